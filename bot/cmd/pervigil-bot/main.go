@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"os"
 	"os/signal"
@@ -11,9 +12,16 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/murata-lab/pervigil/bot/internal/config"
 	"github.com/murata-lab/pervigil/bot/internal/handler"
+	"github.com/murata-lab/pervigil/bot/internal/sysinfo"
+	"github.com/murata-lab/pervigil/bot/internal/sysroot"
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
 )
 
 func main() {
+	configPath := flag.String("config", "", "config file (default: $PERVIGIL_CONFIG or "+config.DefaultPath+")")
+	sysrootPath := flag.String("sysroot", "", "read /sys, /proc and recorded command output from this directory or tarball (debug)")
+	flag.Parse()
+
 	// Load .env from current dir, then from executable dir
 	if err := godotenv.Load(); err != nil {
 		log.Printf("Warning: .env not loaded from current dir: %v", err)
@@ -24,9 +32,28 @@ func main() {
 		}
 	}
 
-	cfg, err := config.Load()
+	path := *configPath
+	if path == "" {
+		path = config.Path(config.OSEnv())
+	}
+	cfg, err := config.LoadPath(path, config.OSEnv())
 	if err != nil {
-		log.Fatalf("config error: %v", err)
+		log.Fatalf("config error:\n%v", err)
+	}
+	handler.Configure(cfg.File)
+	sysinfo.SetMonitoredNICs(cfg.File.Bot.NetworkInterfaces)
+	if *sysrootPath != "" {
+		root, err := sysroot.Open(*sysrootPath)
+		if err != nil {
+			log.Fatalf("sysroot: %v", err)
+		}
+		sysroot.SetDefault(root)
+		log.Printf("Reading sensors from sysroot %s", root.Dir())
+	}
+	if cfg.TempSources != "" || len(cfg.SensorCalibration) > 0 {
+		if err := temperature.ConfigureDefault(cfg.TempSources, cfg.SensorCalibration); err != nil {
+			log.Fatalf("TEMP_SOURCES/SENSOR_CALIBRATION: %v", err)
+		}
 	}
 
 	dg, err := discordgo.New("Bot " + cfg.BotToken)
@@ -68,15 +95,18 @@ func main() {
 
 	log.Println("Bot is running. Press Ctrl+C to exit.")
 
-	// Wait for interrupt
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
-	<-stop
+	// Wait for interrupt; SIGHUP reloads the configuration
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	settings := cfg.File
+	for sig := <-sigs; sig == syscall.SIGHUP; sig = <-sigs {
+		settings = reloadConfig(path, settings)
+	}
 
 	log.Println("Shutting down...")
 
 	// Cleanup commands on shutdown (optional, for dev)
-	if os.Getenv("CLEANUP_COMMANDS") == "1" {
+	if settings.Bot.CleanupCommands {
 		for _, cmd := range registeredCmds {
 			if cmd != nil {
 				dg.ApplicationCommandDelete(dg.State.User.ID, cfg.GuildID, cmd.ID)
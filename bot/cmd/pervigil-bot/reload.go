@@ -0,0 +1,76 @@
+package main
+
+import (
+	"log"
+
+	"github.com/murata-lab/pervigil/bot/internal/config"
+	"github.com/murata-lab/pervigil/bot/internal/handler"
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+	"github.com/murata-lab/pervigil/bot/internal/reload"
+	"github.com/murata-lab/pervigil/bot/internal/sysinfo"
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
+)
+
+// reloadConfig loads the config file on SIGHUP and applies it to the
+// command handlers. It returns the running configuration, which is cur
+// when the new one is invalid.
+func reloadConfig(path string, cur *config.File) *config.File {
+	loaded, err := config.LoadPath(path, config.OSEnv())
+	if err != nil {
+		log.Printf("Configuration reload rejected, keeping the running configuration: %v", err)
+		announce(cur, func(r *reload.Reporter) error { return r.Rejected(err) })
+		return cur
+	}
+	next := botSettings(loaded.File, cur)
+	applied := *next
+	// The session is opened and commands registered once
+	applied.Bot.Token = cur.Bot.Token
+	applied.Bot.GuildID = cur.Bot.GuildID
+
+	if !applied.Sensors.Equal(cur.Sensors) {
+		if err := temperature.ConfigureDefault(applied.Sensors.Sources, applied.Sensors.Calibrations()); err != nil {
+			log.Printf("Configuration reload rejected, keeping the running configuration: %v", err)
+			announce(cur, func(r *reload.Reporter) error { return r.Rejected(err) })
+			return cur
+		}
+	}
+	handler.Configure(&applied)
+	sysinfo.SetMonitoredNICs(applied.Bot.NetworkInterfaces)
+
+	changes, pending := config.Diff(cur, &applied), config.Diff(&applied, next)
+	log.Printf("Configuration reloaded from %s: %d applied, %d pending restart", path, len(changes), len(pending))
+	for _, c := range changes {
+		log.Printf("  %s", c)
+	}
+	for _, c := range pending {
+		log.Printf("  %s (restart required)", c)
+	}
+	announce(&applied, func(r *reload.Reporter) error { return r.Applied(changes, pending) })
+	return &applied
+}
+
+// botSettings returns next with the settings only pervigil-monitor reads
+// kept from cur, so that changing them is not reported by the bot
+func botSettings(next, cur *config.File) *config.File {
+	f := *next
+	f.Monitor = cur.Monitor
+	f.Monitor.StatusAPI = next.Monitor.StatusAPI
+	f.NIC = cur.NIC
+	f.NIC.Interfaces = next.NIC.Interfaces
+	f.Log = cur.Log
+	f.Cost.CheckInterval = cur.Cost.CheckInterval
+	f.Cost.StateFile = cur.Cost.StateFile
+	f.History.MaxMetrics = cur.History.MaxMetrics
+	return &f
+}
+
+// announce posts a reload report to the Discord webhook, when one is configured
+func announce(cfg *config.File, send func(*reload.Reporter) error) {
+	if cfg.Notify.Discord.WebhookURL == "" {
+		return
+	}
+	r := reload.NewReporter(notifier.NewDiscordNotifier(cfg.Notify.Discord.WebhookURL), "pervigil-bot")
+	if err := send(r); err != nil {
+		log.Printf("Reload notification error: %v", err)
+	}
+}
@@ -3,18 +3,26 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
-	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/murata-lab/pervigil/bot/internal/anthropic"
+	"github.com/murata-lab/pervigil/bot/internal/config"
+	"github.com/murata-lab/pervigil/bot/internal/history"
 	"github.com/murata-lab/pervigil/bot/internal/monitor"
 	"github.com/murata-lab/pervigil/bot/internal/notifier"
+	"github.com/murata-lab/pervigil/bot/internal/reload"
+	"github.com/murata-lab/pervigil/bot/internal/statusapi"
+	"github.com/murata-lab/pervigil/bot/internal/sysroot"
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
 )
 
 func main() {
@@ -24,227 +32,413 @@ func main() {
 }
 
 func run() error {
+	configPath := flag.String("config", "", "config file (default: $PERVIGIL_CONFIG or "+config.DefaultPath+")")
+	sysrootPath := flag.String("sysroot", "", "read /sys, /proc and recorded command output from this directory or tarball (debug; implies dry-run)")
+	flag.Parse()
+
 	// Load .env file
 	if err := godotenv.Load(); err != nil {
 		log.Printf("Warning: .env file not loaded: %v", err)
 	}
 
-	cfg, err := loadConfig()
+	path := *configPath
+	if path == "" {
+		path = config.Path(config.OSEnv())
+	}
+
+	// `pervigil-monitor config validate` checks the config file and environment
+	if flag.Arg(0) == "config" {
+		return runConfigCommand(path, flag.Args()[1:])
+	}
+
+	cfg, err := loadConfig(path)
 	if err != nil {
 		return err
 	}
+	nicInterfaces := strings.Join(cfg.NIC.Interfaces, ",")
+	if *sysrootPath != "" {
+		root, err := sysroot.Open(*sysrootPath)
+		if err != nil {
+			return fmt.Errorf("sysroot: %w", err)
+		}
+		sysroot.SetDefault(root)
+		sysrootOverrides(cfg)
+		log.Printf("Reading sensors from sysroot %s", root.Dir())
+	}
+	if cfg.Sensors.Sources != "" || len(cfg.Sensors.Calibration) > 0 {
+		if err := temperature.ConfigureDefault(cfg.Sensors.Sources, cfg.Sensors.Calibrations()); err != nil {
+			return fmt.Errorf("TEMP_SOURCES/SENSOR_CALIBRATION: %w", err)
+		}
+	}
 
-	log.Printf("Starting pervigil-monitor (interval=%ds, iface=%s)", cfg.checkInterval, cfg.nicInterface)
+	// Initialize notifier; the alert log keeps recent alerts for the status API
+	router, outboxes, err := buildNotifier(cfg.Notify)
+	if err != nil {
+		return fmt.Errorf("notify: %w", err)
+	}
+	alertLog := monitor.NewAlertLog(router)
 
-	// Initialize notifier
-	discordNotifier := notifier.NewDiscordNotifier(cfg.webhookURL)
+	// Initialize metric history (optional; failures only disable recording)
+	var (
+		historyStore    *history.Store
+		historyRecorder *monitor.HistoryRecorder
+	)
+	if cfg.History.Dir != "off" {
+		historyStore, err = history.Open(cfg.History.Dir, history.WithMaxMetrics(cfg.History.MaxMetrics))
+		if err != nil {
+			log.Printf("History disabled: %v", err)
+		} else {
+			defer historyStore.Close()
+			historyRecorder = monitor.NewHistoryRecorder(
+				monitor.WithHistoryStore(historyStore),
+				monitor.WithHistoryTempReader(monitor.NewTempAdapter()),
+				monitor.WithHistorySystemReader(monitor.NewSysinfoAdapter()),
+				monitor.WithHistoryInterface(nicInterfaces),
+			)
+			log.Printf("Recording history to %s", historyStore.Dir())
+		}
+	}
 
 	// Initialize NIC monitor
-	nicMonitor := monitor.NewNICMonitor(
-		monitor.WithTempReader(monitor.NewTempAdapter()),
-		monitor.WithNotifier(discordNotifier),
-		monitor.WithStateStore(monitor.NewFileStateStore(cfg.stateFile)),
-		monitor.WithSpeedController(monitor.NewEthtoolSpeedController()),
-		monitor.WithInterface(cfg.nicInterface),
+	nicOpts, err := nicOptions(cfg, alertLog, historyStore)
+	if err != nil {
+		return err
+	}
+	// The speed controller outlives reloads: it holds the link modes to
+	// restore on throttled links
+	nicMonitor := monitor.NewNICMonitor(append(nicOpts, monitor.WithSpeedController(monitor.NewNativeSpeedController()))...)
+
+	// `pervigil-monitor restore` lifts recorded speed limits and exits
+	// (used by systemd ExecStopPost so a crashed monitor never pins the NIC)
+	if flag.Arg(0) == "restore" {
+		return nicMonitor.RestoreAll(context.Background())
+	}
+
+	log.Printf("Starting pervigil-monitor (interval=%s, iface=%s)", time.Duration(cfg.Monitor.CheckInterval), nicInterfaces)
+	if cfg.Monitor.DryRun {
+		log.Printf("Dry-run mode: corrective actions are reported but not executed")
+	}
+
+	// Repair drift between the state file and the actual link before the first check
+	if err := nicMonitor.Reconcile(context.Background()); err != nil {
+		log.Printf("NIC state reconcile error: %v", err)
+	}
+
+	// Initialize SFP DOM monitor (ports without an optical module are skipped)
+	sfpMonitor := monitor.NewSFPMonitor(
+		monitor.WithSFPReader(monitor.NewSFPAdapter()),
+		monitor.WithSFPNotifier(notifier.From(alertLog, "sfp")),
+		monitor.WithSFPInterface(sfpInterfaces(cfg)),
 	)
 
-	// Initialize Log monitor
-	logMonitor := monitor.NewLogMonitor(
-		monitor.WithLogNotifier(discordNotifier),
-		monitor.WithLogReader(monitor.NewFileLogReader(cfg.logFile, cfg.logPosFile)),
+	// Initialize hwmon sensor monitor (fan stalls and chip alarms)
+	sensorMonitor := monitor.NewSensorMonitor(
+		monitor.WithSensorReader(monitor.NewTempAdapter()),
+		monitor.WithSensorNotifier(notifier.From(alertLog, "sensors")),
 	)
 
+	// Initialize Log monitor
+	logMonitor := monitor.NewLogMonitor(logOptions(cfg, alertLog)...)
+
 	// Initialize Cost monitor (optional)
 	var costMonitor *monitor.CostMonitor
-	if cfg.anthropicKey != "" {
-		costMonitor = monitor.NewCostMonitor(
-			monitor.WithCostFetcher(anthropic.NewClient(cfg.anthropicKey)),
-			monitor.WithCostNotifier(discordNotifier),
-			monitor.WithCostStateStore(monitor.NewFileCostStateStore(cfg.costStateFile)),
-			monitor.WithCostThresholds(monitor.CostThresholds{
-				DailyWarning:  cfg.dailyBudgetWarn,
-				DailyCritical: cfg.dailyBudgetCrit,
-			}),
-		)
-		log.Printf("Cost monitor enabled (warn=$%.0f, crit=$%.0f, interval=%ds)",
-			cfg.dailyBudgetWarn, cfg.dailyBudgetCrit, cfg.costCheckInterval)
-	}
-
-	// Setup signal handling
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
-
-	ticker := time.NewTicker(time.Duration(cfg.checkInterval) * time.Second)
-	defer ticker.Stop()
-
-	// Cost monitor has its own interval; nil channel blocks forever in select
-	var costCh <-chan time.Time
-	if costMonitor != nil {
-		costTicker := time.NewTicker(time.Duration(cfg.costCheckInterval) * time.Second)
-		defer costTicker.Stop()
-		costCh = costTicker.C
+	if cfg.Cost.AdminKey != "" {
+		costMonitor = monitor.NewCostMonitor(costOptions(cfg, alertLog, historyStore)...)
+		log.Printf("Cost monitor enabled (warn=$%.0f, crit=$%.0f, interval=%s)",
+			cfg.Cost.DailyWarning, cfg.Cost.DailyCritical, time.Duration(cfg.Cost.CheckInterval))
 	}
 
 	suppress := monitor.NewErrorSuppressor(
-		monitor.WithSuppressInterval(time.Duration(cfg.suppressInterval) * time.Second),
+		monitor.WithSuppressInterval(time.Duration(cfg.Monitor.ErrorSuppressInterval)),
 	)
 
-	// Run immediately on startup
-	runChecks(nicMonitor, logMonitor, costMonitor, suppress)
-
-	for {
-		select {
-		case <-ticker.C:
-			runChecks(nicMonitor, logMonitor, nil, suppress)
-		case <-costCh:
-			runChecks(nil, nil, costMonitor, suppress)
-		case sig := <-stop:
-			log.Printf("Received %v, shutting down", sig)
-			return nil
+	// Serve Prometheus/OpenMetrics metrics (optional)
+	if cfg.Monitor.MetricsListen != "" {
+		exporterOpts := []monitor.ExporterOption{
+			monitor.WithExporterTempReader(monitor.NewTempAdapter()),
+			monitor.WithExporterSystemReader(monitor.NewSysinfoAdapter()),
+			monitor.WithExporterNICStates(nicMonitor),
+			monitor.WithExporterLogTotals(logMonitor),
+			monitor.WithExporterSuppressor(suppress),
+			monitor.WithExporterOutboxes(outboxes),
+			monitor.WithExporterInterface(nicInterfaces),
 		}
+		if costMonitor != nil {
+			exporterOpts = append(exporterOpts, monitor.WithExporterCost(costMonitor))
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", monitor.NewMetricsExporter(exporterOpts...))
+		metricsServer := &http.Server{
+			Addr:              cfg.Monitor.MetricsListen,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("Metrics listener error: %v", err)
+			}
+		}()
+		defer metricsServer.Close()
+		log.Printf("Serving metrics on http://%s/metrics", cfg.Monitor.MetricsListen)
 	}
-}
 
-func runChecks(nic *monitor.NICMonitor, lg *monitor.LogMonitor, cost *monitor.CostMonitor, suppress *monitor.ErrorSuppressor) {
-	if nic != nil {
-		if err := nic.Check(); err != nil {
-			if errors.Is(err, monitor.ErrSensorUnavailable) {
-				if msg, ok := suppress.Check("nic_sensor", err); ok {
-					log.Printf("NIC sensor: %s", msg)
-				}
-			} else {
-				if msg, ok := suppress.Check("nic", err); ok {
-					log.Printf("NIC monitor error: %s", msg)
-				}
-			}
+	// Serve the status API read by pervigil-bot (optional)
+	if cfg.Monitor.StatusAPI != "off" {
+		listener, err := statusapi.Listen(cfg.Monitor.StatusAPI)
+		if err != nil {
+			log.Printf("Status API disabled: %v", err)
 		} else {
-			suppress.Check("nic", nil)
+			statusServer := &http.Server{
+				Handler: monitor.NewStatusHandler(
+					monitor.WithStatusNIC(nicMonitor),
+					monitor.WithStatusSuppressor(suppress),
+					monitor.WithStatusAlerts(alertLog),
+					monitor.WithStatusInterface(nicInterfaces),
+				),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			go func() {
+				if err := statusServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					log.Printf("Status API error: %v", err)
+				}
+			}()
+			defer statusServer.Close()
+			log.Printf("Serving status API on %s", cfg.Monitor.StatusAPI)
 		}
 	}
 
-	if lg != nil {
-		result, err := lg.Process()
-		if err != nil {
-			if msg, ok := suppress.Check("log", err); ok {
-				log.Printf("Log monitor error: %s", msg)
-			}
-		} else {
-			suppress.Check("log", nil)
-			if result != nil && (result.ErrorCount > 0 || result.WarningCount > 0) {
+	// Every check runs on its own schedule; a hung one never blocks the others
+	scheduler := monitor.NewScheduler(monitor.WithSchedulerResult(checkResult(suppress)))
+	checks := []monitor.Check{
+		monitor.NewCheck("nic", nicMonitor.Check),
+		monitor.NewCheck("sfp", sfpMonitor.Check),
+		monitor.NewCheck("sensors", sensorMonitor.Check),
+		monitor.NewCheck("log", func(context.Context) error {
+			result, err := logMonitor.Process()
+			if err == nil && (result.ErrorCount > 0 || result.WarningCount > 0) {
 				log.Printf("Log monitor: %d errors, %d warnings", result.ErrorCount, result.WarningCount)
 			}
+			return err
+		}),
+	}
+	if costMonitor != nil {
+		checks = append(checks, monitor.NewCheck("cost", costMonitor.Check))
+	}
+	if historyRecorder != nil {
+		checks = append(checks, monitor.NewCheck("history", historyRecorder.Check))
+	}
+	if len(outboxes) > 0 {
+		checks = append(checks, monitor.NewCheck("outbox", outboxes.Flush))
+	}
+	for _, c := range checks {
+		if err := scheduler.Register(c, schedule(cfg, c.Name())); err != nil {
+			return err
 		}
 	}
 
-	if cost != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		err := cost.Check(ctx)
-		cancel()
-		if err != nil {
-			if msg, ok := suppress.Check("cost", err); ok {
-				log.Printf("Cost monitor error: %s", msg)
+	reloader := &configReloader{
+		path:      path,
+		sysroot:   *sysrootPath != "",
+		cfg:       cfg,
+		notifier:  alertLog,
+		history:   historyStore,
+		nic:       nicMonitor,
+		log:       logMonitor,
+		cost:      costMonitor,
+		scheduler: scheduler,
+		checks:    checks,
+		reporter:  reload.NewReporter(notifier.From(alertLog, "reload"), "pervigil-monitor"),
+	}
+
+	// Setup signal handling; SIGHUP reloads the configuration
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx)
+		close(done)
+	}()
+
+	for {
+		select {
+		case <-hup:
+			// A reload waits for running checks; shutdown must not
+			go reloader.reload()
+		case sig := <-stop:
+			log.Printf("Received %v, shutting down", sig)
+			// Canceling kills the commands of abandoned checks; RestoreAll
+			// then waits for a NIC check still running
+			cancel()
+			<-done
+			if err := nicMonitor.RestoreAll(context.Background()); err != nil {
+				log.Printf("NIC speed restore error: %v", err)
 			}
-		} else {
-			suppress.Check("cost", nil)
+			return nil
 		}
 	}
 }
 
-type config struct {
-	webhookURL        string
-	nicInterface      string
-	checkInterval     int
-	stateFile         string
-	logFile           string
-	logPosFile        string
-	anthropicKey      string
-	costCheckInterval int
-	dailyBudgetWarn   float64
-	dailyBudgetCrit   float64
-	costStateFile     string
-	suppressInterval  int
+// checkResult logs check errors through the suppressor, keyed by check
+// name; unavailable sensors are tracked apart from real failures
+func checkResult(suppress *monitor.ErrorSuppressor) func(name string, err error) {
+	return func(name string, err error) {
+		switch {
+		case err == nil:
+			suppress.Check(name, nil)
+		case errors.Is(err, monitor.ErrSensorUnavailable):
+			if msg, ok := suppress.Check(name+"_sensor", err); ok {
+				log.Printf("%s sensor: %s", name, msg)
+			}
+		default:
+			if msg, ok := suppress.Check(name, err); ok {
+				log.Printf("%s check error: %s", name, msg)
+			}
+		}
+	}
 }
 
-func loadConfig() (*config, error) {
-	webhookURL := os.Getenv("DISCORD_WEBHOOK_URL")
-	if webhookURL == "" {
-		return nil, fmt.Errorf("DISCORD_WEBHOOK_URL is required")
+// schedule converts the configured schedule of a check
+func schedule(cfg *config.File, name string) monitor.Schedule {
+	s := cfg.Schedule(name)
+	return monitor.Schedule{
+		Interval:     time.Duration(s.Interval),
+		StartupDelay: time.Duration(s.StartupDelay),
+		Jitter:       time.Duration(s.Jitter),
+		Timeout:      time.Duration(s.Timeout),
 	}
+}
 
-	nicInterface := os.Getenv("NIC_INTERFACE")
-	if nicInterface == "" {
-		nicInterface = "eth1"
+// loadConfig loads the config file and environment and checks the settings
+// the monitor requires
+func loadConfig(path string) (*config.File, error) {
+	cfg, err := config.LoadFile(path, config.OSEnv())
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
 	}
-
-	checkInterval := 60
-	if v := os.Getenv("CHECK_INTERVAL"); v != "" {
-		if i, err := strconv.Atoi(v); err == nil && i > 0 {
-			checkInterval = i
-		}
+	if cfg.Notify.Discord.WebhookURL == "" && len(cfg.Notify.Fallback) == 0 {
+		return nil, fmt.Errorf("notify.discord.webhook_url (DISCORD_WEBHOOK_URL) is required unless notify.fallback names other backends")
 	}
+	return cfg, nil
+}
 
-	stateFile := os.Getenv("STATE_FILE")
-	if stateFile == "" {
-		stateFile = "/tmp/pervigil-state"
+// runConfigCommand runs `pervigil-monitor config validate`
+func runConfigCommand(path string, args []string) error {
+	if len(args) != 1 || args[0] != "validate" {
+		return fmt.Errorf("usage: pervigil-monitor [--config file] config validate")
 	}
-
-	logFile := os.Getenv("LOG_FILE")
-	if logFile == "" {
-		logFile = "/var/log/syslog"
+	if _, err := loadConfig(path); err != nil {
+		return err
 	}
-
-	logPosFile := os.Getenv("LOG_POS_FILE")
-	if logPosFile == "" {
-		logPosFile = "/tmp/pervigil-log-pos"
+	if _, err := os.Stat(path); err != nil {
+		fmt.Printf("%s: not found, defaults and environment are valid\n", path)
+		return nil
 	}
+	fmt.Printf("%s: OK\n", path)
+	return nil
+}
 
-	anthropicKey := os.Getenv("ANTHROPIC_ADMIN_KEY")
+// sysrootOverrides adjusts the configuration for a replayed sysroot
+func sysrootOverrides(cfg *config.File) {
+	// A replayed router must never change the speed of this host's NICs
+	cfg.Monitor.DryRun = true
+	// Nor may it mix replayed samples into the real history
+	cfg.History.Dir = "off"
+}
 
-	costCheckInterval := 3600
-	if v := os.Getenv("COST_CHECK_INTERVAL"); v != "" {
-		if i, err := strconv.Atoi(v); err == nil && i > 0 {
-			costCheckInterval = i
-		}
+// nicOptions builds the NIC monitor options from the configuration. The
+// speed controller is set once by main and kept across reloads.
+func nicOptions(cfg *config.File, n notifier.Notifier, hist *history.Store) ([]monitor.NICOption, error) {
+	throttle, err := throttlePolicy(cfg.NIC.Throttle)
+	if err != nil {
+		return nil, err
 	}
-
-	dailyBudgetWarn := 5.0
-	if v := os.Getenv("DAILY_BUDGET_WARN"); v != "" {
-		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 {
-			dailyBudgetWarn = f
-		}
+	opts := []monitor.NICOption{
+		monitor.WithTempReader(monitor.NewTempAdapter()),
+		monitor.WithNotifier(notifier.From(n, "nic")),
+		monitor.WithStateStore(monitor.NewFileStateStore(cfg.Monitor.StateFile)),
+		monitor.WithLinkReader(monitor.NewSysfsLinkReader()),
+		monitor.WithInterface(strings.Join(cfg.NIC.Interfaces, ",")),
+		monitor.WithHoldConditions(monitor.TransitionHolds{
+			Warning:  holdCondition(cfg.NIC.Hold.Warning),
+			Critical: holdCondition(cfg.NIC.Hold.Critical),
+			Recovery: holdCondition(cfg.NIC.Hold.Recovery),
+			Normal:   holdCondition(cfg.NIC.Hold.Normal),
+		}),
+		monitor.WithRiseAlert(monitor.RiseAlert{
+			Delta:  cfg.NIC.RiseAlert.Delta,
+			Window: time.Duration(cfg.NIC.RiseAlert.Window),
+		}),
+		monitor.WithThrottlePolicy(throttle),
+		monitor.WithDryRun(cfg.Monitor.DryRun),
 	}
-
-	dailyBudgetCrit := 10.0
-	if v := os.Getenv("DAILY_BUDGET_CRIT"); v != "" {
-		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 {
-			dailyBudgetCrit = f
-		}
+	if hist != nil {
+		opts = append(opts, monitor.WithChartHistory(hist))
 	}
+	return opts, nil
+}
 
-	costStateFile := os.Getenv("COST_STATE_FILE")
-	if costStateFile == "" {
-		costStateFile = "/tmp/pervigil-cost-state"
+// logOptions builds the log monitor options from the configuration
+func logOptions(cfg *config.File, n notifier.Notifier) []monitor.LogOption {
+	opts := []monitor.LogOption{
+		monitor.WithLogNotifier(notifier.From(n, "log")),
+		monitor.WithLogReader(monitor.NewFileLogReader(cfg.Log.File, cfg.Log.PositionFile)),
+		monitor.WithWarningThreshold(cfg.Log.WarningThreshold),
+	}
+	if len(cfg.Log.ErrorPatterns) > 0 {
+		opts = append(opts, monitor.WithErrorPatterns(cfg.Log.ErrorPatterns))
 	}
+	if len(cfg.Log.WarningPatterns) > 0 {
+		opts = append(opts, monitor.WithWarningPatterns(cfg.Log.WarningPatterns))
+	}
+	if len(cfg.Log.ExcludePatterns) > 0 {
+		opts = append(opts, monitor.WithExcludePatterns(cfg.Log.ExcludePatterns))
+	}
+	return opts
+}
 
-	suppressInterval := 3600
-	if v := os.Getenv("ERROR_SUPPRESS_INTERVAL"); v != "" {
-		if i, err := strconv.Atoi(v); err == nil && i > 0 {
-			suppressInterval = i
-		}
+// costOptions builds the cost monitor options from the configuration
+func costOptions(cfg *config.File, n notifier.Notifier, hist *history.Store) []monitor.CostOption {
+	opts := []monitor.CostOption{
+		monitor.WithCostFetcher(anthropic.NewClient(cfg.Cost.AdminKey)),
+		monitor.WithCostNotifier(notifier.From(n, "cost")),
+		monitor.WithCostStateStore(monitor.NewFileCostStateStore(cfg.Cost.StateFile)),
+		monitor.WithCostThresholds(monitor.CostThresholds{
+			DailyWarning:  cfg.Cost.DailyWarning,
+			DailyCritical: cfg.Cost.DailyCritical,
+		}),
 	}
+	if hist != nil {
+		opts = append(opts, monitor.WithCostHistory(hist))
+	}
+	return opts
+}
 
-	return &config{
-		webhookURL:        webhookURL,
-		nicInterface:      nicInterface,
-		checkInterval:     checkInterval,
-		stateFile:         stateFile,
-		logFile:           logFile,
-		logPosFile:        logPosFile,
-		anthropicKey:      anthropicKey,
-		costCheckInterval: costCheckInterval,
-		dailyBudgetWarn:   dailyBudgetWarn,
-		dailyBudgetCrit:   dailyBudgetCrit,
-		costStateFile:     costStateFile,
-		suppressInterval:  suppressInterval,
+// holdCondition converts a configured hold
+func holdCondition(h config.Hold) monitor.HoldCondition {
+	return monitor.HoldCondition{Samples: h.Samples, Duration: time.Duration(h.Duration)}
+}
+
+// throttlePolicy converts the configured throttle ladder
+func throttlePolicy(t config.Throttle) (monitor.ThrottlePolicy, error) {
+	ladder, err := monitor.ParseThrottleLadder(strings.Join(t.Ladder, ","))
+	if err != nil {
+		return monitor.ThrottlePolicy{}, fmt.Errorf("nic.throttle.ladder: %w", err)
+	}
+	return monitor.ThrottlePolicy{
+		Ladder:        ladder,
+		StepDownAfter: time.Duration(t.StepDownAfter),
+		StepUpAfter:   time.Duration(t.StepUpAfter),
 	}, nil
 }
+
+// sfpInterfaces returns the DOM-monitored interfaces, defaulting to the NICs
+func sfpInterfaces(cfg *config.File) string {
+	if len(cfg.SFP.Interfaces) > 0 {
+		return strings.Join(cfg.SFP.Interfaces, ",")
+	}
+	return strings.Join(cfg.NIC.Interfaces, ",")
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/murata-lab/pervigil/bot/internal/config"
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+)
+
+// notifyTimeout bounds one delivery, so a hung backend cannot hold up a
+// check past its deadline
+const notifyTimeout = 30 * time.Second
+
+// buildNotifier creates the notifier that routes alerts to the configured
+// backends. Monitors tag their alerts with notifier.From. Unless disabled,
+// every backend queues what it fails to send in an outbox, to be retried
+// by flushing the returned outboxes.
+func buildNotifier(cfg config.Notify) (notifier.Notifier, notifier.Outboxes, error) {
+	client := &http.Client{Timeout: notifyTimeout}
+	backends := make(map[string]notifier.Notifier)
+	if cfg.Discord.WebhookURL != "" {
+		backends["discord"] = notifier.NewDiscordNotifier(cfg.Discord.WebhookURL, notifier.WithHTTPClient(client))
+	}
+	for name, b := range cfg.Backends {
+		switch b.Type() {
+		case "discord":
+			backends[name] = notifier.NewDiscordNotifier(b.Discord.WebhookURL, notifier.WithHTTPClient(client))
+		case "slack":
+			backends[name] = notifier.NewSlackNotifier(b.Slack.WebhookURL, notifier.WithSlackHTTPClient(client))
+		case "smtp":
+			backends[name] = smtpNotifier(b.SMTP)
+		case "telegram":
+			opts := []notifier.TelegramOption{notifier.WithTelegramHTTPClient(client)}
+			if b.Telegram.BaseURL != "" {
+				opts = append(opts, notifier.WithTelegramBaseURL(b.Telegram.BaseURL))
+			}
+			backends[name] = notifier.NewTelegramNotifier(b.Telegram.Token, b.Telegram.ChatID, opts...)
+		case "ntfy":
+			opts := []notifier.NtfyOption{notifier.WithNtfyHTTPClient(client), notifier.WithNtfyToken(b.Ntfy.Token)}
+			if b.Ntfy.BaseURL != "" {
+				opts = append(opts, notifier.WithNtfyBaseURL(b.Ntfy.BaseURL))
+			}
+			backends[name] = notifier.NewNtfyNotifier(b.Ntfy.Topic, opts...)
+		case "gotify":
+			backends[name] = notifier.NewGotifyNotifier(b.Gotify.BaseURL, b.Gotify.Token, notifier.WithGotifyHTTPClient(client))
+		case "webhook":
+			n, err := webhookNotifier(b.Webhook, client)
+			if err != nil {
+				return nil, nil, fmt.Errorf("notify backend %s: %w", name, err)
+			}
+			backends[name] = n
+		}
+	}
+
+	opts := []notifier.RouterOption{notifier.WithFallback(cfg.FallbackBackends()...)}
+	for _, r := range cfg.Routes {
+		route := notifier.Route{Sources: r.Match.Source, To: r.To}
+		for _, s := range r.Match.Severity {
+			route.Severities = append(route.Severities, notifier.Severity(s))
+		}
+		if r.Match.Title != "" {
+			re, err := regexp.Compile(r.Match.Title)
+			if err != nil {
+				return nil, nil, err
+			}
+			route.Title = re
+		}
+		opts = append(opts, notifier.WithRoute(route))
+	}
+	outboxes := outboxes(cfg.Outbox, backends)
+	router, err := notifier.NewRouter(backends, opts...)
+	return router, outboxes, err
+}
+
+// outboxes wraps every backend in an outbox, replacing it in backends. A
+// backend whose queue cannot be read sends without one.
+func outboxes(cfg config.Outbox, backends map[string]notifier.Notifier) notifier.Outboxes {
+	if cfg.Dir == "off" {
+		return nil
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+		log.Printf("Notification outbox disabled: %v", err)
+		return nil
+	}
+	opts := []notifier.OutboxOption{
+		notifier.WithOutboxMaxSize(cfg.MaxSize),
+		notifier.WithOutboxBackoff(time.Duration(cfg.RetryMin), time.Duration(cfg.RetryMax)),
+	}
+	boxes := make(notifier.Outboxes)
+	for name, n := range backends {
+		o, err := notifier.NewOutbox(name, n, filepath.Join(cfg.Dir, name+".json"), opts...)
+		if err != nil {
+			log.Printf("Notification outbox of %s disabled: %v", name, err)
+			continue
+		}
+		if q := o.Stats().Queued; q > 0 {
+			log.Printf("Notification outbox of %s: %d alerts queued for retry", name, q)
+		}
+		backends[name] = o
+		boxes[name] = o
+	}
+	return boxes
+}
+
+// smtpNotifier creates a mail backend
+func smtpNotifier(cfg config.SMTP) *notifier.SMTPNotifier {
+	opts := []notifier.SMTPOption{notifier.WithSMTPTimeout(notifyTimeout)}
+	switch cfg.TLS {
+	case "implicit":
+		opts = append(opts, notifier.WithSMTPSecurity(notifier.SMTPImplicitTLS))
+	case "none":
+		opts = append(opts, notifier.WithSMTPSecurity(notifier.SMTPPlain))
+	}
+	if cfg.Username != "" {
+		mech := notifier.SMTPAuthPlain
+		if cfg.Auth == "login" {
+			mech = notifier.SMTPAuthLogin
+		}
+		opts = append(opts, notifier.WithSMTPAuth(mech, cfg.Username, cfg.Password))
+	}
+	return notifier.NewSMTPNotifier(cfg.Addr(), cfg.From, cfg.To, opts...)
+}
+
+// webhookNotifier creates a templated HTTP backend
+func webhookNotifier(cfg config.Webhook, client *http.Client) (*notifier.WebhookNotifier, error) {
+	opts := []notifier.WebhookOption{notifier.WithWebhookHTTPClient(client)}
+	if cfg.Method != "" {
+		opts = append(opts, notifier.WithWebhookMethod(cfg.Method))
+	}
+	for name, value := range cfg.Headers {
+		opts = append(opts, notifier.WithWebhookHeader(name, value))
+	}
+	if cfg.Secret != "" {
+		opts = append(opts, notifier.WithWebhookSecret(cfg.Secret, cfg.SignatureHeader))
+	}
+	if len(cfg.SuccessStatus) > 0 {
+		opts = append(opts, notifier.WithWebhookSuccess(cfg.SuccessStatus...))
+	}
+	return notifier.NewWebhookNotifier(cfg.URL, cfg.Body, opts...)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"log"
+	"sync"
+
+	"github.com/murata-lab/pervigil/bot/internal/config"
+	"github.com/murata-lab/pervigil/bot/internal/history"
+	"github.com/murata-lab/pervigil/bot/internal/monitor"
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+	"github.com/murata-lab/pervigil/bot/internal/reload"
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
+)
+
+// configReloader applies a changed config file on SIGHUP. The monitors are
+// reconfigured in place, so their state files, the error suppressor and
+// the alert log carry over.
+type configReloader struct {
+	path      string
+	sysroot   bool
+	cfg       *config.File // running configuration
+	notifier  notifier.Notifier
+	history   *history.Store
+	nic       *monitor.NICMonitor
+	log       *monitor.LogMonitor
+	cost      *monitor.CostMonitor // nil when disabled
+	scheduler *monitor.Scheduler
+	checks    []monitor.Check // registered with scheduler
+	reporter  *reload.Reporter
+
+	mu sync.Mutex // serializes reloads
+}
+
+// reload loads the config file and applies it. An invalid configuration is
+// rejected as a whole and the running one is kept.
+func (r *configReloader) reload() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := loadConfig(r.path)
+	if err != nil {
+		r.reject(err)
+		return
+	}
+	if r.sysroot {
+		sysrootOverrides(next)
+	}
+	// Settings the monitor does not read are not reported
+	next.Bot = r.cfg.Bot
+	applied := pinRestartSettings(next, r.cfg)
+
+	// Build everything that can fail before changing anything
+	nicOpts, err := nicOptions(applied, r.notifier, r.history)
+	if err != nil {
+		r.reject(err)
+		return
+	}
+	if !applied.Sensors.Equal(r.cfg.Sensors) {
+		if err := temperature.ConfigureDefault(applied.Sensors.Sources, applied.Sensors.Calibrations()); err != nil {
+			r.reject(err)
+			return
+		}
+	}
+
+	r.nic.Reconfigure(nicOpts...)
+	r.log.Reconfigure(logOptions(applied, r.notifier)...)
+	if r.cost != nil {
+		r.cost.Reconfigure(costOptions(applied, r.notifier, r.history)...)
+	}
+	for _, c := range r.checks {
+		if err := r.scheduler.Reschedule(c.Name(), schedule(applied, c.Name())); err != nil {
+			log.Printf("Reschedule %s: %v", c.Name(), err)
+		}
+	}
+
+	changes, pending := config.Diff(r.cfg, applied), config.Diff(applied, next)
+	r.cfg = applied
+	log.Printf("Configuration reloaded from %s: %d applied, %d pending restart", r.path, len(changes), len(pending))
+	for _, c := range changes {
+		log.Printf("  %s", c)
+	}
+	for _, c := range pending {
+		log.Printf("  %s (restart required)", c)
+	}
+	if err := r.reporter.Applied(changes, pending); err != nil {
+		log.Printf("Reload notification error: %v", err)
+	}
+}
+
+func (r *configReloader) reject(err error) {
+	log.Printf("Configuration reload rejected, keeping the running configuration: %v", err)
+	if err := r.reporter.Rejected(err); err != nil {
+		log.Printf("Reload notification error: %v", err)
+	}
+}
+
+// pinRestartSettings returns next with the settings that cannot change
+// while running kept at their values in cur. Listeners, state and position
+// files and the history store are opened once; the interfaces are shared
+// with the SFP monitor, history recorder and endpoints.
+func pinRestartSettings(next, cur *config.File) *config.File {
+	pinned := *next
+	pinned.Notify = cur.Notify
+	pinned.Monitor.StateFile = cur.Monitor.StateFile
+	pinned.Monitor.ErrorSuppressInterval = cur.Monitor.ErrorSuppressInterval
+	pinned.Monitor.MetricsListen = cur.Monitor.MetricsListen
+	pinned.Monitor.StatusAPI = cur.Monitor.StatusAPI
+	pinned.NIC.Interfaces = cur.NIC.Interfaces
+	pinned.SFP = cur.SFP
+	pinned.Log.PositionFile = cur.Log.PositionFile
+	pinned.Cost.StateFile = cur.Cost.StateFile
+	if (next.Cost.AdminKey == "") != (cur.Cost.AdminKey == "") {
+		// Enabling or disabling the cost monitor needs a restart
+		pinned.Cost.AdminKey = cur.Cost.AdminKey
+	}
+	pinned.History = cur.History
+	return &pinned
+}
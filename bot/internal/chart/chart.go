@@ -0,0 +1,400 @@
+// Package chart renders metric history as PNG line charts.
+// It only uses the standard library image packages and a built-in bitmap
+// font, so charts can be drawn on the router without external services.
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/murata-lab/pervigil/bot/internal/history"
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Default image size, readable in a Discord embed without zooming.
+const (
+	DefaultWidth  = 800
+	DefaultHeight = 360
+)
+
+// ErrNoData is returned when no series has a point in the chart range.
+var ErrNoData = errors.New("no data to chart")
+
+// Colors used for threshold lines.
+var (
+	ColorWarning  = color.RGBA{0xe6, 0xa7, 0x00, 0xff}
+	ColorCritical = color.RGBA{0xed, 0x42, 0x45, 0xff}
+)
+
+var (
+	colorBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	colorGrid       = color.RGBA{0xe3, 0xe5, 0xe8, 0xff}
+	colorAxis       = color.RGBA{0x99, 0x9e, 0xa5, 0xff}
+	colorText       = color.RGBA{0x31, 0x33, 0x38, 0xff}
+
+	// palette colors the series in order
+	palette = []color.RGBA{
+		{0x58, 0x65, 0xf2, 0xff}, // blurple
+		{0x23, 0xa5, 0x59, 0xff}, // green
+		{0xeb, 0x45, 0x9e, 0xff}, // fuchsia
+		{0xf0, 0x8c, 0x00, 0xff}, // orange
+		{0x00, 0xa8, 0xb5, 0xff}, // teal
+		{0x8e, 0x5a, 0xd8, 0xff}, // purple
+	}
+)
+
+// Series is one line of a chart.
+type Series struct {
+	Label  string
+	Points []history.Point
+}
+
+// Line is a horizontal reference line such as a threshold.
+type Line struct {
+	Label string
+	Value float64
+	Color color.RGBA
+}
+
+// Chart describes a line chart over a time range.
+type Chart struct {
+	Title  string
+	From   time.Time
+	To     time.Time
+	Step   time.Duration // points further apart than two steps are not joined
+	Series []Series
+	Lines  []Line
+	Width  int
+	Height int
+}
+
+// ThresholdLines returns the warning and critical lines of t that are set.
+func ThresholdLines(t temperature.Thresholds) []Line {
+	var lines []Line
+	if t.Warning > 0 {
+		lines = append(lines, Line{Label: "warning", Value: t.Warning, Color: ColorWarning})
+	}
+	if t.Critical > 0 {
+		lines = append(lines, Line{Label: "critical", Value: t.Critical, Color: ColorCritical})
+	}
+	return lines
+}
+
+// PNG renders c and returns the encoded image.
+func PNG(c Chart) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Render(&buf, c); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Render draws c as a PNG image to w. The average of each point is drawn
+// as a line over a lighter band spanning its min and max.
+func Render(w io.Writer, c Chart) error {
+	if c.Width <= 0 {
+		c.Width = DefaultWidth
+	}
+	if c.Height <= 0 {
+		c.Height = DefaultHeight
+	}
+	lo, hi, ok := valueRange(c)
+	if !ok {
+		return ErrNoData
+	}
+	ticks := niceTicks(lo, hi, 5)
+	lo, hi = ticks[0], ticks[len(ticks)-1]
+
+	img := image.NewRGBA(image.Rect(0, 0, c.Width, c.Height))
+	fillRect(img, img.Bounds(), colorBackground)
+	p := &plot{
+		img:  img,
+		area: image.Rect(56, 28, c.Width-16, c.Height-28),
+		from: c.From,
+		to:   c.To,
+		lo:   lo,
+		hi:   hi,
+	}
+
+	drawText(img, 8, 18, c.Title, colorText)
+	p.drawYAxis(ticks)
+	p.drawXAxis()
+	for _, l := range c.Lines {
+		p.drawLine(l)
+	}
+	for i, s := range c.Series {
+		p.drawSeries(s, c.Step, palette[i%len(palette)])
+	}
+	p.drawLegend(c.Series)
+	hline(img, p.area.Min.X, p.area.Max.X, p.area.Max.Y, colorAxis)
+	vline(img, p.area.Min.X, p.area.Min.Y, p.area.Max.Y, colorAxis)
+
+	return png.Encode(w, img)
+}
+
+// valueRange returns the span of all points in range and the reference lines
+func valueRange(c Chart) (lo, hi float64, ok bool) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, s := range c.Series {
+		for _, pt := range s.Points {
+			if pt.Time.Before(c.From) || pt.Time.After(c.To) {
+				continue
+			}
+			lo, hi = math.Min(lo, pt.Min), math.Max(hi, pt.Max)
+			ok = true
+		}
+	}
+	if !ok {
+		return 0, 0, false
+	}
+	for _, l := range c.Lines {
+		lo, hi = math.Min(lo, l.Value), math.Max(hi, l.Value)
+	}
+	if hi-lo < 1e-9 {
+		lo, hi = lo-1, hi+1
+	}
+	return lo, hi, true
+}
+
+// niceTicks returns about n evenly spaced round values covering lo..hi
+func niceTicks(lo, hi float64, n int) []float64 {
+	raw := (hi - lo) / float64(n)
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := mag * 10
+	for _, m := range []float64{1, 2, 2.5, 5} {
+		if raw <= m*mag {
+			step = m * mag
+			break
+		}
+	}
+	var ticks []float64
+	first := math.Floor(lo/step) * step
+	for i := 0; ; i++ {
+		v := first + float64(i)*step
+		ticks = append(ticks, v)
+		if v >= hi-step*1e-9 {
+			break
+		}
+	}
+	return ticks
+}
+
+// tickInterval returns the spacing of time labels for a range
+func tickInterval(span time.Duration) time.Duration {
+	for _, d := range []time.Duration{
+		10 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+		time.Hour, 2 * time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour,
+		24 * time.Hour, 2 * 24 * time.Hour, 7 * 24 * time.Hour,
+	} {
+		if span/d <= 8 {
+			return d
+		}
+	}
+	return 30 * 24 * time.Hour
+}
+
+// plot maps time and value onto the drawing area
+type plot struct {
+	img      *image.RGBA
+	area     image.Rectangle
+	from, to time.Time
+	lo, hi   float64
+}
+
+func (p *plot) x(t time.Time) int {
+	span := p.to.Sub(p.from)
+	if span <= 0 {
+		return p.area.Min.X
+	}
+	return p.area.Min.X + int(float64(p.area.Dx())*float64(t.Sub(p.from))/float64(span))
+}
+
+func (p *plot) y(v float64) int {
+	return p.area.Max.Y - int(math.Round(float64(p.area.Dy())*(v-p.lo)/(p.hi-p.lo)))
+}
+
+func (p *plot) drawYAxis(ticks []float64) {
+	decimals := 0
+	if step := ticks[1] - ticks[0]; step < 1 {
+		decimals = int(math.Ceil(-math.Log10(step)))
+	}
+	for _, v := range ticks {
+		y := p.y(v)
+		hline(p.img, p.area.Min.X, p.area.Max.X, y, colorGrid)
+		label := strconv.FormatFloat(v, 'f', decimals, 64)
+		drawText(p.img, p.area.Min.X-6-textWidth(label), y+4, label, colorText)
+	}
+}
+
+func (p *plot) drawXAxis() {
+	interval := tickInterval(p.to.Sub(p.from))
+	layout := "15:04"
+	if interval >= 24*time.Hour {
+		layout = "01/02"
+	}
+	// Align labels to local midnight/hours rather than the Unix epoch
+	local := p.from.Local()
+	t := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.Local)
+	for t.Before(p.from) {
+		t = t.Add(interval)
+	}
+	for ; !t.After(p.to); t = t.Add(interval) {
+		x := p.x(t)
+		vline(p.img, x, p.area.Min.Y, p.area.Max.Y, colorGrid)
+		label := t.Format(layout)
+		lx := min(x-textWidth(label)/2, p.img.Bounds().Max.X-textWidth(label)-2)
+		drawText(p.img, lx, p.area.Max.Y+16, label, colorText)
+	}
+}
+
+func (p *plot) drawLine(l Line) {
+	y := p.y(l.Value)
+	for x := p.area.Min.X; x < p.area.Max.X; x += 8 {
+		hline(p.img, x, min(x+4, p.area.Max.X), y, l.Color)
+	}
+	drawText(p.img, p.area.Max.X-textWidth(l.Label)-2, y-4, l.Label, l.Color)
+}
+
+func (p *plot) drawSeries(s Series, step time.Duration, c color.RGBA) {
+	band := lighten(c, 0.75)
+	var pts []history.Point
+	for _, pt := range s.Points {
+		if !pt.Time.Before(p.from) && !pt.Time.After(p.to) {
+			pts = append(pts, pt)
+		}
+	}
+	joined := func(i int) bool {
+		return i > 0 && (step <= 0 || pts[i].Time.Sub(pts[i-1].Time) <= 2*step)
+	}
+	for i, pt := range pts {
+		if !joined(i) {
+			vline(p.img, p.x(pt.Time), p.y(pt.Max), p.y(pt.Min), band)
+			continue
+		}
+		// Fill the band column by column between neighbouring points
+		prev := pts[i-1]
+		x0, x1 := p.x(prev.Time), p.x(pt.Time)
+		for x := x0; x <= x1; x++ {
+			f := 0.0
+			if x1 > x0 {
+				f = float64(x-x0) / float64(x1-x0)
+			}
+			vline(p.img, x, p.y(lerp(prev.Max, pt.Max, f)), p.y(lerp(prev.Min, pt.Min, f)), band)
+		}
+	}
+	for i, pt := range pts {
+		x, y := p.x(pt.Time), p.y(pt.Avg)
+		if !joined(i) {
+			// Start of a run: a lone sample still shows up as a dot
+			thickLine(p.img, x, y, x, y, c)
+			continue
+		}
+		prev := pts[i-1]
+		thickLine(p.img, p.x(prev.Time), p.y(prev.Avg), x, y, c)
+	}
+}
+
+func (p *plot) drawLegend(series []Series) {
+	x := p.area.Max.X
+	for i := len(series) - 1; i >= 0; i-- {
+		label := series[i].Label
+		if label == "" {
+			continue
+		}
+		x -= textWidth(label) + 18
+		fillRect(p.img, image.Rect(x, 9, x+10, 19), palette[i%len(palette)])
+		drawText(p.img, x+14, 18, label, colorText)
+	}
+}
+
+// Drawing primitives
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	r = r.Intersect(img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+func hline(img *image.RGBA, x0, x1, y int, c color.RGBA) {
+	fillRect(img, image.Rect(x0, y, x1+1, y+1), c)
+}
+
+func vline(img *image.RGBA, x, y0, y1 int, c color.RGBA) {
+	fillRect(img, image.Rect(x, min(y0, y1), x+1, max(y0, y1)+1), c)
+}
+
+// thickLine draws a 2px line with Bresenham's algorithm
+func thickLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	e := dx + dy
+	for {
+		fillRect(img, image.Rect(x0, y0, x0+2, y0+2), c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func drawText(img *image.RGBA, x, y int, s string, c color.RGBA) {
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(s)
+}
+
+func textWidth(s string) int {
+	return font.MeasureString(basicfont.Face7x13, s).Ceil()
+}
+
+// lighten mixes c with white
+func lighten(c color.RGBA, f float64) color.RGBA {
+	mix := func(v uint8) uint8 { return uint8(float64(v) + (255-float64(v))*f) }
+	return color.RGBA{mix(c.R), mix(c.G), mix(c.B), 0xff}
+}
+
+func lerp(a, b, f float64) float64 {
+	return a + (b-a)*f
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
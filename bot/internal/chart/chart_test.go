@@ -0,0 +1,94 @@
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/murata-lab/pervigil/bot/internal/history"
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
+)
+
+func TestRender(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	var points []history.Point
+	for m := range 60 {
+		v := 60 + float64(m)/4
+		points = append(points, history.Point{Time: from.Add(time.Duration(m) * time.Minute), Min: v - 1, Max: v + 1, Avg: v, Count: 2})
+	}
+	c := Chart{
+		Title:  "nic_temp:eth1 (1h)",
+		From:   from,
+		To:     from.Add(time.Hour),
+		Step:   time.Minute,
+		Series: []Series{{Label: "eth1", Points: points}},
+		Lines:  ThresholdLines(temperature.Thresholds{Warning: 70, Critical: 85}),
+	}
+
+	data, err := PNG(c)
+	if err != nil {
+		t.Fatalf("PNG() error = %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != DefaultWidth || b.Dy() != DefaultHeight {
+		t.Errorf("size = %v", b)
+	}
+
+	found := map[color.RGBA]bool{}
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			found[color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)] = true
+		}
+	}
+	for name, want := range map[string]color.RGBA{
+		"series":   palette[0],
+		"warning":  ColorWarning,
+		"critical": ColorCritical,
+	} {
+		if !found[want] {
+			t.Errorf("%s color not drawn", name)
+		}
+	}
+}
+
+func TestRender_NoData(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	c := Chart{
+		From:   from,
+		To:     from.Add(time.Hour),
+		Series: []Series{{Points: []history.Point{{Time: from.Add(-time.Hour), Avg: 1}}}},
+	}
+	if _, err := PNG(c); !errors.Is(err, ErrNoData) {
+		t.Errorf("error = %v, want ErrNoData", err)
+	}
+}
+
+func TestNiceTicks(t *testing.T) {
+	tests := []struct {
+		lo, hi    float64
+		first     float64
+		last      float64
+		wantCount int
+	}{
+		{lo: 58, hi: 86, first: 50, last: 90, wantCount: 5},
+		{lo: 0.3, hi: 0.42, first: 0.275, last: 0.425, wantCount: 7},
+		{lo: 0, hi: 100, first: 0, last: 100, wantCount: 6},
+	}
+	for _, tt := range tests {
+		ticks := niceTicks(tt.lo, tt.hi, 5)
+		if len(ticks) != tt.wantCount || !near(ticks[0], tt.first) || !near(ticks[len(ticks)-1], tt.last) {
+			t.Errorf("niceTicks(%v, %v) = %v", tt.lo, tt.hi, ticks)
+		}
+	}
+}
+
+func near(a, b float64) bool {
+	return a-b < 1e-9 && b-a < 1e-9
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"errors"
 	"os"
+
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
 )
 
 var ErrMissingToken = errors.New("BOT_TOKEN is required")
@@ -19,25 +21,49 @@ func (o *osEnvGetter) Getenv(key string) string {
 	return os.Getenv(key)
 }
 
+// OSEnv returns the process environment
+func OSEnv() EnvGetter {
+	return &osEnvGetter{}
+}
+
 type Config struct {
 	BotToken string
 	GuildID  string // optional: for faster command registration
+
+	TempSources       string                             // optional: temperature source chains (TEMP_SOURCES)
+	SensorCalibration map[string]temperature.Calibration // optional: per-sensor calibration (SENSOR_CALIBRATION)
+
+	File *File // every setting, including those of the monitor
 }
 
-// Load loads config from OS environment variables
+// Load loads the bot config from the config file and OS environment variables
 func Load() (*Config, error) {
 	return LoadWithEnv(&osEnvGetter{})
 }
 
-// LoadWithEnv loads config using the provided EnvGetter (for DI/testing)
+// LoadWithEnv loads the bot config using the provided EnvGetter (for DI/testing).
+// The config file is PERVIGIL_CONFIG or DefaultPath.
 func LoadWithEnv(env EnvGetter) (*Config, error) {
-	token := env.Getenv("BOT_TOKEN")
-	if token == "" {
+	return LoadPath(Path(env), env)
+}
+
+// LoadPath loads the bot config from the config file at path and env
+func LoadPath(path string, env EnvGetter) (*Config, error) {
+	f, err := LoadFile(path, env)
+	if err != nil {
+		return nil, err
+	}
+	if f.Bot.Token == "" {
 		return nil, ErrMissingToken
 	}
 
 	return &Config{
-		BotToken: token,
-		GuildID:  env.Getenv("GUILD_ID"),
+		BotToken: f.Bot.Token,
+		GuildID:  f.Bot.GuildID,
+
+		TempSources:       f.Sensors.Sources,
+		SensorCalibration: f.Sensors.Calibrations(),
+
+		File: f,
 	}, nil
 }
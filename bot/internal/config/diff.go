@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Change is one setting that differs between two configurations
+type Change struct {
+	Path string // setting, e.g. "nic.throttle.ladder"
+	Old  string
+	New  string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s → %s", c.Path, c.Old, c.New)
+}
+
+// Diff lists the settings that differ from old to new, in file order.
+// Secrets are reported as changed without their values.
+func Diff(old, new *File) []Change {
+	var changes []Change
+	diffValue(reflect.ValueOf(old).Elem(), reflect.ValueOf(new).Elem(), "", &changes)
+	return changes
+}
+
+func diffValue(a, b reflect.Value, path string, changes *[]Change) {
+	t := a.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		p := key
+		if path != "" {
+			p = path + "." + key
+		}
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeFor[Duration]() {
+			diffValue(a.Field(i), b.Field(i), p, changes)
+			continue
+		}
+		if field.Type.Kind() == reflect.Map {
+			diffMap(a.Field(i), b.Field(i), p, field.Tag.Get("secret") == "true", changes)
+			continue
+		}
+		if field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct {
+			diffSlice(a.Field(i), b.Field(i), p, changes)
+			continue
+		}
+		oldV, newV := formatValue(a.Field(i)), formatValue(b.Field(i))
+		if oldV == newV {
+			continue
+		}
+		if field.Tag.Get("secret") == "true" {
+			oldV, newV = maskSecret(oldV), maskSecret(newV)
+		}
+		*changes = append(*changes, Change{Path: p, Old: oldV, New: newV})
+	}
+}
+
+// diffMap compares a map key by key; a missing key compares as the zero
+// value
+func diffMap(a, b reflect.Value, path string, secret bool, changes *[]Change) {
+	keys := make(map[string]bool)
+	for _, m := range []reflect.Value{a, b} {
+		for _, k := range m.MapKeys() {
+			keys[k.String()] = true
+		}
+	}
+	zero := reflect.Zero(a.Type().Elem())
+	for _, k := range slices.Sorted(maps.Keys(keys)) {
+		key := reflect.ValueOf(k)
+		av, bv := a.MapIndex(key), b.MapIndex(key)
+		if !av.IsValid() {
+			av = zero
+		}
+		if !bv.IsValid() {
+			bv = zero
+		}
+		if zero.Kind() == reflect.Struct {
+			diffValue(av, bv, path+"."+k, changes)
+			continue
+		}
+		oldV, newV := formatValue(av), formatValue(bv)
+		if oldV == newV {
+			continue
+		}
+		if secret {
+			oldV, newV = maskSecret(oldV), maskSecret(newV)
+		}
+		*changes = append(*changes, Change{Path: path + "." + k, Old: oldV, New: newV})
+	}
+}
+
+// diffSlice compares a list of structs item by item; a missing item
+// compares as the zero value
+func diffSlice(a, b reflect.Value, path string, changes *[]Change) {
+	zero := reflect.Zero(a.Type().Elem())
+	for i := range max(a.Len(), b.Len()) {
+		av, bv := zero, zero
+		if i < a.Len() {
+			av = a.Index(i)
+		}
+		if i < b.Len() {
+			bv = b.Index(i)
+		}
+		diffValue(av, bv, path+"."+strconv.Itoa(i), changes)
+	}
+}
+
+// formatValue formats a setting the way it is written in the config file
+func formatValue(v reflect.Value) string {
+	switch x := v.Interface().(type) {
+	case Duration:
+		return time.Duration(x).String()
+	case []string:
+		return "[" + strings.Join(x, ", ") + "]"
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case string:
+		if x == "" {
+			return `""`
+		}
+		return x
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// maskSecret hides a secret value, keeping whether it is set
+func maskSecret(s string) string {
+	if s == `""` {
+		return s
+	}
+	return "(secret)"
+}
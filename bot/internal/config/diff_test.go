@@ -0,0 +1,56 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	old := Default()
+	old.Cost.AdminKey = "sk-old"
+
+	next := Default()
+	next.Monitor.CheckInterval = Duration(30 * time.Second)
+	next.NIC.Throttle.Ladder = []string{"2500", "1000"}
+	next.NIC.Hold.Critical.Samples = 3
+	next.Cost.DailyWarning = 2.5
+	next.Cost.AdminKey = "sk-new"
+	next.Log.ExcludePatterns = []string{"dhcp"}
+	next.Monitor.Checks = map[string]CheckSchedule{"log": {Jitter: Duration(5 * time.Second)}}
+	old.Notify.Backends = map[string]Backend{"hook": {Webhook: Webhook{URL: "https://example.com/hook", Headers: map[string]string{"X-Key": "a"}}}}
+	next.Notify.Backends = map[string]Backend{
+		"pager": {Discord: Discord{WebhookURL: "https://example.com/pager"}},
+		"hook":  {Webhook: Webhook{URL: "https://example.com/hook", Headers: map[string]string{"X-Key": "b", "X-Source": "pervigil"}}},
+	}
+	next.Notify.Routes = []Route{{Match: RouteMatch{Severity: []string{"critical"}}, To: []string{"pager"}}}
+
+	want := []Change{
+		{Path: "notify.backends.hook.webhook.headers.X-Key", Old: "(secret)", New: "(secret)"},
+		{Path: "notify.backends.hook.webhook.headers.X-Source", Old: `""`, New: "(secret)"},
+		{Path: "notify.backends.pager.discord.webhook_url", Old: `""`, New: "(secret)"},
+		{Path: "notify.routes.0.match.severity", Old: "[]", New: "[critical]"},
+		{Path: "notify.routes.0.to", Old: "[]", New: "[pager]"},
+		{Path: "monitor.check_interval", Old: "1m0s", New: "30s"},
+		{Path: "monitor.checks.log.jitter", Old: "0s", New: "5s"},
+		{Path: "nic.hold.critical.samples", Old: "0", New: "3"},
+		{Path: "nic.throttle.ladder", Old: "[1000]", New: "[2500, 1000]"},
+		{Path: "log.exclude_patterns", Old: "[]", New: "[dhcp]"},
+		{Path: "cost.anthropic_admin_key", Old: "(secret)", New: "(secret)"},
+		{Path: "cost.daily_warning", Old: "5", New: "2.5"},
+	}
+	if got := Diff(old, next); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %v\nwant %v", got, want)
+	}
+
+	if got := Diff(old, old); len(got) != 0 {
+		t.Errorf("Diff(old, old) = %v, want none", got)
+	}
+}
+
+func TestChange_String(t *testing.T) {
+	c := Change{Path: "monitor.dry_run", Old: "false", New: "true"}
+	if got := c.String(); got != "monitor.dry_run: false → true" {
+		t.Errorf("String() = %q", got)
+	}
+}
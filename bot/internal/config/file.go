@@ -0,0 +1,424 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is the config file read when neither --config nor
+// PERVIGIL_CONFIG is given. It lives under /config so it survives VyOS
+// image upgrades.
+const DefaultPath = "/config/pervigil/pervigil.yaml"
+
+// File is the configuration shared by pervigil-monitor and pervigil-bot.
+// A setting with an env tag is overridden by that environment variable;
+// the tag of a nested struct is a prefix for the tags of its fields.
+// Values of settings tagged secret are never shown by Diff.
+type File struct {
+	Notify  Notify  `yaml:"notify"`
+	Monitor Monitor `yaml:"monitor"`
+	NIC     NIC     `yaml:"nic"`
+	SFP     SFP     `yaml:"sfp"`
+	Sensors Sensors `yaml:"sensors"`
+	Log     Log     `yaml:"log"`
+	Cost    Cost    `yaml:"cost"`
+	History History `yaml:"history"`
+	Bot     Bot     `yaml:"bot"`
+}
+
+// Notify configures where alerts are sent. The notify.discord webhook is
+// the backend named "discord"; more are added under backends. Every alert
+// goes to the backends of each route it matches, or to the fallback
+// backends when it matches none.
+type Notify struct {
+	Discord  Discord            `yaml:"discord"`
+	Backends map[string]Backend `yaml:"backends"`
+	Routes   []Route            `yaml:"routes"`
+	Fallback []string           `yaml:"fallback"` // empty: [discord]
+	Outbox   Outbox             `yaml:"outbox"`
+}
+
+// FallbackBackends returns the backends of alerts no route matches
+func (n Notify) FallbackBackends() []string {
+	if len(n.Fallback) == 0 {
+		return []string{"discord"}
+	}
+	return n.Fallback
+}
+
+// Outbox queues the notifications a backend fails to send and retries them
+type Outbox struct {
+	Dir      string   `yaml:"dir"`       // one queue file per backend; "off" disables queueing
+	MaxSize  int      `yaml:"max_size"`  // queued alerts per backend; the oldest are dropped
+	RetryMin Duration `yaml:"retry_min"` // first retry delay, doubled per attempt
+	RetryMax Duration `yaml:"retry_max"`
+}
+
+// Backend is a named notification destination; exactly one type is set
+type Backend struct {
+	Discord  Discord  `yaml:"discord"`
+	Slack    Slack    `yaml:"slack"`
+	SMTP     SMTP     `yaml:"smtp"`
+	Telegram Telegram `yaml:"telegram"`
+	Ntfy     Ntfy     `yaml:"ntfy"`
+	Gotify   Gotify   `yaml:"gotify"`
+	Webhook  Webhook  `yaml:"webhook"`
+}
+
+// Type returns the backend type that is set (e.g. "slack"), or "" unless
+// exactly one is
+func (b Backend) Type() string {
+	types := backendTypes(b)
+	if len(types) != 1 {
+		return ""
+	}
+	return types[0]
+}
+
+// Route sends the alerts it matches to backends
+type Route struct {
+	Match RouteMatch `yaml:"match"`
+	To    []string   `yaml:"to"`
+}
+
+// RouteMatch selects alerts; empty criteria match every alert
+type RouteMatch struct {
+	Source   []string `yaml:"source"`   // NotifySources
+	Severity []string `yaml:"severity"` // critical, warning, recovery or info
+	Title    string   `yaml:"title"`    // regular expression
+}
+
+// NotifySources are the pervigil-monitor components that send alerts
+var NotifySources = []string{"nic", "sfp", "sensors", "log", "cost", "reload"}
+
+// Discord is the Discord webhook notifier
+type Discord struct {
+	WebhookURL string `yaml:"webhook_url" env:"DISCORD_WEBHOOK_URL" secret:"true"`
+}
+
+// Slack is the Slack incoming webhook notifier
+type Slack struct {
+	WebhookURL string `yaml:"webhook_url" secret:"true"`
+}
+
+// SMTP is the mail notifier
+type SMTP struct {
+	Host     string   `yaml:"host"`
+	Port     int      `yaml:"port"` // 0: 587 (starttls), 465 (implicit) or 25 (none)
+	TLS      string   `yaml:"tls"`  // starttls (default), implicit or none
+	Auth     string   `yaml:"auth"` // plain (default) or login; used when username is set
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password" secret:"true"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+}
+
+// Addr returns the server address with the default port of the TLS mode
+func (s SMTP) Addr() string {
+	port := s.Port
+	if port == 0 {
+		switch s.TLS {
+		case "implicit":
+			port = 465
+		case "none":
+			port = 25
+		default:
+			port = 587
+		}
+	}
+	return net.JoinHostPort(s.Host, strconv.Itoa(port))
+}
+
+// Telegram is the Telegram Bot API notifier
+type Telegram struct {
+	BaseURL string `yaml:"base_url"` // empty: https://api.telegram.org
+	Token   string `yaml:"token" secret:"true"`
+	ChatID  string `yaml:"chat_id"`
+}
+
+// Ntfy is the ntfy push notifier
+type Ntfy struct {
+	BaseURL string `yaml:"base_url"` // empty: https://ntfy.sh
+	Topic   string `yaml:"topic"`
+	Token   string `yaml:"token" secret:"true"` // access token of a protected topic
+}
+
+// Gotify is the Gotify push notifier
+type Gotify struct {
+	BaseURL string `yaml:"base_url"`
+	Token   string `yaml:"token" secret:"true"` // application token
+}
+
+// Webhook is the generic HTTP notifier. URL, method, headers and body are
+// text/templates; see notifier.WebhookData for the values they can use.
+type Webhook struct {
+	URL             string            `yaml:"url" secret:"true"`
+	Method          string            `yaml:"method"` // empty: POST
+	Headers         map[string]string `yaml:"headers" secret:"true"`
+	Body            string            `yaml:"body"`
+	Secret          string            `yaml:"secret" secret:"true"` // HMAC-SHA256 signing key
+	SignatureHeader string            `yaml:"signature_header"`     // empty: X-Pervigil-Signature
+	SuccessStatus   []string          `yaml:"success_status"`       // e.g. 202 or 2xx; empty: 2xx
+}
+
+// Monitor holds the pervigil-monitor process settings
+type Monitor struct {
+	CheckInterval         Duration `yaml:"check_interval" env:"CHECK_INTERVAL"`
+	StateFile             string   `yaml:"state_file" env:"STATE_FILE"`
+	ErrorSuppressInterval Duration `yaml:"error_suppress_interval" env:"ERROR_SUPPRESS_INTERVAL"`
+	DryRun                bool     `yaml:"dry_run" env:"DRY_RUN"`
+	MetricsListen         string   `yaml:"metrics_listen" env:"METRICS_LISTEN"` // empty disables /metrics
+	StatusAPI             string   `yaml:"status_api" env:"STATUS_API"`         // "off" disables; also read by the bot
+
+	Checks map[string]CheckSchedule `yaml:"checks"` // per-check schedules, keyed by CheckNames
+}
+
+// CheckNames are the checks run by pervigil-monitor
+var CheckNames = []string{"nic", "sfp", "sensors", "log", "cost", "history", "outbox"}
+
+// CheckSchedule overrides when one check runs
+type CheckSchedule struct {
+	Interval     Duration `yaml:"interval"` // 0: monitor.check_interval (cost: cost.check_interval)
+	StartupDelay Duration `yaml:"startup_delay"`
+	Jitter       Duration `yaml:"jitter"`  // random delay of up to this much before every run
+	Timeout      Duration `yaml:"timeout"` // 0: the interval (cost: 30s)
+}
+
+// Schedule returns the schedule of the named check with defaults filled in
+func (f *File) Schedule(name string) CheckSchedule {
+	s := f.Monitor.Checks[name]
+	if s.Interval == 0 {
+		s.Interval = f.Monitor.CheckInterval
+		if name == "cost" {
+			s.Interval = f.Cost.CheckInterval
+		}
+	}
+	if s.Timeout == 0 && name == "cost" {
+		s.Timeout = Duration(30 * time.Second)
+	}
+	return s
+}
+
+// NIC configures the NIC temperature monitor
+type NIC struct {
+	Interfaces []string  `yaml:"interfaces" env:"NIC_INTERFACE"`
+	Hold       Holds     `yaml:"hold"`
+	RiseAlert  RiseAlert `yaml:"rise_alert" env:"RISE_ALERT_"`
+	Throttle   Throttle  `yaml:"throttle" env:"THROTTLE_"`
+}
+
+// Holds are the hold conditions of each NIC state transition
+type Holds struct {
+	Warning  Hold `yaml:"warning" env:"WARNING_"`
+	Critical Hold `yaml:"critical" env:"CRITICAL_"`
+	Recovery Hold `yaml:"recovery" env:"RECOVERY_"`
+	Normal   Hold `yaml:"normal" env:"NORMAL_"`
+}
+
+// Hold is how long a transition condition must hold
+type Hold struct {
+	Samples  int      `yaml:"samples" env:"HOLD_SAMPLES"`
+	Duration Duration `yaml:"duration" env:"HOLD_SECONDS"`
+}
+
+// RiseAlert configures the rate-of-rise alert (Delta 0 disables it)
+type RiseAlert struct {
+	Delta  float64  `yaml:"delta" env:"DELTA"`
+	Window Duration `yaml:"window" env:"WINDOW"`
+}
+
+// Throttle configures the speed limit ladder
+type Throttle struct {
+	Ladder        []string `yaml:"ladder" env:"LADDER"` // Mbps or "down"
+	StepDownAfter Duration `yaml:"step_down_after" env:"STEP_DOWN_SECONDS"`
+	StepUpAfter   Duration `yaml:"step_up_after" env:"STEP_UP_SECONDS"`
+}
+
+// SFP configures the optical module DOM monitor and /sfp
+type SFP struct {
+	Interfaces []string `yaml:"interfaces" env:"SFP_INTERFACES"` // empty: the NIC (monitor) or network (bot) interfaces
+}
+
+// Sensors configures temperature sources and calibration
+type Sensors struct {
+	Sources string `yaml:"sources" env:"TEMP_SOURCES"` // see temperature.Registry.Configure
+
+	// Calibration is keyed by sensor label ("eth1", "Core 0", "acpitz") or
+	// category ("nic", "cpu", "board", "zone", "memory", "disk"). The
+	// variable takes the "eth1:offset=-4;nic:warning=72" form.
+	Calibration map[string]SensorCalibration `yaml:"calibration" env:"SENSOR_CALIBRATION"`
+}
+
+// SensorCalibration corrects a sensor's raw value (raw*scale + offset),
+// renames it and sets its thresholds. A sensor entry replaces the entry of
+// its category.
+type SensorCalibration struct {
+	Offset   float64 `yaml:"offset"`
+	Scale    float64 `yaml:"scale"` // 0: 1
+	Alias    string  `yaml:"alias"`
+	Warning  float64 `yaml:"warning"` // 0: the category default
+	Critical float64 `yaml:"critical"`
+	Recovery float64 `yaml:"recovery"`
+}
+
+// Equal reports whether s and o configure the same sources and calibration
+func (s Sensors) Equal(o Sensors) bool {
+	return s.Sources == o.Sources && maps.Equal(s.Calibration, o.Calibration)
+}
+
+// Calibrations returns the calibration in the form of temperature.Registry
+func (s Sensors) Calibrations() map[string]temperature.Calibration {
+	result := make(map[string]temperature.Calibration, len(s.Calibration))
+	for key, c := range s.Calibration {
+		result[key] = temperature.Calibration{
+			Offset: c.Offset,
+			Scale:  c.Scale,
+			Alias:  c.Alias,
+			Thresholds: temperature.Thresholds{
+				Warning:  c.Warning,
+				Critical: c.Critical,
+				Recovery: c.Recovery,
+			},
+		}
+	}
+	return result
+}
+
+// parseCalibration parses the SENSOR_CALIBRATION form of Sensors.Calibration
+func parseCalibration(spec string) (map[string]SensorCalibration, error) {
+	calibrations, err := temperature.ParseCalibrations(spec)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]SensorCalibration, len(calibrations))
+	for key, c := range calibrations {
+		result[key] = SensorCalibration{
+			Offset:   c.Offset,
+			Scale:    c.Scale,
+			Alias:    c.Alias,
+			Warning:  c.Warning,
+			Critical: c.Critical,
+			Recovery: c.Recovery,
+		}
+	}
+	return result, nil
+}
+
+// Log configures the syslog monitor. Empty pattern lists keep the
+// built-in patterns.
+type Log struct {
+	File             string   `yaml:"file" env:"LOG_FILE"`
+	PositionFile     string   `yaml:"position_file" env:"LOG_POS_FILE"`
+	WarningThreshold int      `yaml:"warning_threshold" env:"LOG_WARNING_THRESHOLD"`
+	ErrorPatterns    []string `yaml:"error_patterns"`
+	WarningPatterns  []string `yaml:"warning_patterns"`
+	ExcludePatterns  []string `yaml:"exclude_patterns"`
+}
+
+// Cost configures the Claude API cost monitor and /claude
+type Cost struct {
+	AdminKey      string   `yaml:"anthropic_admin_key" env:"ANTHROPIC_ADMIN_KEY" secret:"true"` // empty disables the monitor
+	CheckInterval Duration `yaml:"check_interval" env:"COST_CHECK_INTERVAL"`
+	DailyWarning  float64  `yaml:"daily_warning" env:"DAILY_BUDGET_WARN"`
+	DailyCritical float64  `yaml:"daily_critical" env:"DAILY_BUDGET_CRIT"`
+	StateFile     string   `yaml:"state_file" env:"COST_STATE_FILE"`
+}
+
+// History configures the metric history store
+type History struct {
+	Dir        string `yaml:"dir" env:"HISTORY_DIR"` // "off" disables recording
+	MaxMetrics int    `yaml:"max_metrics" env:"HISTORY_MAX_METRICS"`
+}
+
+// Bot holds the pervigil-bot settings
+type Bot struct {
+	Token             string   `yaml:"token" env:"BOT_TOKEN" secret:"true"`
+	GuildID           string   `yaml:"guild_id" env:"GUILD_ID"` // optional: for faster command registration
+	NetworkInterfaces []string `yaml:"network_interfaces" env:"MONITOR_NICS"`
+	CleanupCommands   bool     `yaml:"cleanup_commands" env:"CLEANUP_COMMANDS"`
+}
+
+// Default returns the built-in configuration
+func Default() *File {
+	return &File{
+		Notify: Notify{
+			Outbox: Outbox{
+				Dir:      "/config/pervigil/outbox",
+				MaxSize:  100,
+				RetryMin: Duration(30 * time.Second),
+				RetryMax: Duration(30 * time.Minute),
+			},
+		},
+		Monitor: Monitor{
+			CheckInterval:         Duration(60 * time.Second),
+			StateFile:             "/tmp/pervigil-state",
+			ErrorSuppressInterval: Duration(time.Hour),
+			StatusAPI:             "/run/pervigil/monitor.sock",
+		},
+		NIC: NIC{
+			Interfaces: []string{"eth1"},
+			RiseAlert:  RiseAlert{Window: Duration(5 * time.Minute)},
+			Throttle: Throttle{
+				Ladder:        []string{"1000"},
+				StepDownAfter: Duration(5 * time.Minute),
+			},
+		},
+		Log: Log{
+			File:             "/var/log/syslog",
+			PositionFile:     "/tmp/pervigil-log-pos",
+			WarningThreshold: 5,
+		},
+		Cost: Cost{
+			CheckInterval: Duration(time.Hour),
+			DailyWarning:  5,
+			DailyCritical: 10,
+			StateFile:     "/tmp/pervigil-cost-state",
+		},
+		History: History{
+			Dir:        "/config/pervigil/history",
+			MaxMetrics: 256,
+		},
+		Bot: Bot{
+			NetworkInterfaces: []string{"eth0", "eth1", "eth2"},
+		},
+	}
+}
+
+// Duration is a time.Duration written as "90s"/"5m" or as whole seconds
+type Duration time.Duration
+
+// parseDuration accepts a Go duration or a number of seconds
+func parseDuration(s string) (Duration, error) {
+	s = strings.TrimSpace(s)
+	if i, err := strconv.Atoi(s); err == nil {
+		return Duration(time.Duration(i) * time.Second), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q (want e.g. 90s, 5m or seconds)", s)
+	}
+	return Duration(d), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (d *Duration) UnmarshalYAML(n *yaml.Node) error {
+	v, err := parseDuration(n.Value)
+	if err != nil {
+		// A TypeError lets decoding continue and report every bad value
+		return &yaml.TypeError{Errors: []string{fmt.Sprintf("line %d: %v", n.Line, err)}}
+	}
+	*d = v
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (d Duration) MarshalYAML() (any, error) {
+	return time.Duration(d).String(), nil
+}
@@ -0,0 +1,277 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is one invalid setting
+type Problem struct {
+	Path string // setting, e.g. "nic.throttle.ladder"
+	Line int    // line in the config file; 0 when not set by the file
+	Env  string // environment variable that set the value, if any
+	Msg  string
+}
+
+func (p Problem) String() string {
+	switch {
+	case p.Env != "":
+		return fmt.Sprintf("%s (%s): %s", p.Env, p.Path, p.Msg)
+	case p.Path == "":
+		return p.Msg
+	default:
+		return fmt.Sprintf("%s: %s", p.Path, p.Msg)
+	}
+}
+
+// Error lists every problem found in a configuration
+type Error struct {
+	File     string
+	Problems []Problem
+}
+
+func (e *Error) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		if p.Line > 0 {
+			lines[i] = fmt.Sprintf("%s:%d: %s", e.File, p.Line, p)
+		} else {
+			lines[i] = p.String()
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Path returns the config file to read: PERVIGIL_CONFIG or DefaultPath
+func Path(env EnvGetter) string {
+	if p := env.Getenv("PERVIGIL_CONFIG"); p != "" {
+		return p
+	}
+	return DefaultPath
+}
+
+// LoadFile reads the config file at path over the defaults, applies
+// environment overrides and validates the result. A missing file is only
+// an error when it is not DefaultPath, so env-only setups keep working.
+// Every problem is reported, with its line number when it comes from the file.
+func LoadFile(path string, env EnvGetter) (*File, error) {
+	f := Default()
+	lines := make(map[string]int)
+	var problems []Problem
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && path == DefaultPath:
+	case err != nil:
+		return nil, err
+	default:
+		problems = append(problems, decode(data, f, lines)...)
+	}
+
+	envSet, envProblems := applyEnv(f, env)
+	problems = append(problems, envProblems...)
+	problems = append(problems, f.validate()...)
+
+	if len(problems) == 0 {
+		return f, nil
+	}
+	for i, p := range problems {
+		if name := envFor(envSet, p.Path); name != "" {
+			problems[i].Env = name
+		} else if p.Line == 0 {
+			problems[i].Line = lines[p.Path]
+		}
+	}
+	return nil, &Error{File: path, Problems: problems}
+}
+
+// envFor returns the variable that set path or one of its parents
+// ("nic.interfaces.1" was set by NIC_INTERFACE)
+func envFor(envSet map[string]string, path string) string {
+	for p := path; p != ""; {
+		if name, ok := envSet[p]; ok {
+			return name
+		}
+		i := strings.LastIndex(p, ".")
+		if i < 0 {
+			break
+		}
+		p = p[:i]
+	}
+	return ""
+}
+
+var (
+	yamlLine     = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	unknownField = regexp.MustCompile(`^field (\S+) not found in type \S+$`)
+)
+
+// decode strictly decodes data into f and indexes the line of every setting
+func decode(data []byte, f *File, lines map[string]int) []Problem {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return yamlProblems(err)
+	}
+	indexLines(&root, "", lines)
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(f); err != nil && !errors.Is(err, io.EOF) {
+		return yamlProblems(err)
+	}
+	return nil
+}
+
+// yamlProblems turns yaml.v3 errors ("line 3: ...") into problems
+func yamlProblems(err error) []Problem {
+	msgs := []string{err.Error()}
+	var te *yaml.TypeError
+	if errors.As(err, &te) {
+		msgs = te.Errors
+	}
+	problems := make([]Problem, 0, len(msgs))
+	for _, msg := range msgs {
+		p := Problem{Msg: msg}
+		if m := yamlLine.FindStringSubmatch(msg); m != nil {
+			p.Line, _ = strconv.Atoi(m[1])
+			p.Msg = m[2]
+		}
+		if m := unknownField.FindStringSubmatch(p.Msg); m != nil {
+			p.Msg = fmt.Sprintf("unknown setting %q", m[1])
+		}
+		problems = append(problems, p)
+	}
+	return problems
+}
+
+// indexLines records the line of every mapping key ("nic.hold.warning")
+// and sequence item ("log.error_patterns.2")
+func indexLines(n *yaml.Node, path string, lines map[string]int) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			indexLines(c, path, lines)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := join(n.Content[i].Value)
+			lines[key] = n.Content[i].Line
+			indexLines(n.Content[i+1], key, lines)
+		}
+	case yaml.SequenceNode:
+		for i, c := range n.Content {
+			lines[join(strconv.Itoa(i))] = c.Line
+			indexLines(c, join(strconv.Itoa(i)), lines)
+		}
+	}
+}
+
+// applyEnv overrides settings from their environment variables. It returns
+// the variable that set each overridden setting, keyed by setting path.
+func applyEnv(f *File, env EnvGetter) (map[string]string, []Problem) {
+	set := make(map[string]string)
+	var problems []Problem
+	walk(reflect.ValueOf(f).Elem(), "", "", func(path, name string, v reflect.Value) {
+		raw := env.Getenv(name)
+		if raw == "" {
+			return
+		}
+		if err := setValue(v, raw); err != nil {
+			problems = append(problems, Problem{Path: path, Env: name, Msg: err.Error()})
+			return
+		}
+		set[path] = name
+	})
+	return set, problems
+}
+
+// walk calls fn for every field with an env tag, with its setting path and
+// full variable name
+func walk(v reflect.Value, path, prefix string, fn func(path, name string, v reflect.Value)) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		p := key
+		if path != "" {
+			p = path + "." + key
+		}
+		name := field.Tag.Get("env")
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeFor[Duration]() {
+			walk(v.Field(i), p, prefix+name, fn)
+			continue
+		}
+		if name != "" {
+			fn(p, prefix+name, v.Field(i))
+		}
+	}
+}
+
+// setValue parses an environment value into a setting
+func setValue(v reflect.Value, raw string) error {
+	switch v.Interface().(type) {
+	case Duration:
+		d, err := parseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(d))
+	case string:
+		v.SetString(raw)
+	case []string:
+		v.Set(reflect.ValueOf(splitList(raw)))
+	case int:
+		i, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(int64(i))
+	case float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(f)
+	case bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case map[string]SensorCalibration:
+		c, err := parseCalibration(raw)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(c))
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(s string) []string {
+	var result []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
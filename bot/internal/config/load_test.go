@@ -0,0 +1,428 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "pervigil.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	path := writeConfig(t, `
+notify:
+  discord:
+    webhook_url: https://discord.com/api/webhooks/1/abc
+monitor:
+  check_interval: 30s
+  dry_run: true
+nic:
+  interfaces: [eth1, eth2]
+  hold:
+    critical: {samples: 3, duration: 120}
+  throttle:
+    ladder: [2500, 1000, down]
+log:
+  exclude_patterns:
+    - 'dhcp.*Truncated'
+cost:
+  daily_warning: 2
+  daily_critical: 4
+`)
+	f, err := LoadFile(path, &mapEnvGetter{values: map[string]string{
+		"CHECK_INTERVAL": "45",
+		"HISTORY_DIR":    "off",
+	}})
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	if f.Notify.Discord.WebhookURL != "https://discord.com/api/webhooks/1/abc" || !f.Monitor.DryRun {
+		t.Errorf("notify/monitor = %+v %+v", f.Notify, f.Monitor)
+	}
+	// Environment variables override the file
+	if got := time.Duration(f.Monitor.CheckInterval); got != 45*time.Second {
+		t.Errorf("check_interval = %v, want 45s from CHECK_INTERVAL", got)
+	}
+	if f.History.Dir != "off" {
+		t.Errorf("history.dir = %q", f.History.Dir)
+	}
+	if !reflect.DeepEqual(f.NIC.Interfaces, []string{"eth1", "eth2"}) {
+		t.Errorf("nic.interfaces = %v", f.NIC.Interfaces)
+	}
+	if h := f.NIC.Hold.Critical; h.Samples != 3 || time.Duration(h.Duration) != 2*time.Minute {
+		t.Errorf("hold.critical = %+v", h)
+	}
+	if !reflect.DeepEqual(f.NIC.Throttle.Ladder, []string{"2500", "1000", "down"}) {
+		t.Errorf("ladder = %v", f.NIC.Throttle.Ladder)
+	}
+	if len(f.Log.ExcludePatterns) != 1 || f.Cost.DailyWarning != 2 {
+		t.Errorf("log/cost = %+v %+v", f.Log, f.Cost)
+	}
+	// Unset settings keep their defaults
+	if f.Log.File != "/var/log/syslog" || time.Duration(f.NIC.Throttle.StepDownAfter) != 5*time.Minute {
+		t.Errorf("defaults lost: log.file=%q step_down_after=%v", f.Log.File, f.NIC.Throttle.StepDownAfter)
+	}
+}
+
+func TestLoadFile_Problems(t *testing.T) {
+	path := writeConfig(t, `monitor:
+  check_interval: soon
+  color: blue
+nic:
+  throttle:
+    ladder: [1000, fast]
+log:
+  error_patterns:
+    - '(unclosed'
+cost:
+  daily_warning: 20
+`)
+	_, err := LoadFile(path, &mapEnvGetter{values: map[string]string{
+		"DAILY_BUDGET_CRIT": "ten",
+		"NIC_INTERFACE":     "eth 1",
+	}})
+	var cfgErr *Error
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("LoadFile() error = %v, want *Error", err)
+	}
+
+	msg := err.Error()
+	for _, want := range []string{
+		path + `:2: invalid duration "soon"`,
+		path + `:3: unknown setting "color"`,
+		path + `:6: nic.throttle.ladder.1: invalid step "fast"`,
+		path + `:9: log.error_patterns.0: invalid pattern`,
+		`DAILY_BUDGET_CRIT (cost.daily_critical): invalid number "ten"`,
+		`NIC_INTERFACE (nic.interfaces.0): invalid interface name "eth 1"`,
+		path + `:11: cost.daily_warning: 20.00 is above daily_critical 10.00`,
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error missing %q:\n%s", want, msg)
+		}
+	}
+}
+
+func TestLoadFile_Missing(t *testing.T) {
+	env := &mapEnvGetter{values: map[string]string{}}
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml"), env); err == nil {
+		t.Error("an explicitly given config file must exist")
+	}
+
+	if _, err := os.Stat(DefaultPath); err == nil {
+		t.Skip(DefaultPath + " exists on this host")
+	}
+	f, err := LoadFile(DefaultPath, env)
+	if err != nil {
+		t.Fatalf("LoadFile(DefaultPath) error = %v", err)
+	}
+	if !reflect.DeepEqual(f, Default()) {
+		t.Errorf("LoadFile(DefaultPath) = %+v, want defaults", f)
+	}
+}
+
+func TestLoadWithEnv_ConfigFile(t *testing.T) {
+	path := writeConfig(t, "bot:\n  token: from-file\n  guild_id: g1\n")
+	cfg, err := LoadWithEnv(&mapEnvGetter{values: map[string]string{"PERVIGIL_CONFIG": path}})
+	if err != nil {
+		t.Fatalf("LoadWithEnv() error = %v", err)
+	}
+	if cfg.BotToken != "from-file" || cfg.GuildID != "g1" || cfg.File == nil {
+		t.Errorf("cfg = %+v", cfg)
+	}
+}
+
+func TestLoadFile_Checks(t *testing.T) {
+	path := writeConfig(t, `monitor:
+  check_interval: 30s
+  checks:
+    nic:
+      timeout: 10s
+    log:
+      interval: 5m
+      startup_delay: 20s
+      jitter: 3s
+cost:
+  check_interval: 2h
+`)
+	f, err := LoadFile(path, &mapEnvGetter{values: map[string]string{}})
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want CheckSchedule
+	}{
+		{"nic", CheckSchedule{Interval: Duration(30 * time.Second), Timeout: Duration(10 * time.Second)}},
+		{"log", CheckSchedule{
+			Interval:     Duration(5 * time.Minute),
+			StartupDelay: Duration(20 * time.Second),
+			Jitter:       Duration(3 * time.Second),
+		}},
+		{"sfp", CheckSchedule{Interval: Duration(30 * time.Second)}},
+		{"cost", CheckSchedule{Interval: Duration(2 * time.Hour), Timeout: Duration(30 * time.Second)}},
+	}
+	for _, tt := range tests {
+		if got := f.Schedule(tt.name); got != tt.want {
+			t.Errorf("Schedule(%q) = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	path = writeConfig(t, `monitor:
+  checks:
+    bgp: {interval: 1m}
+    nic: {jitter: -1s}
+`)
+	_, err = LoadFile(path, &mapEnvGetter{values: map[string]string{}})
+	if err == nil {
+		t.Fatal("LoadFile() accepted an unknown check")
+	}
+	for _, want := range []string{
+		path + `:3: monitor.checks.bgp: unknown check "bgp"`,
+		path + `:4: monitor.checks.nic.jitter: must not be negative`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoadFile_Routes(t *testing.T) {
+	path := writeConfig(t, `notify:
+  discord:
+    webhook_url: https://discord.com/api/webhooks/1/abc
+  backends:
+    pager:
+      discord: {webhook_url: https://discord.com/api/webhooks/2/def}
+    ops:
+      slack: {webhook_url: https://hooks.slack.com/services/T0/B0/xyz}
+    mail:
+      smtp:
+        host: smtp.example.com
+        tls: implicit
+        username: pervigil
+        password: secret
+        from: Pervigil <pervigil@example.com>
+        to: [ops@example.com, oncall@example.com]
+    phone:
+      ntfy: {base_url: "https://ntfy.example.com", topic: router-alerts}
+    tg:
+      telegram: {token: "123:ABC", chat_id: "-10042"}
+    pd:
+      webhook:
+        url: https://events.pagerduty.com/v2/enqueue
+        headers: {X-Routing-Key: abc}
+        body: |
+          {"routing_key": "abc", "event_action": "trigger",
+           "payload": {"summary": {{json .Title}}, "source": {{json .Hostname}}, "severity": "critical"}}
+        success_status: ["202"]
+  routes:
+    - match: {source: [nic, sfp], severity: [critical]}
+      to: [pager, discord]
+    - match: {title: '^💰'}
+      to: [pager, ops, phone, tg, pd]
+`)
+	f, err := LoadFile(path, &mapEnvGetter{values: map[string]string{}})
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if len(f.Notify.Routes) != 2 || !reflect.DeepEqual(f.Notify.Routes[0].Match.Source, []string{"nic", "sfp"}) {
+		t.Errorf("routes = %+v", f.Notify.Routes)
+	}
+	if got := f.Notify.FallbackBackends(); !reflect.DeepEqual(got, []string{"discord"}) {
+		t.Errorf("FallbackBackends() = %v, want [discord]", got)
+	}
+	if o := f.Notify.Outbox; o.Dir != "/config/pervigil/outbox" || o.MaxSize != 100 || o.RetryMin != Duration(30*time.Second) {
+		t.Errorf("outbox = %+v, want defaults", o)
+	}
+	mail := f.Notify.Backends["mail"]
+	if mail.Type() != "smtp" || mail.SMTP.Addr() != "smtp.example.com:465" || len(mail.SMTP.To) != 2 {
+		t.Errorf("mail backend = %+v (type %q, addr %s)", mail.SMTP, mail.Type(), mail.SMTP.Addr())
+	}
+	if pd := f.Notify.Backends["pd"]; pd.Type() != "webhook" || pd.Webhook.Headers["X-Routing-Key"] != "abc" || !strings.Contains(pd.Webhook.Body, `"routing_key"`) {
+		t.Errorf("pd backend = %+v (type %q)", pd.Webhook, pd.Type())
+	}
+
+	path = writeConfig(t, `notify:
+  backends:
+    discord:
+      discord: {webhook_url: https://example.com/a}
+    empty: {}
+    both:
+      discord: {webhook_url: https://example.com/a}
+      slack: {webhook_url: https://example.com/b}
+    team:
+      slack: {webhook_url: hooks.slack.com/services/x}
+  routes:
+    - match: {source: [bgp], severity: [fatal], title: '('}
+      to: [finance]
+    - match: {severity: [warning]}
+      to: [discord]
+  fallback: [empty]
+  outbox: {max_size: 0, retry_min: 10m, retry_max: 1m}
+`)
+	_, err = LoadFile(path, &mapEnvGetter{values: map[string]string{}})
+	if err == nil {
+		t.Fatal("LoadFile() accepted invalid routes")
+	}
+	for _, want := range []string{
+		path + `:3: notify.backends.discord: "discord" is the notify.discord webhook`,
+		path + `:5: notify.backends.empty: set exactly one backend type (discord, slack, smtp, telegram, ntfy, gotify, webhook)`,
+		path + `:6: notify.backends.both: set exactly one backend type`,
+		path + `:10: notify.backends.team.slack.webhook_url: invalid URL`,
+		path + `:13: notify.routes.0.to.0: unknown backend "finance"`,
+		path + `:12: notify.routes.0.match.source.0: unknown source "bgp"`,
+		path + `:12: notify.routes.0.match.severity.0: unknown severity "fatal"`,
+		path + `:12: notify.routes.0.match.title: invalid pattern`,
+		path + `:15: notify.routes.1.to.0: backend discord needs notify.discord.webhook_url`,
+		path + `:17: notify.outbox.max_size: must be greater than zero`,
+		path + `:17: notify.outbox.retry_max: 1m0s is below retry_min 10m0s`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoadFile_BackendProblems(t *testing.T) {
+	path := writeConfig(t, `notify:
+  backends:
+    mail:
+      smtp:
+        port: 70000
+        tls: ssl
+        auth: cram-md5
+        password: secret
+        from: not an address
+    relay:
+      smtp:
+        host: mail.example.com
+        tls: none
+        username: pervigil
+        from: pervigil@example.com
+        to: [ops@example.com, "@"]
+    tg:
+      telegram: {base_url: "https://tg.example.com"}
+    phone:
+      ntfy: {topic: "a/b"}
+    gotify:
+      gotify: {token: abc}
+    hook:
+      webhook:
+        url: "{{.Hostname"
+        headers: {Authorization: "{{end}}"}
+        body: "{{yaml .Title}}"
+        signature_header: X-Sig
+        success_status: [2XX]
+    plain:
+      webhook: {url: "example.com/hook"}
+`)
+	_, err := LoadFile(path, &mapEnvGetter{values: map[string]string{}})
+	if err == nil {
+		t.Fatal("LoadFile() accepted invalid backends")
+	}
+	for _, want := range []string{
+		`notify.backends.mail.smtp.host: is required`,
+		path + `:5: notify.backends.mail.smtp.port: invalid port 70000`,
+		path + `:6: notify.backends.mail.smtp.tls: unknown mode "ssl"`,
+		path + `:7: notify.backends.mail.smtp.auth: unknown mechanism "cram-md5"`,
+		`notify.backends.mail.smtp.username: is required to log in`,
+		path + `:9: notify.backends.mail.smtp.from: invalid address`,
+		`notify.backends.mail.smtp.to: at least one address is required`,
+		path + `:13: notify.backends.relay.smtp.tls: credentials are only sent over TLS or to localhost`,
+		path + `:16: notify.backends.relay.smtp.to.1: invalid address "@"`,
+		`notify.backends.tg.telegram.token: is required`,
+		`notify.backends.tg.telegram.chat_id: is required`,
+		path + `:20: notify.backends.phone.ntfy.topic: invalid topic "a/b"`,
+		`notify.backends.gotify.gotify.base_url: is required`,
+		path + `:25: notify.backends.hook.webhook.url: invalid template`,
+		path + `:26: notify.backends.hook.webhook.headers.Authorization: invalid template`,
+		path + `:27: notify.backends.hook.webhook.body: invalid template: template: webhook:1: function "yaml" not defined`,
+		`notify.backends.hook.webhook.secret: is required to sign requests`,
+		path + `:29: notify.backends.hook.webhook.success_status.0: invalid status "2XX"`,
+		path + `:31: notify.backends.plain.webhook.url: invalid URL "example.com/hook"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoadFile_SensorCalibration(t *testing.T) {
+	path := writeConfig(t, `sensors:
+  calibration:
+    eth1: {offset: -4, alias: uplink, critical: 90}
+    nic:
+      warning: 72
+`)
+	f, err := LoadFile(path, &mapEnvGetter{values: map[string]string{}})
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	want := map[string]SensorCalibration{
+		"eth1": {Offset: -4, Alias: "uplink", Critical: 90},
+		"nic":  {Warning: 72},
+	}
+	if !reflect.DeepEqual(f.Sensors.Calibration, want) {
+		t.Errorf("sensors.calibration = %+v", f.Sensors.Calibration)
+	}
+	if c := f.Sensors.Calibrations()["eth1"]; c.Offset != -4 || c.Alias != "uplink" || c.Critical != 90 {
+		t.Errorf("Calibrations()[eth1] = %+v", c)
+	}
+
+	// The variable keeps the compact form and replaces the file's entries
+	f, err = LoadFile(path, &mapEnvGetter{values: map[string]string{"SENSOR_CALIBRATION": "memory:critical=95"}})
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if want := map[string]SensorCalibration{"memory": {Critical: 95}}; !reflect.DeepEqual(f.Sensors.Calibration, want) {
+		t.Errorf("sensors.calibration = %+v, want %+v", f.Sensors.Calibration, want)
+	}
+}
+
+func TestLoadFile_SensorCalibrationProblems(t *testing.T) {
+	path := writeConfig(t, `sensors:
+  calibration:
+    eth1:
+      scale: -1
+      warning: 95
+      critical: 90
+      recovery: 90
+    nic:
+      offset: warm
+      hysteresis: 5
+`)
+	_, err := LoadFile(path, &mapEnvGetter{values: map[string]string{}})
+	if err == nil {
+		t.Fatal("LoadFile() accepted an invalid calibration")
+	}
+	msg := err.Error()
+	for _, want := range []string{
+		path + `:4: sensors.calibration.eth1.scale: must not be negative`,
+		path + `:5: sensors.calibration.eth1.warning: 95 is above critical 90`,
+		path + `:7: sensors.calibration.eth1.recovery: 90 is not below critical 90`,
+		path + `:9: `,
+		path + `:10: unknown setting "hysteresis"`,
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error missing %q:\n%s", want, msg)
+		}
+	}
+
+	_, err = LoadFile(writeConfig(t, ""), &mapEnvGetter{values: map[string]string{"SENSOR_CALIBRATION": "eth1:critical=hot"}})
+	if err == nil || !strings.Contains(err.Error(), "SENSOR_CALIBRATION (sensors.calibration): eth1: critical") {
+		t.Errorf("LoadFile() error = %v", err)
+	}
+}
@@ -0,0 +1,357 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
+)
+
+// validator collects problems keyed by setting path
+type validator struct {
+	problems []Problem
+}
+
+func (v *validator) fail(path, format string, args ...any) {
+	v.problems = append(v.problems, Problem{Path: path, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) positive(path string, ok bool) {
+	if !ok {
+		v.fail(path, "must be greater than zero")
+	}
+}
+
+func (v *validator) nonNegative(path string, ok bool) {
+	if !ok {
+		v.fail(path, "must not be negative")
+	}
+}
+
+func (v *validator) interfaces(path string, ifaces []string) {
+	for i, iface := range ifaces {
+		if iface == "" || strings.ContainsAny(iface, " /,") {
+			v.fail(fmt.Sprintf("%s.%d", path, i), "invalid interface name %q", iface)
+		}
+	}
+}
+
+func (v *validator) required(path, value string) {
+	if value == "" {
+		v.fail(path, "is required")
+	}
+}
+
+// url checks a required http(s) URL
+func (v *validator) url(path, u string) {
+	if u == "" {
+		v.fail(path, "is required")
+		return
+	}
+	if parsed, err := url.Parse(u); err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		v.fail(path, "invalid URL %q", u)
+	}
+}
+
+func (v *validator) patterns(path string, patterns []string) {
+	for i, p := range patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			v.fail(fmt.Sprintf("%s.%d", path, i), "invalid pattern: %v", err)
+		}
+	}
+}
+
+// validate checks the notification backends and routes
+func (n Notify) validate(v *validator) {
+	for _, name := range slices.Sorted(maps.Keys(n.Backends)) {
+		path := "notify.backends." + name
+		if name == "discord" {
+			v.fail(path, `"discord" is the notify.discord webhook; choose another name`)
+			continue
+		}
+		b := n.Backends[name]
+		switch b.Type() {
+		case "":
+			v.fail(path, "set exactly one backend type (%s)", strings.Join(allBackendTypes(), ", "))
+		case "discord":
+			v.url(path+".discord.webhook_url", b.Discord.WebhookURL)
+		case "slack":
+			v.url(path+".slack.webhook_url", b.Slack.WebhookURL)
+		case "smtp":
+			b.SMTP.validate(v, path+".smtp")
+		case "telegram":
+			if b.Telegram.BaseURL != "" {
+				v.url(path+".telegram.base_url", b.Telegram.BaseURL)
+			}
+			v.required(path+".telegram.token", b.Telegram.Token)
+			v.required(path+".telegram.chat_id", b.Telegram.ChatID)
+		case "ntfy":
+			if b.Ntfy.BaseURL != "" {
+				v.url(path+".ntfy.base_url", b.Ntfy.BaseURL)
+			}
+			if t := b.Ntfy.Topic; t == "" || strings.ContainsAny(t, "/ ") {
+				v.fail(path+".ntfy.topic", "invalid topic %q", t)
+			}
+		case "gotify":
+			v.url(path+".gotify.base_url", b.Gotify.BaseURL)
+			v.required(path+".gotify.token", b.Gotify.Token)
+		case "webhook":
+			b.Webhook.validate(v, path+".webhook")
+		}
+	}
+
+	backends := func(path string, names []string) {
+		for i, name := range names {
+			switch {
+			case name == "discord" && n.Discord.WebhookURL == "":
+				v.fail(fmt.Sprintf("%s.%d", path, i), "backend discord needs notify.discord.webhook_url")
+			case name != "discord" && !hasBackend(n.Backends, name):
+				v.fail(fmt.Sprintf("%s.%d", path, i), "unknown backend %q", name)
+			}
+		}
+	}
+	for i, r := range n.Routes {
+		path := fmt.Sprintf("notify.routes.%d", i)
+		if len(r.To) == 0 {
+			v.fail(path+".to", "at least one backend is required")
+		}
+		backends(path+".to", r.To)
+		for j, src := range r.Match.Source {
+			if !slices.Contains(NotifySources, src) {
+				v.fail(fmt.Sprintf("%s.match.source.%d", path, j), "unknown source %q (want one of %s)", src, strings.Join(NotifySources, ", "))
+			}
+		}
+		for j, sev := range r.Match.Severity {
+			if !slices.Contains(notifier.Severities, notifier.Severity(sev)) {
+				v.fail(fmt.Sprintf("%s.match.severity.%d", path, j), "unknown severity %q (want critical, warning, recovery or info)", sev)
+			}
+		}
+		if _, err := regexp.Compile(r.Match.Title); err != nil {
+			v.fail(path+".match.title", "invalid pattern: %v", err)
+		}
+	}
+	backends("notify.fallback", n.Fallback)
+
+	if n.Outbox.Dir != "off" {
+		v.required("notify.outbox.dir", n.Outbox.Dir)
+		v.positive("notify.outbox.max_size", n.Outbox.MaxSize > 0)
+		v.positive("notify.outbox.retry_min", n.Outbox.RetryMin > 0)
+		if n.Outbox.RetryMax < n.Outbox.RetryMin {
+			v.fail("notify.outbox.retry_max", "%s is below retry_min %s", time.Duration(n.Outbox.RetryMax), time.Duration(n.Outbox.RetryMin))
+		}
+	}
+}
+
+// validate checks a mail backend
+func (s SMTP) validate(v *validator, path string) {
+	if s.Host == "" {
+		v.fail(path+".host", "is required")
+	}
+	if s.Port < 0 || s.Port > 65535 {
+		v.fail(path+".port", "invalid port %d", s.Port)
+	}
+	if !slices.Contains([]string{"", "starttls", "implicit", "none"}, s.TLS) {
+		v.fail(path+".tls", "unknown mode %q (want starttls, implicit or none)", s.TLS)
+	}
+	if !slices.Contains([]string{"", "plain", "login"}, s.Auth) {
+		v.fail(path+".auth", "unknown mechanism %q (want plain or login)", s.Auth)
+	}
+	if s.Username == "" && (s.Password != "" || s.Auth != "") {
+		v.fail(path+".username", "is required to log in")
+	}
+	if s.Username != "" && s.TLS == "none" && s.Host != "localhost" && s.Host != "127.0.0.1" && s.Host != "::1" {
+		v.fail(path+".tls", "credentials are only sent over TLS or to localhost")
+	}
+	if _, err := mail.ParseAddress(s.From); err != nil {
+		v.fail(path+".from", "invalid address %q", s.From)
+	}
+	if len(s.To) == 0 {
+		v.fail(path+".to", "at least one address is required")
+	}
+	for i, to := range s.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			v.fail(fmt.Sprintf("%s.to.%d", path, i), "invalid address %q", to)
+		}
+	}
+}
+
+// validate checks a webhook backend; templates are only parsed, since
+// what they render depends on the alert
+func (w Webhook) validate(v *validator, path string) {
+	if w.URL == "" {
+		v.fail(path+".url", "is required")
+	} else if !strings.Contains(w.URL, "{{") {
+		v.url(path+".url", w.URL)
+	}
+	templates := map[string]string{"url": w.URL, "method": w.Method, "body": w.Body}
+	for name, h := range w.Headers {
+		templates["headers."+name] = h
+	}
+	for _, key := range slices.Sorted(maps.Keys(templates)) {
+		if _, err := notifier.ParseWebhookTemplate(templates[key]); err != nil {
+			v.fail(path+"."+key, "invalid template: %v", err)
+		}
+	}
+	if w.SignatureHeader != "" && w.Secret == "" {
+		v.fail(path+".secret", "is required to sign requests")
+	}
+	for i, p := range w.SuccessStatus {
+		if !notifier.ValidStatusPattern(p) {
+			v.fail(fmt.Sprintf("%s.success_status.%d", path, i), "invalid status %q (want e.g. 200 or 2xx)", p)
+		}
+	}
+}
+
+// validate checks one sensor calibration
+func (c SensorCalibration) validate(v *validator, path string) {
+	v.nonNegative(path+".scale", c.Scale >= 0)
+	v.nonNegative(path+".warning", c.Warning >= 0)
+	v.nonNegative(path+".critical", c.Critical >= 0)
+	v.nonNegative(path+".recovery", c.Recovery >= 0)
+	if c.Warning > 0 && c.Critical > 0 && c.Warning > c.Critical {
+		v.fail(path+".warning", "%g is above critical %g", c.Warning, c.Critical)
+	}
+	if c.Recovery > 0 && c.Critical > 0 && c.Recovery >= c.Critical {
+		v.fail(path+".recovery", "%g is not below critical %g", c.Recovery, c.Critical)
+	}
+}
+
+// backendTypes returns the types set in b
+func backendTypes(b Backend) []string {
+	v := reflect.ValueOf(b)
+	var types []string
+	for i := range v.NumField() {
+		if !v.Field(i).IsZero() {
+			types = append(types, yamlKey(v.Type().Field(i)))
+		}
+	}
+	return types
+}
+
+// allBackendTypes returns every backend type
+func allBackendTypes() []string {
+	t := reflect.TypeFor[Backend]()
+	types := make([]string, t.NumField())
+	for i := range t.NumField() {
+		types[i] = yamlKey(t.Field(i))
+	}
+	return types
+}
+
+func yamlKey(f reflect.StructField) string {
+	key, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	return key
+}
+
+func hasBackend(backends map[string]Backend, name string) bool {
+	_, ok := backends[name]
+	return ok
+}
+
+// validate checks the values that decoding alone cannot
+func (f *File) validate() []Problem {
+	var v validator
+
+	if u := f.Notify.Discord.WebhookURL; u != "" {
+		v.url("notify.discord.webhook_url", u)
+	}
+	f.Notify.validate(&v)
+
+	v.positive("monitor.check_interval", f.Monitor.CheckInterval > 0)
+	v.positive("monitor.error_suppress_interval", f.Monitor.ErrorSuppressInterval > 0)
+	if addr := f.Monitor.MetricsListen; addr != "" {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			v.fail("monitor.metrics_listen", "invalid address %q (want host:port or :port)", addr)
+		}
+	}
+	if addr := f.Monitor.StatusAPI; addr != "off" && !strings.Contains(addr, "/") {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			v.fail("monitor.status_api", "invalid address %q (want a socket path, host:port or off)", addr)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(f.Monitor.Checks)) {
+		path := "monitor.checks." + name
+		if !slices.Contains(CheckNames, name) {
+			v.fail(path, "unknown check %q (want one of %s)", name, strings.Join(CheckNames, ", "))
+			continue
+		}
+		s := f.Monitor.Checks[name]
+		v.nonNegative(path+".interval", s.Interval >= 0)
+		v.nonNegative(path+".startup_delay", s.StartupDelay >= 0)
+		v.nonNegative(path+".jitter", s.Jitter >= 0)
+		v.nonNegative(path+".timeout", s.Timeout >= 0)
+	}
+
+	if len(f.NIC.Interfaces) == 0 {
+		v.fail("nic.interfaces", "at least one interface is required")
+	}
+	v.interfaces("nic.interfaces", f.NIC.Interfaces)
+	holds := []struct {
+		name string
+		hold Hold
+	}{
+		{"warning", f.NIC.Hold.Warning},
+		{"critical", f.NIC.Hold.Critical},
+		{"recovery", f.NIC.Hold.Recovery},
+		{"normal", f.NIC.Hold.Normal},
+	}
+	for _, h := range holds {
+		v.nonNegative("nic.hold."+h.name+".samples", h.hold.Samples >= 0)
+		v.nonNegative("nic.hold."+h.name+".duration", h.hold.Duration >= 0)
+	}
+	v.nonNegative("nic.rise_alert.delta", f.NIC.RiseAlert.Delta >= 0)
+	v.positive("nic.rise_alert.window", f.NIC.RiseAlert.Window > 0)
+	if len(f.NIC.Throttle.Ladder) == 0 {
+		v.fail("nic.throttle.ladder", "at least one step is required")
+	}
+	for i, step := range f.NIC.Throttle.Ladder {
+		if mbps, err := strconv.Atoi(step); step != "down" && (err != nil || mbps <= 0) {
+			v.fail("nic.throttle.ladder."+strconv.Itoa(i), "invalid step %q (want Mbps or down)", step)
+		}
+	}
+	v.nonNegative("nic.throttle.step_down_after", f.NIC.Throttle.StepDownAfter >= 0)
+	v.nonNegative("nic.throttle.step_up_after", f.NIC.Throttle.StepUpAfter >= 0)
+
+	v.interfaces("sfp.interfaces", f.SFP.Interfaces)
+
+	if err := temperature.NewRegistry().Configure(f.Sensors.Sources); err != nil {
+		v.fail("sensors.sources", "%v", err)
+	}
+	for _, key := range slices.Sorted(maps.Keys(f.Sensors.Calibration)) {
+		f.Sensors.Calibration[key].validate(&v, "sensors.calibration."+key)
+	}
+
+	if f.Log.File == "" {
+		v.fail("log.file", "is required")
+	}
+	if f.Log.PositionFile == "" {
+		v.fail("log.position_file", "is required")
+	}
+	v.positive("log.warning_threshold", f.Log.WarningThreshold > 0)
+	v.patterns("log.error_patterns", f.Log.ErrorPatterns)
+	v.patterns("log.warning_patterns", f.Log.WarningPatterns)
+	v.patterns("log.exclude_patterns", f.Log.ExcludePatterns)
+
+	v.positive("cost.check_interval", f.Cost.CheckInterval > 0)
+	v.positive("cost.daily_warning", f.Cost.DailyWarning > 0)
+	v.positive("cost.daily_critical", f.Cost.DailyCritical > 0)
+	if f.Cost.DailyWarning > f.Cost.DailyCritical {
+		v.fail("cost.daily_warning", "%.2f is above daily_critical %.2f", f.Cost.DailyWarning, f.Cost.DailyCritical)
+	}
+
+	v.positive("history.max_metrics", f.History.MaxMetrics > 0)
+
+	v.interfaces("bot.network_interfaces", f.Bot.NetworkInterfaces)
+	return v.problems
+}
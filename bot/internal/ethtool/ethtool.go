@@ -0,0 +1,274 @@
+// Package ethtool talks to the kernel ethtool interface (SIOCETHTOOL ioctl)
+// without depending on the ethtool binary.
+package ethtool
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotSupported indicates the driver or module does not provide the data.
+var ErrNotSupported = errors.New("not supported by device")
+
+// ethtool commands (include/uapi/linux/ethtool.h)
+const (
+	cmdGStrings      = 0x1b
+	cmdGStats        = 0x1d
+	cmdGSSetInfo     = 0x37
+	cmdGModuleInfo   = 0x42
+	cmdGModuleEEPROM = 0x43
+	cmdGLinkSettings = 0x4c
+	cmdSLinkSettings = 0x4d
+)
+
+const (
+	ssStats     = 1  // ETH_SS_STATS
+	gstringLen  = 32 // ETH_GSTRING_LEN
+	iffUp       = 0x1
+	autonegOn   = 0x01
+	autonegOff  = 0x00
+	linkHdrSize = 48 // sizeof(struct ethtool_link_settings)
+)
+
+// struct ethtool_link_settings field offsets
+const (
+	offSpeed   = 4
+	offDuplex  = 8
+	offAutoneg = 11
+	offNwords  = 15
+)
+
+// DuplexFull is the full duplex value of LinkSettings.Duplex (DUPLEX_FULL).
+const DuplexFull = 0x01
+
+// Module EEPROM types (ETH_MODULE_SFF_*)
+const (
+	moduleSFF8079 = 0x1
+	moduleSFF8472 = 0x2
+	moduleSFF8636 = 0x3
+	moduleSFF8436 = 0x4
+)
+
+var byteOrder = binary.NativeEndian
+
+// requester abstracts kernel requests (for testing)
+type requester interface {
+	// Ethtool issues SIOCETHTOOL; data is read and written in place.
+	Ethtool(iface string, data []byte) error
+	Flags(iface string) (uint16, error)
+	SetFlags(iface string, flags uint16) error
+}
+
+// Client issues ethtool requests to the kernel.
+type Client struct {
+	req requester
+}
+
+// New creates a client using the SIOCETHTOOL ioctl.
+func New() *Client {
+	return &Client{req: &ioctlRequester{}}
+}
+
+// NewWith creates a client with a custom requester (for testing).
+func NewWith(r requester) *Client {
+	return &Client{req: r}
+}
+
+// LinkSettings mirrors struct ethtool_link_settings.
+type LinkSettings struct {
+	Speed       uint32 // Mbps
+	Duplex      uint8
+	Autoneg     bool
+	Supported   Bitmap
+	Advertising Bitmap
+
+	raw    []byte // last buffer returned by the kernel, reused on set
+	nwords int
+}
+
+// LinkSettings reads the current link settings of iface.
+func (c *Client) LinkSettings(iface string) (*LinkSettings, error) {
+	// Handshake: the kernel answers a zero nwords request with -nwords.
+	hdr := make([]byte, linkHdrSize)
+	byteOrder.PutUint32(hdr[0:], cmdGLinkSettings)
+	if err := c.req.Ethtool(iface, hdr); err != nil {
+		return nil, fmt.Errorf("get link settings %s: %w", iface, err)
+	}
+	nwords := -int(int8(hdr[offNwords]))
+	if nwords <= 0 {
+		return nil, fmt.Errorf("get link settings %s: bad handshake (%d words)", iface, nwords)
+	}
+
+	buf := make([]byte, linkHdrSize+3*4*nwords)
+	byteOrder.PutUint32(buf[0:], cmdGLinkSettings)
+	buf[offNwords] = byte(int8(nwords))
+	if err := c.req.Ethtool(iface, buf); err != nil {
+		return nil, fmt.Errorf("get link settings %s: %w", iface, err)
+	}
+
+	ls := &LinkSettings{
+		Speed:   byteOrder.Uint32(buf[offSpeed:]),
+		Duplex:  buf[offDuplex],
+		Autoneg: buf[offAutoneg] == autonegOn,
+		raw:     buf,
+		nwords:  nwords,
+	}
+	ls.Supported = readBitmap(buf, 0, nwords)
+	ls.Advertising = readBitmap(buf, 1, nwords)
+	return ls, nil
+}
+
+// SetLinkSettings applies speed, duplex, autoneg and advertising to iface.
+// ls must come from LinkSettings so unrelated fields are preserved.
+func (c *Client) SetLinkSettings(iface string, ls *LinkSettings) error {
+	if ls.raw == nil {
+		return fmt.Errorf("set link settings %s: settings not read from device", iface)
+	}
+	buf := append([]byte(nil), ls.raw...)
+	byteOrder.PutUint32(buf[0:], cmdSLinkSettings)
+	byteOrder.PutUint32(buf[offSpeed:], ls.Speed)
+	buf[offDuplex] = ls.Duplex
+	buf[offAutoneg] = autonegOff
+	if ls.Autoneg {
+		buf[offAutoneg] = autonegOn
+	}
+	writeBitmap(buf, 1, ls.nwords, ls.Advertising)
+	if err := c.req.Ethtool(iface, buf); err != nil {
+		return fmt.Errorf("set link settings %s: %w", iface, err)
+	}
+	return nil
+}
+
+// LinkUp reports whether iface is administratively up.
+func (c *Client) LinkUp(iface string) (bool, error) {
+	flags, err := c.req.Flags(iface)
+	if err != nil {
+		return false, fmt.Errorf("get flags %s: %w", iface, err)
+	}
+	return flags&iffUp != 0, nil
+}
+
+// SetLinkUp brings iface administratively up or down.
+func (c *Client) SetLinkUp(iface string, up bool) error {
+	flags, err := c.req.Flags(iface)
+	if err != nil {
+		return fmt.Errorf("get flags %s: %w", iface, err)
+	}
+	if up {
+		flags |= iffUp
+	} else {
+		flags &^= iffUp
+	}
+	if err := c.req.SetFlags(iface, flags); err != nil {
+		return fmt.Errorf("set flags %s: %w", iface, err)
+	}
+	return nil
+}
+
+// ModuleEEPROM returns the module EEPROM type and contents.
+func (c *Client) ModuleEEPROM(iface string) (uint32, []byte, error) {
+	info := make([]byte, 44) // struct ethtool_modinfo
+	byteOrder.PutUint32(info[0:], cmdGModuleInfo)
+	if err := c.req.Ethtool(iface, info); err != nil {
+		return 0, nil, fmt.Errorf("get module info %s: %w", iface, err)
+	}
+	typ := byteOrder.Uint32(info[4:])
+	length := byteOrder.Uint32(info[8:])
+	if length == 0 {
+		return 0, nil, fmt.Errorf("module eeprom %s: %w", iface, ErrNotSupported)
+	}
+
+	buf := make([]byte, 16+length) // struct ethtool_eeprom + data
+	byteOrder.PutUint32(buf[0:], cmdGModuleEEPROM)
+	byteOrder.PutUint32(buf[12:], length)
+	if err := c.req.Ethtool(iface, buf); err != nil {
+		return 0, nil, fmt.Errorf("get module eeprom %s: %w", iface, err)
+	}
+	return typ, buf[16:], nil
+}
+
+// ModuleTemperature returns the plugged module's DOM temperature in °C.
+func (c *Client) ModuleTemperature(iface string) (float64, error) {
+	typ, eeprom, err := c.ModuleEEPROM(iface)
+	if err != nil {
+		return 0, err
+	}
+
+	var off int
+	switch typ {
+	case moduleSFF8472:
+		// A0h byte 92 bit 6: digital diagnostics implemented; temp at A2h bytes 96-97
+		if len(eeprom) < 512 || eeprom[92]&0x40 == 0 {
+			return 0, fmt.Errorf("module temperature %s: %w", iface, ErrNotSupported)
+		}
+		off = 256 + 96
+	case moduleSFF8636, moduleSFF8436:
+		off = 22
+	default:
+		return 0, fmt.Errorf("module temperature %s: %w", iface, ErrNotSupported)
+	}
+	if len(eeprom) < off+2 {
+		return 0, fmt.Errorf("module temperature %s: short eeprom", iface)
+	}
+	return float64(int16(binary.BigEndian.Uint16(eeprom[off:]))) / 256, nil
+}
+
+// Stats returns the driver statistics (as shown by ethtool -S).
+func (c *Client) Stats(iface string) (map[string]uint64, error) {
+	info := make([]byte, 20) // struct ethtool_sset_info + one u32
+	byteOrder.PutUint32(info[0:], cmdGSSetInfo)
+	byteOrder.PutUint64(info[8:], 1<<ssStats)
+	if err := c.req.Ethtool(iface, info); err != nil {
+		return nil, fmt.Errorf("get stats count %s: %w", iface, err)
+	}
+	if byteOrder.Uint64(info[8:]) == 0 {
+		return nil, fmt.Errorf("stats %s: %w", iface, ErrNotSupported)
+	}
+	n := int(byteOrder.Uint32(info[16:]))
+
+	names := make([]byte, 12+n*gstringLen) // struct ethtool_gstrings + data
+	byteOrder.PutUint32(names[0:], cmdGStrings)
+	byteOrder.PutUint32(names[4:], ssStats)
+	byteOrder.PutUint32(names[8:], uint32(n))
+	if err := c.req.Ethtool(iface, names); err != nil {
+		return nil, fmt.Errorf("get stats names %s: %w", iface, err)
+	}
+
+	values := make([]byte, 8+n*8) // struct ethtool_stats + data
+	byteOrder.PutUint32(values[0:], cmdGStats)
+	byteOrder.PutUint32(values[4:], uint32(n))
+	if err := c.req.Ethtool(iface, values); err != nil {
+		return nil, fmt.Errorf("get stats %s: %w", iface, err)
+	}
+
+	stats := make(map[string]uint64, n)
+	for i := 0; i < n; i++ {
+		raw := names[12+i*gstringLen : 12+(i+1)*gstringLen]
+		name := strings.TrimRight(string(raw), "\x00")
+		stats[name] = byteOrder.Uint64(values[8+i*8:])
+	}
+	return stats, nil
+}
+
+// readBitmap reads the idx-th link mode mask (supported, advertising, lp_advertising).
+func readBitmap(buf []byte, idx, nwords int) Bitmap {
+	b := make(Bitmap, nwords)
+	base := linkHdrSize + idx*nwords*4
+	for i := range b {
+		b[i] = byteOrder.Uint32(buf[base+i*4:])
+	}
+	return b
+}
+
+func writeBitmap(buf []byte, idx, nwords int, b Bitmap) {
+	base := linkHdrSize + idx*nwords*4
+	for i := 0; i < nwords; i++ {
+		var w uint32
+		if i < len(b) {
+			w = b[i]
+		}
+		byteOrder.PutUint32(buf[base+i*4:], w)
+	}
+}
@@ -0,0 +1,213 @@
+package ethtool
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+// fakeRequester emulates the kernel side of the SIOCETHTOOL ioctl.
+type fakeRequester struct {
+	nwords    int
+	speed     uint32
+	autoneg   bool
+	supported Bitmap
+	adv       Bitmap
+	flags     uint16
+
+	moduleType uint32
+	eeprom     []byte
+
+	statNames  []string
+	statValues []uint64
+
+	lastSet []byte
+	err     error
+}
+
+func (f *fakeRequester) Ethtool(_ string, data []byte) error {
+	if f.err != nil {
+		return f.err
+	}
+	switch byteOrder.Uint32(data) {
+	case cmdGLinkSettings:
+		if int8(data[offNwords]) == 0 {
+			data[offNwords] = byte(int8(-f.nwords))
+			return nil
+		}
+		byteOrder.PutUint32(data[offSpeed:], f.speed)
+		data[offDuplex] = DuplexFull
+		if f.autoneg {
+			data[offAutoneg] = autonegOn
+		}
+		writeBitmap(data, 0, f.nwords, f.supported)
+		writeBitmap(data, 1, f.nwords, f.adv)
+	case cmdSLinkSettings:
+		f.lastSet = append([]byte(nil), data...)
+	case cmdGModuleInfo:
+		byteOrder.PutUint32(data[4:], f.moduleType)
+		byteOrder.PutUint32(data[8:], uint32(len(f.eeprom)))
+	case cmdGModuleEEPROM:
+		copy(data[16:], f.eeprom)
+	case cmdGSSetInfo:
+		byteOrder.PutUint32(data[16:], uint32(len(f.statNames)))
+	case cmdGStrings:
+		for i, name := range f.statNames {
+			copy(data[12+i*gstringLen:], name)
+		}
+	case cmdGStats:
+		for i, v := range f.statValues {
+			byteOrder.PutUint64(data[8+i*8:], v)
+		}
+	default:
+		return syscall.EOPNOTSUPP
+	}
+	return nil
+}
+
+func (f *fakeRequester) Flags(string) (uint16, error) {
+	return f.flags, f.err
+}
+
+func (f *fakeRequester) SetFlags(_ string, flags uint16) error {
+	f.flags = flags
+	return f.err
+}
+
+// x540Modes is 100baseT/Full | 1000baseT/Full | 10000baseT/Full
+var x540Modes = Bitmap{1<<3 | 1<<5 | 1<<12, 0, 0}
+
+func TestClient_LinkSettings(t *testing.T) {
+	fake := &fakeRequester{nwords: 3, speed: 10000, autoneg: true, supported: x540Modes, adv: x540Modes}
+	c := NewWith(fake)
+
+	ls, err := c.LinkSettings("eth1")
+	if err != nil {
+		t.Fatalf("LinkSettings() error = %v", err)
+	}
+	if ls.Speed != 10000 || !ls.Autoneg || ls.Duplex != DuplexFull {
+		t.Errorf("LinkSettings() = %+v", ls)
+	}
+	if !reflect.DeepEqual(ls.Supported.Speeds(), []int{100, 1000, 10000}) {
+		t.Errorf("Supported.Speeds() = %v", ls.Supported.Speeds())
+	}
+}
+
+func TestClient_SetLinkSettings(t *testing.T) {
+	fake := &fakeRequester{nwords: 3, speed: 10000, autoneg: true, supported: x540Modes, adv: x540Modes}
+	c := NewWith(fake)
+
+	ls, err := c.LinkSettings("eth1")
+	if err != nil {
+		t.Fatalf("LinkSettings() error = %v", err)
+	}
+	ls.Speed = 1000
+	ls.Autoneg = false
+	ls.Advertising = Bitmap{1 << 5}
+	if err := c.SetLinkSettings("eth1", ls); err != nil {
+		t.Fatalf("SetLinkSettings() error = %v", err)
+	}
+
+	if got := byteOrder.Uint32(fake.lastSet[offSpeed:]); got != 1000 {
+		t.Errorf("speed = %d, want 1000", got)
+	}
+	if fake.lastSet[offAutoneg] != autonegOff {
+		t.Error("autoneg should be off")
+	}
+	if got := readBitmap(fake.lastSet, 1, 3); !reflect.DeepEqual(got, Bitmap{1 << 5, 0, 0}) {
+		t.Errorf("advertising = %v", got)
+	}
+	if got := readBitmap(fake.lastSet, 0, 3); !reflect.DeepEqual(got, x540Modes) {
+		t.Errorf("supported mask should be preserved, got %v", got)
+	}
+}
+
+func TestClient_SetLinkSettings_RequiresRead(t *testing.T) {
+	c := NewWith(&fakeRequester{})
+	if err := c.SetLinkSettings("eth1", &LinkSettings{Speed: 1000}); err == nil {
+		t.Error("expected error for settings not read from device")
+	}
+}
+
+func TestClient_SetLinkUp(t *testing.T) {
+	fake := &fakeRequester{flags: iffUp | 0x1000}
+	c := NewWith(fake)
+
+	if err := c.SetLinkUp("eth1", false); err != nil {
+		t.Fatalf("SetLinkUp() error = %v", err)
+	}
+	if fake.flags != 0x1000 {
+		t.Errorf("flags = %#x, want 0x1000", fake.flags)
+	}
+	up, err := c.LinkUp("eth1")
+	if err != nil || up {
+		t.Errorf("LinkUp() = %v, %v, want false", up, err)
+	}
+}
+
+func TestClient_ModuleTemperature_SFF8472(t *testing.T) {
+	eeprom := make([]byte, 512)
+	eeprom[92] = 0x40
+	binary.BigEndian.PutUint16(eeprom[256+96:], uint16(int16(41*256+128))) // 41.5°C
+	c := NewWith(&fakeRequester{moduleType: moduleSFF8472, eeprom: eeprom})
+
+	temp, err := c.ModuleTemperature("eth1")
+	if err != nil {
+		t.Fatalf("ModuleTemperature() error = %v", err)
+	}
+	if temp != 41.5 {
+		t.Errorf("ModuleTemperature() = %v, want 41.5", temp)
+	}
+}
+
+func TestClient_ModuleTemperature_NoDiagnostics(t *testing.T) {
+	c := NewWith(&fakeRequester{moduleType: moduleSFF8472, eeprom: make([]byte, 512)})
+
+	_, err := c.ModuleTemperature("eth1")
+	if !errors.Is(err, ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestClient_ModuleTemperature_Negative(t *testing.T) {
+	eeprom := make([]byte, 256)
+	binary.BigEndian.PutUint16(eeprom[22:], uint16(0xFB00)) // -5°C
+	c := NewWith(&fakeRequester{moduleType: moduleSFF8636, eeprom: eeprom})
+
+	temp, err := c.ModuleTemperature("eth1")
+	if err != nil {
+		t.Fatalf("ModuleTemperature() error = %v", err)
+	}
+	if temp != -5 {
+		t.Errorf("ModuleTemperature() = %v, want -5", temp)
+	}
+}
+
+func TestClient_Stats(t *testing.T) {
+	c := NewWith(&fakeRequester{
+		statNames:  []string{"rx_packets", "temp"},
+		statValues: []uint64{1234, 52},
+	})
+
+	stats, err := c.Stats("eth1")
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	want := map[string]uint64{"rx_packets": 1234, "temp": 52}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("Stats() = %v, want %v", stats, want)
+	}
+}
+
+func TestClient_PropagatesErrors(t *testing.T) {
+	c := NewWith(&fakeRequester{err: syscall.EOPNOTSUPP})
+
+	if _, err := c.LinkSettings("eth1"); !errors.Is(err, syscall.EOPNOTSUPP) {
+		t.Errorf("LinkSettings() error = %v, want EOPNOTSUPP", err)
+	}
+	if _, err := c.Stats("eth1"); !errors.Is(err, syscall.EOPNOTSUPP) {
+		t.Errorf("Stats() error = %v, want EOPNOTSUPP", err)
+	}
+}
@@ -0,0 +1,83 @@
+package ethtool
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	siocEthtool  = 0x8946
+	siocGIFFlags = 0x8913
+	siocSIFFlags = 0x8914
+)
+
+// ifreqData is struct ifreq with the ifr_data member of the union.
+type ifreqData struct {
+	name [syscall.IFNAMSIZ]byte
+	data unsafe.Pointer
+	_    [16]byte
+}
+
+// ifreqFlags is struct ifreq with the ifr_flags member of the union.
+type ifreqFlags struct {
+	name  [syscall.IFNAMSIZ]byte
+	flags uint16
+	_     [22]byte
+}
+
+// ioctlRequester issues requests on an AF_INET datagram socket.
+type ioctlRequester struct{}
+
+func (r *ioctlRequester) Ethtool(iface string, data []byte) error {
+	var ifr ifreqData
+	if err := setName(ifr.name[:], iface); err != nil {
+		return err
+	}
+	ifr.data = unsafe.Pointer(&data[0])
+	err := ioctl(siocEthtool, unsafe.Pointer(&ifr))
+	runtime.KeepAlive(data)
+	return err
+}
+
+func (r *ioctlRequester) Flags(iface string) (uint16, error) {
+	var ifr ifreqFlags
+	if err := setName(ifr.name[:], iface); err != nil {
+		return 0, err
+	}
+	if err := ioctl(siocGIFFlags, unsafe.Pointer(&ifr)); err != nil {
+		return 0, err
+	}
+	return ifr.flags, nil
+}
+
+func (r *ioctlRequester) SetFlags(iface string, flags uint16) error {
+	var ifr ifreqFlags
+	if err := setName(ifr.name[:], iface); err != nil {
+		return err
+	}
+	ifr.flags = flags
+	return ioctl(siocSIFFlags, unsafe.Pointer(&ifr))
+}
+
+func setName(dst []byte, iface string) error {
+	if iface == "" || len(iface) >= len(dst) {
+		return fmt.Errorf("invalid interface name %q", iface)
+	}
+	copy(dst, iface)
+	return nil
+}
+
+func ioctl(req uintptr, arg unsafe.Pointer) error {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("open socket: %w", err)
+	}
+	defer syscall.Close(fd)
+
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), req, uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
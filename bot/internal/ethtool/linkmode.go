@@ -0,0 +1,64 @@
+package ethtool
+
+// LinkMode describes one ethtool link mode bit.
+type LinkMode struct {
+	Name  string // name as printed by the ethtool binary
+	Bit   uint   // ETHTOOL_LINK_MODE_*_BIT in include/uapi/linux/ethtool.h
+	Speed int    // Mbps
+}
+
+// LinkModes lists the link modes pervigil knows how to advertise.
+var LinkModes = []LinkMode{
+	{"10baseT/Half", 0, 10},
+	{"10baseT/Full", 1, 10},
+	{"100baseT/Half", 2, 100},
+	{"100baseT/Full", 3, 100},
+	{"1000baseT/Half", 4, 1000},
+	{"1000baseT/Full", 5, 1000},
+	{"10000baseT/Full", 12, 10000},
+	{"2500baseX/Full", 15, 2500},
+	{"1000baseKX/Full", 17, 1000},
+	{"10000baseKX4/Full", 18, 10000},
+	{"10000baseKR/Full", 19, 10000},
+	{"1000baseX/Full", 41, 1000},
+	{"10000baseCR/Full", 42, 10000},
+	{"10000baseSR/Full", 43, 10000},
+	{"10000baseLR/Full", 44, 10000},
+	{"10000baseLRM/Full", 45, 10000},
+	{"10000baseER/Full", 46, 10000},
+	{"2500baseT/Full", 47, 2500},
+	{"5000baseT/Full", 48, 5000},
+}
+
+// LinkModeByName returns the link mode with the given ethtool name.
+func LinkModeByName(name string) (LinkMode, bool) {
+	for _, m := range LinkModes {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return LinkMode{}, false
+}
+
+// Bitmap is a link mode bitmap as used by ETHTOOL_GLINKSETTINGS.
+type Bitmap []uint32
+
+// Has reports whether bit is set.
+func (b Bitmap) Has(bit uint) bool {
+	word := int(bit / 32)
+	return word < len(b) && b[word]&(1<<(bit%32)) != 0
+}
+
+// Speeds returns the distinct speeds (Mbps) of known link modes set in b,
+// in LinkModes order.
+func (b Bitmap) Speeds() []int {
+	seen := make(map[int]bool)
+	var speeds []int
+	for _, m := range LinkModes {
+		if b.Has(m.Bit) && !seen[m.Speed] {
+			seen[m.Speed] = true
+			speeds = append(speeds, m.Speed)
+		}
+	}
+	return speeds
+}
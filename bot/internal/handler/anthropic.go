@@ -4,8 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -17,10 +15,6 @@ import (
 var (
 	claudeClientOnce sync.Once
 	claudeClientInst *anthropic.Client
-
-	thresholdOnce           sync.Once
-	cachedWarnThreshold     float64
-	cachedCriticalThreshold float64
 )
 
 func getClaudeClient(apiKey string) *anthropic.Client {
@@ -31,7 +25,7 @@ func getClaudeClient(apiKey string) *anthropic.Client {
 }
 
 func cmdClaude(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	apiKey := os.Getenv("ANTHROPIC_ADMIN_KEY")
+	apiKey := conf().Cost.AdminKey
 	if apiKey == "" {
 		respond(s, i, "ANTHROPIC_ADMIN_KEY が未設定です")
 		return
@@ -140,21 +134,8 @@ func cmdClaude(s *discordgo.Session, i *discordgo.InteractionCreate) {
 }
 
 func costThresholds() (warn, crit float64) {
-	thresholdOnce.Do(func() {
-		cachedWarnThreshold = 5.0
-		if v := os.Getenv("DAILY_BUDGET_WARN"); v != "" {
-			if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 {
-				cachedWarnThreshold = f
-			}
-		}
-		cachedCriticalThreshold = 10.0
-		if v := os.Getenv("DAILY_BUDGET_CRIT"); v != "" {
-			if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 {
-				cachedCriticalThreshold = f
-			}
-		}
-	})
-	return cachedWarnThreshold, cachedCriticalThreshold
+	c := conf().Cost
+	return c.DailyWarning, c.DailyCritical
 }
 
 func sumCost(report *anthropic.CostReport) float64 {
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/murata-lab/pervigil/bot/internal/chart"
+	"github.com/murata-lab/pervigil/bot/internal/history"
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
+)
+
+// graphMetric is a metric kind selectable in /graph
+type graphMetric struct {
+	kind     string
+	name     string
+	format   string // value format for the summary
+	category string // temperature category for threshold lines
+}
+
+var graphMetrics = []graphMetric{
+	{history.MetricNICTemp, "NIC温度", "%.1f°C", temperature.CategoryNIC},
+	{history.MetricCPUTemp, "CPU温度", "%.1f°C", temperature.CategoryCPU},
+	{history.MetricBoardTemp, "ボード温度", "%.1f°C", temperature.CategoryBoard},
+	{history.MetricCPUUsage, "CPU使用率", "%.1f%%", ""},
+	{history.MetricLoad1, "ロードアベレージ", "%.2f", ""},
+	{history.MetricMemoryUsage, "メモリ使用率", "%.1f%%", temperature.CategoryMemory},
+	{history.MetricDiskUsage, "ディスク使用率", "%.1f%%", temperature.CategoryDisk},
+	{history.MetricRxBytes, "受信量", "%.0f B/s", ""},
+	{history.MetricTxBytes, "送信量", "%.0f B/s", ""},
+	{history.MetricRxErrors, "受信エラー", "%.2f/s", ""},
+	{history.MetricTxErrors, "送信エラー", "%.2f/s", ""},
+	{history.MetricDailyCost, "Claude API日次コスト", "$%.2f", ""},
+}
+
+// graphRanges are the selectable chart spans
+var graphRanges = []struct {
+	name string
+	span time.Duration
+}{
+	{"1h", time.Hour},
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+}
+
+// graphOptions returns the metric and range options of /graph.
+func graphOptions() []*discordgo.ApplicationCommandOption {
+	metrics := make([]*discordgo.ApplicationCommandOptionChoice, len(graphMetrics))
+	for i, m := range graphMetrics {
+		metrics[i] = &discordgo.ApplicationCommandOptionChoice{Name: m.name, Value: m.kind}
+	}
+	ranges := make([]*discordgo.ApplicationCommandOptionChoice, len(graphRanges))
+	for i, r := range graphRanges {
+		ranges[i] = &discordgo.ApplicationCommandOptionChoice{Name: r.name, Value: r.name}
+	}
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "metric",
+			Description: "表示するメトリクス",
+			Required:    true,
+			Choices:     metrics,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "range",
+			Description: "表示期間 (デフォルト: 24h)",
+			Choices:     ranges,
+		},
+	}
+}
+
+func cmdGraph(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if err := deferredRespond(s, i); err != nil {
+		return
+	}
+
+	metric, rangeName := graphMetrics[0], "24h"
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "metric":
+			for _, m := range graphMetrics {
+				if m.kind == opt.StringValue() {
+					metric = m
+				}
+			}
+		case "range":
+			rangeName = opt.StringValue()
+		}
+	}
+	span := 24 * time.Hour
+	for _, r := range graphRanges {
+		if r.name == rangeName {
+			span = r.span
+		}
+	}
+
+	content, png, err := renderGraph(historyDir(), metric, rangeName, span, time.Now())
+	if err != nil {
+		followup(s, i, fmt.Sprintf("**%s** (%s)\n```\n%s\n```", metric.name, rangeName, err))
+		return
+	}
+	followupFile(s, i, content, &discordgo.File{
+		Name:        "graph.png",
+		ContentType: "image/png",
+		Reader:      bytes.NewReader(png),
+	})
+}
+
+// historyDir returns the history directory written by the monitor.
+func historyDir() string {
+	return conf().History.Dir
+}
+
+// renderGraph charts every recorded series of a metric kind and returns
+// the message summary and PNG. Errors are user-facing.
+func renderGraph(dir string, metric graphMetric, rangeName string, span time.Duration, now time.Time) (string, []byte, error) {
+	if dir == "off" {
+		return "", nil, errors.New("履歴の記録が無効です (HISTORY_DIR=off)")
+	}
+	store, err := history.Open(dir, history.ReadOnly())
+	if err != nil {
+		return "", nil, fmt.Errorf("履歴を開けません: %v", err)
+	}
+	defer store.Close()
+
+	names, err := store.Metrics()
+	if err != nil {
+		return "", nil, fmt.Errorf("履歴を読めません: %v", err)
+	}
+
+	from := now.Add(-span)
+	c := chart.Chart{
+		Title: fmt.Sprintf("%s (%s)", metric.kind, rangeName),
+		From:  from,
+		To:    now,
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("**%s** (%s)\n```\n", metric.name, rangeName))
+	for _, name := range names {
+		kind, label := history.SplitName(name)
+		if kind != metric.kind {
+			continue
+		}
+		series, err := store.Query(name, from, now)
+		if err != nil || len(series.Points) == 0 {
+			continue
+		}
+		c.Step = series.Step
+		c.Series = append(c.Series, chart.Series{Label: label, Points: series.Points})
+		writeGraphSummary(&sb, metric, label, series.Points)
+	}
+	if len(c.Series) == 0 {
+		return "", nil, errors.New("この期間の履歴データがありません")
+	}
+	sb.WriteString("```")
+
+	if metric.category != "" {
+		var keys []string
+		if len(c.Series) == 1 && c.Series[0].Label != "" {
+			keys = append(keys, c.Series[0].Label)
+		}
+		c.Lines = chart.ThresholdLines(temperature.ThresholdsFor(metric.category, keys...))
+	}
+
+	png, err := chart.PNG(c)
+	if err != nil {
+		return "", nil, fmt.Errorf("グラフを描画できません: %v", err)
+	}
+	return sb.String(), png, nil
+}
+
+// writeGraphSummary writes the latest, minimum and maximum of a series.
+func writeGraphSummary(sb *strings.Builder, metric graphMetric, label string, points []history.Point) {
+	lo, hi := points[0].Min, points[0].Max
+	for _, p := range points {
+		lo, hi = min(lo, p.Min), max(hi, p.Max)
+	}
+	latest := points[len(points)-1].Avg
+	if label == "" {
+		label = metric.kind
+	}
+	sb.WriteString(fmt.Sprintf("%-12s: 最新 %s / 最小 %s / 最大 %s\n", label,
+		fmt.Sprintf(metric.format, latest), fmt.Sprintf(metric.format, lo), fmt.Sprintf(metric.format, hi)))
+}
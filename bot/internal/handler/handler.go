@@ -4,6 +4,7 @@ import (
 	"log"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
 )
 
 // Command represents a Discord slash command with its handler.
@@ -11,6 +12,7 @@ type Command struct {
 	Name        string
 	Description string
 	Execute     func(*discordgo.Session, *discordgo.InteractionCreate)
+	Options     []*discordgo.ApplicationCommandOption
 }
 
 var commands []Command
@@ -18,18 +20,23 @@ var commands []Command
 func init() {
 	commands = []Command{
 		// temperature.go
-		{"nic", "NIC温度を表示", cmdNIC},
-		{"temp", "全温度情報を表示 (CPU + NIC)", cmdTemp},
+		{"nic", "NIC温度を表示", cmdNIC, nil},
+		{"temp", "全温度情報を表示 (CPU + NIC)", cmdTemp, nil},
+		{"sensors", "ファン・電圧・電力・電流センサーを表示", cmdSensors, nil},
 		// system.go
-		{"status", "システム状態サマリー", cmdStatus},
-		{"cpu", "CPU使用率とロードアベレージを表示", cmdCPU},
-		{"memory", "メモリ使用状況を表示", cmdMemory},
-		{"disk", "ディスク使用状況を表示", cmdDisk},
-		{"info", "ルーター全情報を表示", cmdInfo},
+		{"status", "システム状態サマリー", cmdStatus, nil},
+		{"cpu", "CPU使用率とロードアベレージを表示", cmdCPU, nil},
+		{"memory", "メモリ使用状況を表示", cmdMemory, nil},
+		{"disk", "ディスク使用状況を表示", cmdDisk, nil},
+		{"info", "ルーター全情報を表示", cmdInfo, nil},
 		// network.go
-		{"network", "全NIC情報を表示", cmdNetwork},
+		{"network", "全NIC情報を表示", cmdNetwork, nil},
+		// sfp.go
+		{"sfp", "光モジュール診断情報 (DOM) を表示", cmdSFP, nil},
 		// anthropic.go
-		{"claude", "Claude API利用状況を表示", cmdClaude},
+		{"claude", "Claude API利用状況を表示", cmdClaude, nil},
+		// graph.go
+		{"graph", "メトリクスの履歴グラフを表示", cmdGraph, graphOptions()},
 	}
 }
 
@@ -40,6 +47,7 @@ func Commands() []*discordgo.ApplicationCommand {
 		result[i] = &discordgo.ApplicationCommand{
 			Name:        cmd.Name,
 			Description: cmd.Description,
+			Options:     cmd.Options,
 		}
 	}
 	return result
@@ -84,6 +92,17 @@ func followup(s *discordgo.Session, i *discordgo.InteractionCreate, content stri
 	}
 }
 
+// followupFile sends a followup message with a file attached.
+func followupFile(s *discordgo.Session, i *discordgo.InteractionCreate, content string, file *discordgo.File) {
+	_, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: content,
+		Files:   []*discordgo.File{file},
+	})
+	if err != nil {
+		log.Printf("[handler] followup error: %v", err)
+	}
+}
+
 // statusIndicator returns an emoji based on value thresholds.
 func statusIndicator(val, warn, crit float64) string {
 	switch {
@@ -95,3 +114,8 @@ func statusIndicator(val, warn, crit float64) string {
 		return "🟢"
 	}
 }
+
+// thresholdIndicator returns an emoji based on calibrated thresholds.
+func thresholdIndicator(val float64, t temperature.Thresholds) string {
+	return statusIndicator(val, t.Warning, t.Critical)
+}
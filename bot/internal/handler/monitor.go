@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/murata-lab/pervigil/bot/internal/statusapi"
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
+)
+
+// monitorTimeout bounds how long a command waits for pervigil-monitor
+const monitorTimeout = 2 * time.Second
+
+// staleReading is the age after which a monitor reading is shown with its time
+const staleReading = 5 * time.Minute
+
+// monitorStatus asks pervigil-monitor for its state over the status API.
+// It returns nil, nil when the API is disabled (status_api: off); on error
+// callers fall back to reading the sensors themselves.
+func monitorStatus() (*statusapi.Status, error) {
+	addr := conf().Monitor.StatusAPI
+	if addr == "off" {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), monitorTimeout)
+	defer cancel()
+	st, err := statusapi.NewClient(addr).Status(ctx)
+	if err != nil {
+		log.Printf("[handler] status API error: %v", err)
+		return nil, err
+	}
+	return st, nil
+}
+
+// nicStateLabel returns the display name of a monitor state
+func nicStateLabel(state string) string {
+	switch state {
+	case "warning":
+		return "警告"
+	case "critical":
+		return "危険"
+	default:
+		return "正常"
+	}
+}
+
+// monitorNICReading formats the last temperature the monitor read from nic
+func monitorNICReading(nic statusapi.InterfaceStatus) string {
+	if nic.ReadAt.IsZero() {
+		return "N/A"
+	}
+	t := temperature.Thresholds{Warning: nic.Warning, Critical: nic.Critical}
+	s := fmt.Sprintf("%5.1f°C %s", nic.Temperature, thresholdIndicator(nic.Temperature, t))
+	if time.Since(nic.ReadAt) > staleReading {
+		s += fmt.Sprintf(" (%s時点)", nic.ReadAt.Local().Format("15:04"))
+	}
+	return s
+}
+
+// monitorNICState formats the state machine and speed limit of nic
+func monitorNICState(nic statusapi.InterfaceStatus) string {
+	s := nicStateLabel(nic.State)
+	if nic.SpeedLimited {
+		s += fmt.Sprintf(" 速度制限中(Lv%d)", nic.ThrottleLevel)
+	}
+	return s
+}
+
+// writeMonitorSummary writes whether the monitor is running, its failing
+// checks and the latest alert, after a blank line
+func writeMonitorSummary(sb *strings.Builder, st *statusapi.Status, err error) {
+	switch {
+	case err != nil:
+		sb.WriteString("\n監視: 応答なし (センサーを直接読み取り)\n")
+		return
+	case st == nil:
+		return
+	}
+
+	mode := ""
+	if st.DryRun {
+		mode = ", ドライラン"
+	}
+	sb.WriteString(fmt.Sprintf("\n監視: 稼働中 (%s起動%s)\n", st.StartedAt.Local().Format("01/02 15:04"), mode))
+	for _, e := range st.Errors {
+		if e.Failing {
+			sb.WriteString(fmt.Sprintf("  エラー(%s): %s\n", e.Check, e.Message))
+		}
+	}
+	if len(st.Alerts) > 0 {
+		a := st.Alerts[0]
+		sb.WriteString(fmt.Sprintf("最新の通知: %s %s\n", a.Time.Local().Format("01/02 15:04"), a.Title))
+	}
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -13,7 +14,7 @@ func cmdNetwork(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		return
 	}
 
-	nics := sysinfo.GetAllNICs()
+	nics := sysinfo.GetAllNICs(context.Background())
 
 	if len(nics) == 0 {
 		followup(s, i, "NIC情報なし")
@@ -34,7 +35,7 @@ func cmdNetwork(s *discordgo.Session, i *discordgo.InteractionCreate) {
 			sb.WriteString(fmt.Sprintf("  速度: %s\n", nic.Speed))
 		}
 		if nic.Temp > 0 {
-			tStatus := statusIndicator(nic.Temp, 70, 85)
+			tStatus := thresholdIndicator(nic.Temp, nic.TempThresholds)
 			sb.WriteString(fmt.Sprintf("  温度: %.1f°C %s\n", nic.Temp, tStatus))
 		}
 		sb.WriteString(fmt.Sprintf("  RX: %s (%d pkts, %d err)\n",
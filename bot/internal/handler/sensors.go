@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
+)
+
+func cmdSensors(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if err := deferredRespond(s, i); err != nil {
+		return
+	}
+
+	readings, err := temperature.GetHwmonSensors()
+	if err != nil {
+		followup(s, i, fmt.Sprintf("センサー情報取得エラー: %v", err))
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("**ハードウェアセンサー**\n```\n")
+
+	chip := ""
+	shown := 0
+	for _, r := range readings {
+		// Temperatures are covered by /temp
+		if r.Kind == temperature.KindTemperature {
+			continue
+		}
+		if r.Chip != chip {
+			if chip != "" {
+				sb.WriteString("\n")
+			}
+			chip = r.Chip
+			sb.WriteString(chip + ":\n")
+		}
+		sb.WriteString(fmt.Sprintf("  %-12s: %12s %s%s\n", r.Name(), r.FormatValue(), sensorIndicator(r), sensorLimits(r)))
+		shown++
+	}
+
+	if shown == 0 {
+		sb.WriteString("N/A (センサー未対応)\n")
+	}
+
+	sb.WriteString("```")
+	followup(s, i, sb.String())
+}
+
+// sensorIndicator returns an emoji for a hwmon reading.
+func sensorIndicator(r temperature.SensorReading) string {
+	switch {
+	case r.Stalled(), r.Alarm:
+		return "🔴"
+	case r.OutOfRange():
+		return "🟡"
+	default:
+		return "🟢"
+	}
+}
+
+// sensorLimits formats the min/max/crit limits of a reading.
+func sensorLimits(r temperature.SensorReading) string {
+	var parts []string
+	for _, l := range []struct {
+		name string
+		val  *float64
+	}{{"min", r.Min}, {"max", r.Max}, {"crit", r.Crit}} {
+		if l.val != nil && *l.val != 0 {
+			parts = append(parts, fmt.Sprintf("%s %g", l.name, *l.val))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(parts, ", ") + "]"
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/murata-lab/pervigil/bot/internal/config"
+)
+
+// settings is the configuration read by the command handlers
+var settings atomic.Pointer[config.File]
+
+// Configure sets the configuration used by the command handlers.
+func Configure(f *config.File) {
+	settings.Store(f)
+}
+
+// conf returns the configuration set by Configure, or the defaults
+func conf() *config.File {
+	if f := settings.Load(); f != nil {
+		return f
+	}
+	return config.Default()
+}
+
+// nicInterfaces returns the monitored NICs as a comma-separated list
+func nicInterfaces() string {
+	return strings.Join(conf().NIC.Interfaces, ",")
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/murata-lab/pervigil/bot/internal/sfp"
+	"github.com/murata-lab/pervigil/bot/internal/sysinfo"
+)
+
+func cmdSFP(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if err := deferredRespond(s, i); err != nil {
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("**光モジュール (DOM)**\n```\n")
+
+	found := false
+	for _, iface := range sfpInterfaces() {
+		diag, err := sfp.Get(context.Background(), iface)
+		if errors.Is(err, sfp.ErrNoDiagnostics) {
+			continue
+		}
+		if err != nil {
+			sb.WriteString(fmt.Sprintf("%s: 取得失敗 (%v)\n\n", iface, err))
+			continue
+		}
+		found = true
+
+		sb.WriteString(fmt.Sprintf("%s %s", domIndicator(diag.Level()), iface))
+		if module := diag.Module(); module != "" {
+			sb.WriteString(fmt.Sprintf(" (%s)", module))
+		}
+		sb.WriteString("\n")
+		for _, r := range diag.Readings {
+			sb.WriteString(fmt.Sprintf("  %-12s: %12s %s", r.Label(), sfp.FormatValue(r), domIndicator(r.Level())))
+			if r.Thresholds.Known {
+				sb.WriteString(fmt.Sprintf("  [警告 %g〜%g]", r.Thresholds.LowWarning, r.Thresholds.HighWarning))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	if !found {
+		sb.WriteString("N/A (光モジュールなし / DOM未対応)\n")
+	}
+
+	sb.WriteString("```")
+	followup(s, i, sb.String())
+}
+
+// sfpInterfaces returns the configured SFP interfaces or the monitored NICs.
+func sfpInterfaces() []string {
+	if ifaces := conf().SFP.Interfaces; len(ifaces) > 0 {
+		return ifaces
+	}
+	return sysinfo.GetMonitoredNICs()
+}
+
+// domIndicator returns an emoji for a DOM level.
+func domIndicator(l sfp.Level) string {
+	switch l {
+	case sfp.LevelAlarm:
+		return "🔴"
+	case sfp.LevelWarning:
+		return "🟡"
+	default:
+		return "🟢"
+	}
+}
@@ -1,12 +1,14 @@
 package handler
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"runtime"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/murata-lab/pervigil/bot/internal/statusapi"
 	"github.com/murata-lab/pervigil/bot/internal/sysinfo"
 	"github.com/murata-lab/pervigil/bot/internal/temperature"
 )
@@ -28,8 +30,8 @@ func cmdStatus(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 	hostname, _ := os.Hostname()
 	uptime := sysinfo.GetUptime()
-	ifaces := os.Getenv("NIC_INTERFACE")
-	cpu, nics, _ := temperature.GetAllTemps(ifaces)
+	cpu, nics, _, _ := temperature.GetAllTemps(context.Background(), nicInterfaces())
+	st, monErr := monitorStatus()
 
 	var sb strings.Builder
 	sb.WriteString("**システム状態**\n```\n")
@@ -42,10 +44,18 @@ func cmdStatus(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		sb.WriteString(fmt.Sprintf("CPU最高温度: %.1f°C\n", maxCPUTemp(cpu)))
 	}
 
-	for _, nic := range nics {
-		status := statusIndicator(nic.Value, 70, 85)
-		sb.WriteString(fmt.Sprintf("NIC温度(%s): %.1f°C %s\n", nic.Label, nic.Value, status))
+	// The monitor's view includes its state machine; direct reads are the fallback
+	if st != nil && len(st.Interfaces) > 0 {
+		for _, nic := range st.Interfaces {
+			sb.WriteString(fmt.Sprintf("NIC温度(%s): %s %s\n", nic.Name, strings.TrimSpace(monitorNICReading(nic)), monitorNICState(nic)))
+		}
+	} else {
+		for _, nic := range nics {
+			status := thresholdIndicator(nic.Value, nic.Thresholds)
+			sb.WriteString(fmt.Sprintf("NIC温度(%s): %.1f°C %s\n", nic.Label, nic.Value, status))
+		}
 	}
+	writeMonitorSummary(&sb, st, monErr)
 
 	sb.WriteString("```")
 	followup(s, i, sb.String())
@@ -83,7 +93,7 @@ func cmdMemory(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		return
 	}
 
-	status := statusIndicator(info.UsagePercent, 70, 90)
+	status := thresholdIndicator(info.UsagePercent, temperature.ThresholdsFor(temperature.CategoryMemory))
 
 	var sb strings.Builder
 	sb.WriteString("**メモリ情報**\n```\n")
@@ -107,7 +117,7 @@ func cmdDisk(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		return
 	}
 
-	status := statusIndicator(info.UsagePercent, 70, 90)
+	status := thresholdIndicator(info.UsagePercent, temperature.ThresholdsFor(temperature.CategoryDisk))
 
 	var sb strings.Builder
 	sb.WriteString("**ディスク情報** (/)\n```\n")
@@ -125,7 +135,14 @@ func cmdInfo(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		return
 	}
 
-	info := sysinfo.GetAllRouterInfo()
+	info := sysinfo.GetAllRouterInfo(context.Background())
+	st, monErr := monitorStatus()
+	monitored := make(map[string]statusapi.InterfaceStatus)
+	if st != nil {
+		for _, nic := range st.Interfaces {
+			monitored[nic.Name] = nic
+		}
+	}
 
 	var sb strings.Builder
 	sb.WriteString("**ルーター情報**\n```\n")
@@ -145,7 +162,7 @@ func cmdInfo(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	sb.WriteString("\n")
 
 	if info.Memory != nil {
-		status := statusIndicator(info.Memory.UsagePercent, 70, 90)
+		status := thresholdIndicator(info.Memory.UsagePercent, temperature.ThresholdsFor(temperature.CategoryMemory))
 		sb.WriteString(fmt.Sprintf("メモリ: %s / %s (%.1f%%) %s\n",
 			sysinfo.FormatBytes(info.Memory.Used),
 			sysinfo.FormatBytes(info.Memory.Total),
@@ -153,7 +170,7 @@ func cmdInfo(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	}
 
 	if info.Disk != nil {
-		status := statusIndicator(info.Disk.UsagePercent, 70, 90)
+		status := thresholdIndicator(info.Disk.UsagePercent, temperature.ThresholdsFor(temperature.CategoryDisk))
 		sb.WriteString(fmt.Sprintf("ディスク: %s / %s (%.1f%%) %s\n",
 			sysinfo.FormatBytes(info.Disk.Used),
 			sysinfo.FormatBytes(info.Disk.Total),
@@ -169,7 +186,10 @@ func cmdInfo(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		}
 		tempStr := ""
 		if nic.Temp > 0 {
-			tempStr = fmt.Sprintf(" %.1f°C", nic.Temp)
+			tempStr = fmt.Sprintf(" %.1f°C %s", nic.Temp, thresholdIndicator(nic.Temp, nic.TempThresholds))
+		}
+		if m, ok := monitored[nic.Name]; ok {
+			tempStr += " " + monitorNICState(m)
 		}
 		sb.WriteString(fmt.Sprintf("  %s: %s%s\n", nic.Name, state, tempStr))
 	}
@@ -181,6 +201,12 @@ func cmdInfo(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		}
 	}
 
+	if len(info.ThermalZones) > 0 {
+		sb.WriteString("\nThermal:\n")
+		writeZones(&sb, info.ThermalZones)
+	}
+	writeMonitorSummary(&sb, st, monErr)
+
 	sb.WriteString("```")
 	followup(s, i, sb.String())
 }
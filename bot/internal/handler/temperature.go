@@ -1,8 +1,8 @@
 package handler
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
@@ -14,12 +14,22 @@ func cmdNIC(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		return
 	}
 
-	ifaces := os.Getenv("NIC_INTERFACE")
-	_, nics, board := temperature.GetAllTemps(ifaces)
-
 	var sb strings.Builder
 	sb.WriteString("**NIC温度**\n```\n")
 
+	// Prefer the monitor's readings, which carry its state and speed limit
+	st, monErr := monitorStatus()
+	if st != nil && len(st.Interfaces) > 0 {
+		for _, nic := range st.Interfaces {
+			sb.WriteString(fmt.Sprintf("%-10s: %s %s\n", nic.Name, monitorNICReading(nic), monitorNICState(nic)))
+		}
+		sb.WriteString("```")
+		followup(s, i, sb.String())
+		return
+	}
+
+	_, nics, board, zones := temperature.GetAllTemps(context.Background(), nicInterfaces())
+
 	if len(nics) == 0 {
 		sb.WriteString("N/A (センサー未対応)\n")
 		if len(board) > 0 {
@@ -28,12 +38,17 @@ func cmdNIC(s *discordgo.Session, i *discordgo.InteractionCreate) {
 				sb.WriteString(fmt.Sprintf("  %-16s: %5.1f°C\n", b.Label, b.Value))
 			}
 		}
+		if len(zones) > 0 {
+			sb.WriteString("\nThermal:\n")
+			writeZones(&sb, zones)
+		}
 	} else {
 		for _, nic := range nics {
-			status := statusIndicator(nic.Value, 70, 85)
+			status := thresholdIndicator(nic.Value, nic.Thresholds)
 			sb.WriteString(fmt.Sprintf("%-10s: %5.1f°C %s\n", nic.Label, nic.Value, status))
 		}
 	}
+	writeMonitorSummary(&sb, nil, monErr)
 
 	sb.WriteString("```")
 	followup(s, i, sb.String())
@@ -44,8 +59,7 @@ func cmdTemp(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		return
 	}
 
-	ifaces := os.Getenv("NIC_INTERFACE")
-	cpu, nics, board := temperature.GetAllTemps(ifaces)
+	cpu, nics, board, zones := temperature.GetAllTemps(context.Background(), nicInterfaces())
 
 	var sb strings.Builder
 	sb.WriteString("**温度情報**\n```\n")
@@ -62,7 +76,7 @@ func cmdTemp(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	sb.WriteString("\nNIC:\n")
 	if len(nics) > 0 {
 		for _, nic := range nics {
-			status := statusIndicator(nic.Value, 70, 85)
+			status := thresholdIndicator(nic.Value, nic.Thresholds)
 			sb.WriteString(fmt.Sprintf("  %-10s: %5.1f°C %s\n", nic.Label, nic.Value, status))
 		}
 	} else {
@@ -76,6 +90,23 @@ func cmdTemp(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		}
 	}
 
+	if len(zones) > 0 {
+		sb.WriteString("\nThermal:\n")
+		writeZones(&sb, zones)
+	}
+
 	sb.WriteString("```")
 	followup(s, i, sb.String())
 }
+
+// writeZones writes thermal zones with their trip point (or calibrated) thresholds.
+func writeZones(sb *strings.Builder, zones []temperature.ThermalZone) {
+	for _, z := range zones {
+		if !z.Thresholds.Known() {
+			sb.WriteString(fmt.Sprintf("  %-16s: %5.1f°C\n", z.Label, z.Value))
+			continue
+		}
+		status := thresholdIndicator(z.Value, z.Thresholds)
+		sb.WriteString(fmt.Sprintf("  %-16s: %5.1f°C %s (crit %.0f°C)\n", z.Label, z.Value, status, z.Thresholds.Critical))
+	}
+}
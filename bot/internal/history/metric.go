@@ -0,0 +1,34 @@
+package history
+
+import "strings"
+
+// Metric kinds recorded by the monitor. Per-sensor and per-interface
+// metrics are named kind:label (see Name).
+const (
+	MetricNICTemp     = "nic_temp"     // °C, per interface
+	MetricCPUTemp     = "cpu_temp"     // °C, per core/package
+	MetricBoardTemp   = "board_temp"   // °C, per chip
+	MetricCPUUsage    = "cpu_usage"    // %
+	MetricLoad1       = "load1"        // 1 min load average
+	MetricMemoryUsage = "memory_usage" // %
+	MetricDiskUsage   = "disk_usage"   // %
+	MetricRxBytes     = "rx_bytes"     // bytes/s, per interface
+	MetricTxBytes     = "tx_bytes"     // bytes/s, per interface
+	MetricRxErrors    = "rx_errors"    // errors/s, per interface
+	MetricTxErrors    = "tx_errors"    // errors/s, per interface
+	MetricDailyCost   = "daily_cost"   // USD
+)
+
+// Name returns the metric name of kind for label ("nic_temp:eth1").
+func Name(kind, label string) string {
+	if label == "" {
+		return kind
+	}
+	return kind + ":" + label
+}
+
+// SplitName splits a metric name into kind and label.
+func SplitName(metric string) (kind, label string) {
+	kind, label, _ = strings.Cut(metric, ":")
+	return kind, label
+}
@@ -0,0 +1,217 @@
+package history
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+)
+
+// Resolution is one downsampling tier of a metric file.
+type Resolution struct {
+	Name  string
+	Step  time.Duration
+	Slots int
+}
+
+// Retention returns how far back the tier reaches.
+func (r Resolution) Retention() time.Duration {
+	return r.Step * time.Duration(r.Slots)
+}
+
+// Resolutions are the tiers kept for every metric, finest first.
+// Each tier aggregates raw samples directly, so coarse tiers are exact.
+var Resolutions = []Resolution{
+	{Name: "1m", Step: time.Minute, Slots: 2 * 24 * 60}, // 2 days
+	{Name: "1h", Step: time.Hour, Slots: 60 * 24},       // 60 days
+	{Name: "1d", Step: 24 * time.Hour, Slots: 2 * 366},  // 2 years
+}
+
+// File layout: a fixed header followed by the slots of each tier in order.
+// Every file has the same size, so the store is bounded by its metric count.
+const (
+	fileMagic  = "PVGLTS01"
+	headerSize = 256
+	maxNameLen = headerSize - len(fileMagic) - 2 - 8*3
+	recordSize = 40 // start, count, min, max, sum
+)
+
+// record aggregates the samples of one slot
+type record struct {
+	start int64 // slot start (unix seconds); 0 when empty
+	count uint64
+	min   float64
+	max   float64
+	sum   float64
+}
+
+func (r *record) add(v float64) {
+	if r.count == 0 {
+		r.min, r.max = v, v
+	}
+	r.count++
+	r.min = math.Min(r.min, v)
+	r.max = math.Max(r.max, v)
+	r.sum += v
+}
+
+func (r *record) marshal(b []byte) {
+	binary.LittleEndian.PutUint64(b[0:], uint64(r.start))
+	binary.LittleEndian.PutUint64(b[8:], r.count)
+	binary.LittleEndian.PutUint64(b[16:], math.Float64bits(r.min))
+	binary.LittleEndian.PutUint64(b[24:], math.Float64bits(r.max))
+	binary.LittleEndian.PutUint64(b[32:], math.Float64bits(r.sum))
+}
+
+func (r *record) unmarshal(b []byte) {
+	r.start = int64(binary.LittleEndian.Uint64(b[0:]))
+	r.count = binary.LittleEndian.Uint64(b[8:])
+	r.min = math.Float64frombits(binary.LittleEndian.Uint64(b[16:]))
+	r.max = math.Float64frombits(binary.LittleEndian.Uint64(b[24:]))
+	r.sum = math.Float64frombits(binary.LittleEndian.Uint64(b[32:]))
+}
+
+// ringFile is the on-disk ring buffers of one metric
+type ringFile struct {
+	f    *os.File
+	name string
+}
+
+func ringFileSize() int64 {
+	size := int64(headerSize)
+	for _, res := range Resolutions {
+		size += int64(res.Slots) * recordSize
+	}
+	return size
+}
+
+// header encodes the metric name and tier layout
+func header(name string) []byte {
+	b := make([]byte, headerSize)
+	copy(b, fileMagic)
+	off := len(fileMagic)
+	for _, res := range Resolutions {
+		binary.LittleEndian.PutUint32(b[off:], uint32(res.Step/time.Second))
+		binary.LittleEndian.PutUint32(b[off+4:], uint32(res.Slots))
+		off += 8
+	}
+	binary.LittleEndian.PutUint16(b[off:], uint16(len(name)))
+	copy(b[off+2:], name)
+	return b
+}
+
+// openRing opens a metric file, creating it when missing. A file whose
+// layout differs from the current tiers is reset.
+func openRing(path, name string, readOnly bool) (*ringFile, error) {
+	flag := os.O_RDWR | os.O_CREATE
+	if readOnly {
+		flag = os.O_RDONLY
+	}
+	f, err := os.OpenFile(path, flag, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	want := header(name)
+	got := make([]byte, headerSize)
+	_, err = f.ReadAt(got, 0)
+	switch {
+	case err == nil && string(got) == string(want):
+		return &ringFile{f: f, name: name}, nil
+	case readOnly:
+		f.Close()
+		return nil, fmt.Errorf("%s: incompatible history file", path)
+	case err != nil && !errors.Is(err, io.EOF):
+		f.Close()
+		return nil, err
+	}
+
+	// New or incompatible file: start over with empty slots
+	if err := f.Truncate(0); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Truncate(ringFileSize()); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.WriteAt(want, 0); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &ringFile{f: f, name: name}, nil
+}
+
+// readName returns the metric name stored in a file header
+func readName(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	b := make([]byte, headerSize)
+	if _, err := f.ReadAt(b, 0); err != nil {
+		return "", err
+	}
+	if string(b[:len(fileMagic)]) != fileMagic {
+		return "", fmt.Errorf("%s: not a history file", path)
+	}
+	off := len(fileMagic) + 8*len(Resolutions)
+	n := int(binary.LittleEndian.Uint16(b[off:]))
+	if n > maxNameLen {
+		return "", fmt.Errorf("%s: corrupt header", path)
+	}
+	return string(b[off+2 : off+2+n]), nil
+}
+
+// offset returns the file position of the slot holding t in tier i
+func offset(i int, start int64) int64 {
+	off := int64(headerSize)
+	for _, res := range Resolutions[:i] {
+		off += int64(res.Slots) * recordSize
+	}
+	res := Resolutions[i]
+	slot := (start / int64(res.Step/time.Second)) % int64(res.Slots)
+	return off + slot*recordSize
+}
+
+func (rf *ringFile) read(i int, start int64) (record, error) {
+	var r record
+	b := make([]byte, recordSize)
+	if _, err := rf.f.ReadAt(b, offset(i, start)); err != nil {
+		return r, err
+	}
+	r.unmarshal(b)
+	if r.start != start {
+		// Empty, or an older period that has been lapped
+		return record{}, nil
+	}
+	return r, nil
+}
+
+// add merges a sample into the slot of every tier
+func (rf *ringFile) add(t time.Time, v float64) error {
+	b := make([]byte, recordSize)
+	for i, res := range Resolutions {
+		start := t.Truncate(res.Step).Unix()
+		r, err := rf.read(i, start)
+		if err != nil {
+			return err
+		}
+		r.start = start
+		r.add(v)
+		r.marshal(b)
+		if _, err := rf.f.WriteAt(b, offset(i, start)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rf *ringFile) close() error {
+	return rf.f.Close()
+}
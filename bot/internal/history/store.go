@@ -0,0 +1,262 @@
+// Package history is an embedded time-series store for collected metrics.
+// Each metric is a fixed-size file of ring buffers at 1m, 1h and 1d
+// resolution, so disk usage is bounded by the number of metrics.
+package history
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxMetrics bounds the number of metric files.
+const DefaultMaxMetrics = 256
+
+const fileExt = ".ring"
+
+var (
+	// ErrUnknownMetric is returned when a metric has never been recorded.
+	ErrUnknownMetric = errors.New("unknown metric")
+	// ErrTooManyMetrics is returned when recording a new metric would exceed the limit.
+	ErrTooManyMetrics = errors.New("too many metrics")
+)
+
+// Point is the aggregate of the samples within one step.
+type Point struct {
+	Time  time.Time // start of the step
+	Min   float64
+	Max   float64
+	Avg   float64
+	Count int
+}
+
+// Series is the result of a query.
+type Series struct {
+	Metric string
+	Step   time.Duration
+	Points []Point
+}
+
+// Store reads and writes metric files in a directory.
+type Store struct {
+	dir        string
+	readOnly   bool
+	maxMetrics int
+	nowFunc    func() time.Time
+
+	mu    sync.Mutex
+	files map[string]*ringFile
+}
+
+// Option configures Store
+type Option func(*Store)
+
+// ReadOnly opens the store for queries only (e.g. from the bot).
+func ReadOnly() Option {
+	return func(s *Store) {
+		s.readOnly = true
+	}
+}
+
+// WithMaxMetrics sets the maximum number of metrics.
+func WithMaxMetrics(n int) Option {
+	return func(s *Store) {
+		s.maxMetrics = n
+	}
+}
+
+// WithNowFunc sets the clock used to pick query resolutions (for testing).
+func WithNowFunc(f func() time.Time) Option {
+	return func(s *Store) {
+		s.nowFunc = f
+	}
+}
+
+// Open opens the store in dir, creating the directory unless read-only.
+func Open(dir string, opts ...Option) (*Store, error) {
+	s := &Store{
+		dir:        dir,
+		maxMetrics: DefaultMaxMetrics,
+		nowFunc:    time.Now,
+		files:      make(map[string]*ringFile),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if !s.readOnly {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Dir returns the store directory.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Record adds a sample to a metric.
+func (s *Store) Record(metric string, t time.Time, v float64) error {
+	if s.readOnly {
+		return errors.New("history store is read-only")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rf, err := s.open(metric, true)
+	if err != nil {
+		return err
+	}
+	if err := rf.add(t.UTC(), v); err != nil {
+		return fmt.Errorf("%s: %w", metric, err)
+	}
+	return nil
+}
+
+// Query returns the samples of a metric between from and to, at the finest
+// resolution that still reaches back to from.
+func (s *Store) Query(metric string, from, to time.Time) (Series, error) {
+	age := s.nowFunc().Sub(from)
+	res := len(Resolutions) - 1
+	for i, r := range Resolutions {
+		if r.Retention() >= age {
+			res = i
+			break
+		}
+	}
+	return s.QueryResolution(metric, Resolutions[res], from, to)
+}
+
+// QueryResolution returns the samples of a metric at the given resolution.
+func (s *Store) QueryResolution(metric string, res Resolution, from, to time.Time) (Series, error) {
+	tier := -1
+	for i, r := range Resolutions {
+		if r == res {
+			tier = i
+		}
+	}
+	if tier < 0 {
+		return Series{}, fmt.Errorf("unknown resolution %q", res.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rf, err := s.open(metric, false)
+	if err != nil {
+		return Series{}, err
+	}
+
+	series := Series{Metric: metric, Step: res.Step}
+	// Never walk more than one lap of the ring
+	first := from.UTC().Truncate(res.Step)
+	if oldest := to.UTC().Add(-res.Retention() + res.Step).Truncate(res.Step); first.Before(oldest) {
+		first = oldest
+	}
+	for t := first; !t.After(to); t = t.Add(res.Step) {
+		r, err := rf.read(tier, t.Unix())
+		if err != nil {
+			return Series{}, fmt.Errorf("%s: %w", metric, err)
+		}
+		if r.count == 0 {
+			continue
+		}
+		series.Points = append(series.Points, Point{
+			Time:  time.Unix(r.start, 0),
+			Min:   r.min,
+			Max:   r.max,
+			Avg:   r.sum / float64(r.count),
+			Count: int(r.count),
+		})
+	}
+	return series, nil
+}
+
+// Metrics returns the names of all recorded metrics.
+func (s *Store) Metrics() ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*"+fileExt))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, p := range paths {
+		name, err := readName(p)
+		if err != nil {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Close closes all open metric files.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for name, rf := range s.files {
+		errs = append(errs, rf.close())
+		delete(s.files, name)
+	}
+	return errors.Join(errs...)
+}
+
+// open returns the file of a metric, creating it when allowed.
+// Callers hold s.mu.
+func (s *Store) open(metric string, create bool) (*ringFile, error) {
+	if rf, ok := s.files[metric]; ok {
+		return rf, nil
+	}
+	if metric == "" || len(metric) > maxNameLen {
+		return nil, fmt.Errorf("invalid metric name %q", metric)
+	}
+
+	path := filepath.Join(s.dir, fileName(metric))
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if !create {
+			return nil, fmt.Errorf("%s: %w", metric, ErrUnknownMetric)
+		}
+		existing, err := filepath.Glob(filepath.Join(s.dir, "*"+fileExt))
+		if err != nil {
+			return nil, err
+		}
+		if len(existing) >= s.maxMetrics {
+			return nil, fmt.Errorf("%s: %w (limit %d)", metric, ErrTooManyMetrics, s.maxMetrics)
+		}
+	}
+
+	rf, err := openRing(path, metric, s.readOnly)
+	if err != nil {
+		return nil, err
+	}
+	s.files[metric] = rf
+	return rf, nil
+}
+
+// fileName maps a metric to a safe file name. Names that needed escaping
+// get a hash suffix so "cpu_temp:Core 0" and "cpu_temp:Core_0" stay apart.
+func fileName(metric string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, metric)
+	if safe != metric {
+		h := fnv.New32a()
+		h.Write([]byte(metric))
+		safe = fmt.Sprintf("%s-%08x", safe, h.Sum32())
+	}
+	return safe + fileExt
+}
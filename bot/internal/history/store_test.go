@@ -0,0 +1,182 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var base = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func openTestStore(t *testing.T, dir string, now time.Time, opts ...Option) *Store {
+	t.Helper()
+	opts = append(opts, WithNowFunc(func() time.Time { return now }))
+	s, err := Open(dir, opts...)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestStore_RecordAndQuery(t *testing.T) {
+	s := openTestStore(t, t.TempDir(), base.Add(3*time.Hour))
+	metric := Name(MetricNICTemp, "eth1")
+
+	// Two samples per minute for three hours: 60 + minute/10
+	for m := range 180 {
+		for _, sec := range []int{0, 30} {
+			v := 60 + float64(m)/10
+			if sec == 30 {
+				v += 1
+			}
+			if err := s.Record(metric, base.Add(time.Duration(m)*time.Minute+time.Duration(sec)*time.Second), v); err != nil {
+				t.Fatalf("Record() error = %v", err)
+			}
+		}
+	}
+
+	// Last hour → 1m resolution
+	series, err := s.Query(metric, base.Add(2*time.Hour), base.Add(3*time.Hour))
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if series.Step != time.Minute || len(series.Points) != 60 {
+		t.Fatalf("step = %v, points = %d; want 1m, 60", series.Step, len(series.Points))
+	}
+	p := series.Points[0]
+	if !p.Time.Equal(base.Add(2*time.Hour)) || p.Count != 2 || p.Min != 72 || p.Max != 73 || p.Avg != 72.5 {
+		t.Errorf("first point = %+v", p)
+	}
+
+	// Hourly aggregates are exact over the raw samples
+	series, err = s.QueryResolution(metric, Resolutions[1], base, base.Add(3*time.Hour))
+	if err != nil {
+		t.Fatalf("QueryResolution() error = %v", err)
+	}
+	if len(series.Points) != 3 {
+		t.Fatalf("hourly points = %d, want 3", len(series.Points))
+	}
+	if p := series.Points[1]; p.Count != 120 || p.Min != 66 || p.Max != 60+float64(119)/10+1 {
+		t.Errorf("second hour = %+v", p)
+	}
+}
+
+func TestStore_QueryPicksCoarserResolution(t *testing.T) {
+	now := base.Add(10 * 24 * time.Hour)
+	s := openTestStore(t, t.TempDir(), now)
+	for d := range 10 {
+		if err := s.Record(MetricDailyCost, base.Add(time.Duration(d)*24*time.Hour), float64(d)); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	series, err := s.Query(MetricDailyCost, now.Add(-7*24*time.Hour), now)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if series.Step != time.Hour || len(series.Points) != 7 {
+		t.Errorf("step = %v, points = %d; want 1h, 7", series.Step, len(series.Points))
+	}
+}
+
+func TestStore_RingWrapsOldSamples(t *testing.T) {
+	now := base.Add(3 * 24 * time.Hour)
+	s := openTestStore(t, t.TempDir(), now)
+	metric := MetricCPUUsage
+
+	// Same 1m slot index, one lap (2 days) apart
+	if err := s.Record(metric, base, 10); err != nil {
+		t.Fatal(err)
+	}
+	lap := Resolutions[0].Retention()
+	if err := s.Record(metric, base.Add(lap), 20); err != nil {
+		t.Fatal(err)
+	}
+
+	series, err := s.QueryResolution(metric, Resolutions[0], base, base.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series.Points) != 0 {
+		t.Errorf("lapped sample should be gone, got %+v", series.Points)
+	}
+	series, err = s.QueryResolution(metric, Resolutions[0], base.Add(lap), base.Add(lap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series.Points) != 1 || series.Points[0].Avg != 20 || series.Points[0].Count != 1 {
+		t.Errorf("new sample = %+v, want a fresh slot", series.Points)
+	}
+}
+
+func TestStore_PersistsAndBoundsSize(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metric := Name(MetricCPUTemp, "Core 0")
+	if err := s.Record(metric, base, 45); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, _ := filepath.Glob(filepath.Join(dir, "*"+fileExt))
+	if len(paths) != 1 {
+		t.Fatalf("files = %v", paths)
+	}
+	fi, err := os.Stat(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Size() != ringFileSize() {
+		t.Errorf("size = %d, want fixed %d", fi.Size(), ringFileSize())
+	}
+
+	ro := openTestStore(t, dir, base, ReadOnly())
+	names, err := ro.Metrics()
+	if err != nil || len(names) != 1 || names[0] != metric {
+		t.Errorf("Metrics() = %v, %v", names, err)
+	}
+	series, err := ro.Query(metric, base, base)
+	if err != nil || len(series.Points) != 1 || series.Points[0].Avg != 45 {
+		t.Errorf("Query() = %+v, %v", series, err)
+	}
+	if err := ro.Record(metric, base, 1); err == nil {
+		t.Error("read-only store should reject Record")
+	}
+}
+
+func TestStore_Errors(t *testing.T) {
+	s := openTestStore(t, t.TempDir(), base, WithMaxMetrics(2))
+
+	if _, err := s.Query("missing", base, base); !errors.Is(err, ErrUnknownMetric) {
+		t.Errorf("Query(missing) error = %v, want ErrUnknownMetric", err)
+	}
+	for _, m := range []string{"a", "b"} {
+		if err := s.Record(m, base, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Record("c", base, 1); !errors.Is(err, ErrTooManyMetrics) {
+		t.Errorf("Record(c) error = %v, want ErrTooManyMetrics", err)
+	}
+	// Existing metrics keep recording at the limit
+	if err := s.Record("a", base.Add(time.Minute), 2); err != nil {
+		t.Errorf("Record(a) error = %v", err)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	if got := fileName("nic_temp:eth1"); got == fileName("nic_temp_eth1") {
+		t.Errorf("escaped names must not collide: %q", got)
+	}
+	if got := fileName("load1"); got != "load1.ring" {
+		t.Errorf("fileName(load1) = %q", got)
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/murata-lab/pervigil/bot/internal/anthropic"
+	"github.com/murata-lab/pervigil/bot/internal/history"
 	"github.com/murata-lab/pervigil/bot/internal/notifier"
 )
 
@@ -101,8 +104,13 @@ type CostMonitor struct {
 	notifier   notifier.Notifier
 	stateStore CostStateStore
 	thresholds CostThresholds
+	history    historyWriter
 	hostname   string
 	nowFunc    func() time.Time
+
+	mu sync.Mutex // serializes Check and Reconfigure
+
+	dailyCost atomic.Pointer[float64] // last fetched, read by the metrics endpoint
 }
 
 // CostOption configures CostMonitor.
@@ -136,6 +144,13 @@ func WithCostThresholds(t CostThresholds) CostOption {
 	}
 }
 
+// WithCostHistory records the daily cost of every check.
+func WithCostHistory(h historyWriter) CostOption {
+	return func(m *CostMonitor) {
+		m.history = h
+	}
+}
+
 // WithCostNowFunc sets a custom time source (for testing).
 func WithCostNowFunc(f func() time.Time) CostOption {
 	return func(m *CostMonitor) {
@@ -157,8 +172,28 @@ func NewCostMonitor(opts ...CostOption) *CostMonitor {
 	return m
 }
 
+// Reconfigure replaces the settings with those of NewCostMonitor(opts...).
+// The alert state lives in the state store and the last fetched cost is
+// kept, so a reload never repeats a transition notification.
+func (m *CostMonitor) Reconfigure(opts ...CostOption) {
+	next := NewCostMonitor(opts...)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fetcher = next.fetcher
+	m.notifier = next.notifier
+	m.stateStore = next.stateStore
+	m.thresholds = next.thresholds
+	m.history = next.history
+	m.hostname = next.hostname
+	m.nowFunc = next.nowFunc
+}
+
 // Check fetches current cost and sends notifications on state transitions.
 func (m *CostMonitor) Check(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	now := m.nowFunc()
 	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
 	tomorrow := today.AddDate(0, 0, 1)
@@ -173,6 +208,14 @@ func (m *CostMonitor) Check(ctx context.Context) error {
 	for _, b := range report.Data {
 		dailyCost += b.CostUSD
 	}
+	m.dailyCost.Store(&dailyCost)
+
+	var historyErr error
+	if m.history != nil {
+		if err := m.history.Record(history.MetricDailyCost, now, dailyCost); err != nil {
+			historyErr = fmt.Errorf("record history: %w", err)
+		}
+	}
 
 	prev, err := m.stateStore.LoadCost()
 	if err != nil {
@@ -195,7 +238,15 @@ func (m *CostMonitor) Check(ctx context.Context) error {
 	if err := m.stateStore.SaveCost(CostStateData{State: newState, Date: todayStr}); err != nil {
 		return fmt.Errorf("save state: %w", err)
 	}
-	return nil
+	return historyErr
+}
+
+// DailyCost returns the cost of today as of the last successful fetch.
+func (m *CostMonitor) DailyCost() (float64, bool) {
+	if c := m.dailyCost.Load(); c != nil {
+		return *c, true
+	}
+	return 0, false
 }
 
 func (m *CostMonitor) determineState(cost float64) CostState {
@@ -216,3 +216,42 @@ func TestCostMonitor_CriticalToNormal(t *testing.T) {
 		t.Errorf("state = %s, want %s", ss.state.State, CostNormal)
 	}
 }
+
+func TestCostMonitor_Reconfigure(t *testing.T) {
+	n := &mockCostNotifier{}
+	ss := &mockCostStateStore{state: CostStateData{State: CostNormal, Date: fixedDate}}
+	opts := func(warn float64) []CostOption {
+		return []CostOption{
+			WithCostFetcher(&mockFetcher{cost: 6.0}),
+			WithCostNotifier(n),
+			WithCostStateStore(ss),
+			WithCostThresholds(CostThresholds{DailyWarning: warn, DailyCritical: 10.0}),
+			WithCostNowFunc(fixedNow),
+		}
+	}
+
+	m := NewCostMonitor(opts(5.0)...)
+	if err := m.Check(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The warning state is kept: an unchanged state is not announced again
+	m.Reconfigure(opts(4.0)...)
+	if err := m.Check(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(n.calls) != 1 {
+		t.Errorf("notifications = %v, want only the first warning", n.calls)
+	}
+	if cost, ok := m.DailyCost(); !ok || cost != 6.0 {
+		t.Errorf("DailyCost() = %v, %v", cost, ok)
+	}
+
+	m.Reconfigure(opts(7.0)...)
+	if err := m.Check(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(n.calls) != 2 || !strings.Contains(n.calls[1], "コスト正常化") {
+		t.Errorf("notifications = %v, want recovery after raising the threshold", n.calls)
+	}
+}
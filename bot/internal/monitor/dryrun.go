@@ -0,0 +1,133 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+)
+
+// dryRunTitlePrefix marks every notification sent while in dry-run mode
+const dryRunTitlePrefix = "[DRY RUN] "
+
+// WithDryRun enables observe-only mode: corrective actions are logged and
+// reported as "would have" notifications but never executed, while the
+// state machine and state file progress as usual. A speed limit actually
+// applied before dry-run was enabled is still lifted.
+func WithDryRun(enabled bool) NICOption {
+	return func(m *NICMonitor) {
+		m.dryRun = enabled
+	}
+}
+
+// DryRun reports whether corrective actions are suppressed
+func (m *NICMonitor) DryRun() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.dryRun
+}
+
+// dryRunNotifier prefixes notification titles so dry-run alerts are never
+// mistaken for real actions
+type dryRunNotifier struct {
+	next notifier.Notifier
+}
+
+func (n *dryRunNotifier) Send(title, message string, color notifier.Color, fields []notifier.Field) error {
+	return n.next.Send(dryRunTitlePrefix+title, message, color, fields)
+}
+
+func (n *dryRunNotifier) SendWithAttachments(title, message string, color notifier.Color, fields []notifier.Field, files []notifier.Attachment) error {
+	return notifier.SendWithAttachments(n.next, dryRunTitlePrefix+title, message, color, fields, files)
+}
+
+// dryRunReporter logs and announces a skipped corrective action; without
+// a notifier it only logs. Every corrective action wrapper in dry-run mode
+// reports through it.
+type dryRunReporter struct {
+	notifier notifier.Notifier
+	hostname string
+}
+
+func (r *dryRunReporter) skip(iface, action string) error {
+	log.Printf("dry-run: would have %s", action)
+	if r.notifier == nil {
+		return nil
+	}
+	if err := r.notifier.Send(
+		fmt.Sprintf("🧪 対処をスキップ - %s", r.hostname),
+		fmt.Sprintf("ドライランのため、NIC(%s)への対処は実行されませんでした。", iface),
+		notifier.ColorBlue,
+		[]notifier.Field{
+			{Name: "Interface", Value: iface, Inline: true},
+			{Name: "Action", Value: "Would have " + action, Inline: true},
+		},
+	); err != nil {
+		return fmt.Errorf("send notification: %w", err)
+	}
+	return nil
+}
+
+// dryRunSpeedController reports speed changes instead of applying them.
+// Read-only link mode detection is still delegated so the throttle ladder
+// matches what a real run would use, and so is Restore of a link that is
+// actually pinned: that limit was applied before dry-run was enabled, and
+// leaving it would defeat the restore on recovery and shutdown.
+type dryRunSpeedController struct {
+	next     SpeedController
+	reporter *dryRunReporter
+}
+
+func (c *dryRunSpeedController) Limit(_ context.Context, iface string, step ThrottleStep) error {
+	if step == StepAdminDown {
+		return c.reporter.skip(iface, "taken "+iface+" down")
+	}
+	return c.reporter.skip(iface, fmt.Sprintf("limited %s to %s", iface, step))
+}
+
+func (c *dryRunSpeedController) Restore(ctx context.Context, iface string) error {
+	if pinned, err := c.SpeedPinned(ctx, iface); err == nil && pinned {
+		log.Printf("dry-run: restoring %s, limited before dry-run was enabled", iface)
+		return c.next.Restore(ctx, iface)
+	}
+	return c.reporter.skip(iface, "restored "+iface+" to auto-negotiation")
+}
+
+func (c *dryRunSpeedController) SupportedSpeeds(ctx context.Context, iface string) ([]int, error) {
+	detector, ok := c.next.(speedDetector)
+	if !ok {
+		return nil, fmt.Errorf("link mode detection not available for %s", iface)
+	}
+	return detector.SupportedSpeeds(ctx, iface)
+}
+
+func (c *dryRunSpeedController) SpeedPinned(ctx context.Context, iface string) (bool, error) {
+	detector, ok := c.next.(pinDetector)
+	if !ok {
+		return false, fmt.Errorf("link mode detection not available for %s", iface)
+	}
+	return detector.SpeedPinned(ctx, iface)
+}
+
+// applyDryRun wraps the notifier and corrective actions when dry-run is
+// enabled. Speed changes are suppressed even without a notifier.
+func (m *NICMonitor) applyDryRun() {
+	if !m.dryRun {
+		return
+	}
+	if m.notifier != nil {
+		m.notifier = &dryRunNotifier{next: m.notifier}
+	}
+	reporter := &dryRunReporter{notifier: m.notifier, hostname: m.hostname}
+	m.speedCtrl = &dryRunSpeedController{next: m.speedCtrl, reporter: reporter}
+}
+
+// baseSpeedController returns the speed controller without the dry-run
+// wrapper
+func (m *NICMonitor) baseSpeedController() SpeedController {
+	if c, ok := m.speedCtrl.(*dryRunSpeedController); ok {
+		return c.next
+	}
+	return m.speedCtrl
+}
@@ -0,0 +1,182 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNICMonitor_DryRun_CriticalDoesNotLimit(t *testing.T) {
+	temp := &mockTempReader{temp: 90.0}
+	notif := &mockNotifier{}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateNormal})
+	speed := &mockSpeedController{}
+
+	m := newHoldTestMonitor(temp, notif, store, speed, WithDryRun(true))
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	if speed.limited {
+		t.Error("dry-run must not limit speed")
+	}
+	// FSM still progresses
+	got := store.states["eth1"]
+	if got.TempState != StateCritical || got.ThrottleLevel != 1 || !got.SpeedLimited {
+		t.Errorf("state = %+v, want critical at throttle level 1", got)
+	}
+
+	if len(notif.calls) != 2 {
+		t.Fatalf("expected alert + dry-run notification, got %d", len(notif.calls))
+	}
+	for _, c := range notif.calls {
+		if !strings.HasPrefix(c.title, dryRunTitlePrefix) {
+			t.Errorf("title %q missing dry-run prefix", c.title)
+		}
+	}
+	action := notif.calls[1].fields[1].Value
+	if action != "Would have limited eth1 to 1Gbps" {
+		t.Errorf("action = %q", action)
+	}
+}
+
+func TestNICMonitor_DryRun_RecoveryDoesNotRestore(t *testing.T) {
+	temp := &mockTempReader{temp: 60.0}
+	notif := &mockNotifier{}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateCritical, SpeedLimited: true, ThrottleLevel: 1})
+	speed := &mockSpeedController{}
+
+	m := newHoldTestMonitor(temp, notif, store, speed, WithDryRun(true))
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	if speed.restored {
+		t.Error("dry-run must not restore speed")
+	}
+	if got := store.states["eth1"]; got.ThrottleLevel != 0 || got.SpeedLimited {
+		t.Errorf("state = %+v, want throttle cleared", got)
+	}
+	if len(notif.calls) != 2 || !strings.Contains(notif.calls[1].fields[1].Value, "restored eth1") {
+		t.Errorf("expected restore dry-run notification, got %+v", notif.calls)
+	}
+}
+
+func TestNICMonitor_DryRun_Disabled(t *testing.T) {
+	temp := &mockTempReader{temp: 90.0}
+	notif := &mockNotifier{}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateNormal})
+	speed := &mockSpeedController{}
+
+	m := newHoldTestMonitor(temp, notif, store, speed, WithDryRun(false))
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	if !speed.limited {
+		t.Error("speed should be limited when dry-run is disabled")
+	}
+	if len(notif.calls) != 1 || strings.HasPrefix(notif.calls[0].title, dryRunTitlePrefix) {
+		t.Errorf("unexpected notifications %+v", notif.calls)
+	}
+}
+
+func TestNICMonitor_DryRun_KeepsLinkModeDetection(t *testing.T) {
+	temp := &mockTempReader{temp: 90.0}
+	notif := &mockNotifier{}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateNormal})
+	speed := &detectingSpeedController{speeds: []int{1000, 2500, 5000, 10000}}
+
+	m := NewNICMonitor(
+		WithTempReader(temp),
+		WithNotifier(notif),
+		WithStateStore(store),
+		WithSpeedController(speed),
+		WithThrottlePolicy(ThrottlePolicy{Ladder: []ThrottleStep{5000, 2500, 1000}}),
+		WithDryRun(true),
+	)
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	if speed.limited {
+		t.Error("dry-run must not limit speed")
+	}
+	if action := notif.calls[1].fields[1].Value; action != "Would have limited eth1 to 5Gbps" {
+		t.Errorf("action = %q", action)
+	}
+}
+
+func TestNICMonitor_DryRun_RestoresLimitAppliedBefore(t *testing.T) {
+	// The link was pinned by a real run before dry-run was enabled
+	pinned := MonitorState{TempState: StateCritical, SpeedLimited: true, ThrottleLevel: 1}
+	newMonitor := func(store *mockStateStore, speed SpeedController) *NICMonitor {
+		return NewNICMonitor(
+			WithTempReader(&mockTempReader{temp: 60.0}),
+			WithNotifier(&mockNotifier{}),
+			WithStateStore(store),
+			WithSpeedController(speed),
+			WithDryRun(true),
+		)
+	}
+
+	store := newMockStateStore("eth1", pinned)
+	speed := &detectingSpeedController{speeds: []int{1000, 10000}, pinned: true}
+	if err := newMonitor(store, speed).Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !speed.restored {
+		t.Error("recovery should restore a limit applied before dry-run")
+	}
+	if got := store.states["eth1"]; got.ThrottleLevel != 0 || got.SpeedLimited {
+		t.Errorf("state = %+v, want throttle cleared", got)
+	}
+
+	speed = &detectingSpeedController{speeds: []int{1000, 10000}, pinned: true}
+	if err := newMonitor(newMockStateStore("eth1", pinned), speed).RestoreAll(t.Context()); err != nil {
+		t.Fatalf("RestoreAll() error = %v", err)
+	}
+	if !speed.restored {
+		t.Error("RestoreAll should restore a limit applied before dry-run")
+	}
+}
+
+func TestNICMonitor_DryRun_WithoutNotifier(t *testing.T) {
+	speed := &mockSpeedController{}
+	m := NewNICMonitor(WithSpeedController(speed), WithDryRun(true))
+
+	if err := m.speedCtrl.Limit(t.Context(), "eth1", 1000); err != nil {
+		t.Fatalf("Limit() error = %v", err)
+	}
+	if speed.limited {
+		t.Error("dry-run must not limit speed without a notifier")
+	}
+}
+
+func TestNICMonitor_DryRun_SkipsReconcile(t *testing.T) {
+	// Simulated limit: recorded but never applied to the 10Gbps link
+	notif := &mockNotifier{}
+	want := MonitorState{TempState: StateCritical, SpeedLimited: true, ThrottleLevel: 1}
+	store := newMockStateStore("eth1", want)
+	speed := &mockSpeedController{}
+
+	m := NewNICMonitor(
+		WithNotifier(notif),
+		WithStateStore(store),
+		WithSpeedController(speed),
+		WithLinkReader(&mockLinkReader{speeds: map[string]int{"eth1": 10000}}),
+		WithDryRun(true),
+	)
+	if err := m.Reconcile(t.Context()); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if got := store.states["eth1"]; got.TempState != want.TempState || got.ThrottleLevel != want.ThrottleLevel || !got.SpeedLimited {
+		t.Errorf("state = %+v, want simulated limit kept", got)
+	}
+	if len(notif.calls) != 0 {
+		t.Errorf("expected no repair notification, got %+v", notif.calls)
+	}
+	if speed.restored {
+		t.Error("speed should not be touched")
+	}
+}
@@ -2,6 +2,8 @@ package monitor
 
 import (
 	"fmt"
+	"maps"
+	"sync"
 	"time"
 )
 
@@ -11,12 +13,24 @@ type suppressEntry struct {
 	count      int
 }
 
+// SuppressStats counts the errors seen for one key since startup.
+type SuppressStats struct {
+	Errors      int // all errors, logged or not
+	Suppressed  int // errors that were not logged
+	LastError   string
+	LastErrorAt time.Time
+	Failing     bool // false once the check succeeded again
+}
+
 // ErrorSuppressor suppresses repeated identical errors, logging them
 // only once per interval with a count of suppressed occurrences.
 type ErrorSuppressor struct {
 	interval time.Duration
 	nowFunc  func() time.Time
 	entries  map[string]*suppressEntry
+
+	mu    sync.Mutex // guards stats, which are read by the metrics and status endpoints
+	stats map[string]SuppressStats
 }
 
 // SuppressOption configures ErrorSuppressor.
@@ -42,6 +56,7 @@ func NewErrorSuppressor(opts ...SuppressOption) *ErrorSuppressor {
 		interval: time.Hour,
 		nowFunc:  time.Now,
 		entries:  make(map[string]*suppressEntry),
+		stats:    make(map[string]SuppressStats),
 	}
 	for _, opt := range opts {
 		opt(s)
@@ -55,6 +70,7 @@ func NewErrorSuppressor(opts ...SuppressOption) *ErrorSuppressor {
 func (s *ErrorSuppressor) Check(key string, err error) (string, bool) {
 	if err == nil {
 		delete(s.entries, key)
+		s.recover(key)
 		return "", false
 	}
 
@@ -68,6 +84,7 @@ func (s *ErrorSuppressor) Check(key string, err error) (string, bool) {
 			lastLogged: now,
 			count:      0,
 		}
+		s.count(key, msg, now, false)
 		return msg, true
 	}
 
@@ -80,9 +97,39 @@ func (s *ErrorSuppressor) Check(key string, err error) (string, bool) {
 		}
 		entry.lastLogged = now
 		entry.count = 0
+		s.count(key, msg, now, false)
 		return result, true
 	}
 
 	entry.count++
+	s.count(key, msg, now, true)
 	return "", false
 }
+
+// Stats returns the error counts per key.
+func (s *ErrorSuppressor) Stats() map[string]SuppressStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.stats)
+}
+
+func (s *ErrorSuppressor) count(key, msg string, now time.Time, suppressed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.stats[key]
+	st.Errors++
+	if suppressed {
+		st.Suppressed++
+	}
+	st.LastError, st.LastErrorAt, st.Failing = msg, now, true
+	s.stats[key] = st
+}
+
+func (s *ErrorSuppressor) recover(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.stats[key]; ok {
+		st.Failing = false
+		s.stats[key] = st
+	}
+}
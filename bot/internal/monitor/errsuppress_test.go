@@ -142,3 +142,27 @@ func TestErrorSuppressor_MultipleKeys(t *testing.T) {
 		t.Fatal("expected log repeated error to be suppressed")
 	}
 }
+
+func TestErrorSuppressor_Stats(t *testing.T) {
+	s := NewErrorSuppressor()
+	err := errors.New("no hwmon for eth2")
+
+	s.Check("nic", err) // logged
+	s.Check("nic", err) // suppressed
+	s.Check("sfp", err) // logged
+	s.Check("nic", nil) // reset does not clear the totals
+
+	stats := s.Stats()
+	if got := stats["nic"]; got.Errors != 2 || got.Suppressed != 1 {
+		t.Errorf("nic stats = %+v, want 2 errors, 1 suppressed", got)
+	}
+	if got := stats["sfp"]; got.Errors != 1 || got.Suppressed != 0 {
+		t.Errorf("sfp stats = %+v, want 1 error", got)
+	}
+	if got := stats["nic"]; got.Failing || got.LastError != err.Error() {
+		t.Errorf("nic stats = %+v, want recovered with last error kept", got)
+	}
+	if got := stats["sfp"]; !got.Failing {
+		t.Errorf("sfp stats = %+v, want failing", got)
+	}
+}
@@ -0,0 +1,402 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
+)
+
+// openMetricsContentType is the media type of the /metrics response
+const openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// nicStateReader returns the FSM state of the monitored interfaces
+type nicStateReader interface {
+	States() (map[string]MonitorState, error)
+}
+
+// logTotaler counts the log lines matched since startup
+type logTotaler interface {
+	Totals() (errorLines, warningLines int64)
+}
+
+// costReader returns the last fetched daily cost
+type costReader interface {
+	DailyCost() (float64, bool)
+}
+
+// suppressStatter returns check error counts
+type suppressStatter interface {
+	Stats() map[string]SuppressStats
+}
+
+// outboxStatter returns the notification outbox queues
+type outboxStatter interface {
+	Stats() map[string]notifier.OutboxStats
+}
+
+// MetricsExporter serves the monitor's readings in OpenMetrics text format.
+// Sensors are read on every scrape; state and counters come from the monitors.
+type MetricsExporter struct {
+	temps    historyTempReader
+	system   systemReader
+	nic      nicStateReader
+	logs     logTotaler
+	cost     costReader
+	suppress suppressStatter
+	outboxes outboxStatter
+	ifaces   []string
+	diskPath string
+}
+
+// ExporterOption configures MetricsExporter
+type ExporterOption func(*MetricsExporter)
+
+// WithExporterTempReader sets the temperature reader
+func WithExporterTempReader(t historyTempReader) ExporterOption {
+	return func(e *MetricsExporter) {
+		e.temps = t
+	}
+}
+
+// WithExporterSystemReader sets the system metrics reader
+func WithExporterSystemReader(s systemReader) ExporterOption {
+	return func(e *MetricsExporter) {
+		e.system = s
+	}
+}
+
+// WithExporterNICStates sets the source of NIC FSM states
+func WithExporterNICStates(n nicStateReader) ExporterOption {
+	return func(e *MetricsExporter) {
+		e.nic = n
+	}
+}
+
+// WithExporterLogTotals sets the source of log match counts
+func WithExporterLogTotals(l logTotaler) ExporterOption {
+	return func(e *MetricsExporter) {
+		e.logs = l
+	}
+}
+
+// WithExporterCost sets the source of the daily cost
+func WithExporterCost(c costReader) ExporterOption {
+	return func(e *MetricsExporter) {
+		e.cost = c
+	}
+}
+
+// WithExporterSuppressor sets the source of check error counts
+func WithExporterSuppressor(s suppressStatter) ExporterOption {
+	return func(e *MetricsExporter) {
+		e.suppress = s
+	}
+}
+
+// WithExporterOutboxes sets the source of notification queue stats
+func WithExporterOutboxes(o outboxStatter) ExporterOption {
+	return func(e *MetricsExporter) {
+		e.outboxes = o
+	}
+}
+
+// WithExporterInterface sets the interfaces to export (comma-separated)
+func WithExporterInterface(iface string) ExporterOption {
+	return func(e *MetricsExporter) {
+		e.ifaces = splitInterfaces(iface)
+	}
+}
+
+// NewMetricsExporter creates a new metrics exporter
+func NewMetricsExporter(opts ...ExporterOption) *MetricsExporter {
+	e := &MetricsExporter{
+		ifaces:   []string{"eth1"},
+		diskPath: "/",
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// ServeHTTP writes all metrics. Readings that fail are left out so one
+// broken sensor does not fail the whole scrape; sensor commands are killed
+// when the scraper gives up.
+func (e *MetricsExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	e.WriteMetrics(r.Context(), &buf)
+	w.Header().Set("Content-Type", openMetricsContentType)
+	w.Write(buf.Bytes())
+}
+
+// WriteMetrics writes all metrics in OpenMetrics text format
+func (e *MetricsExporter) WriteMetrics(ctx context.Context, buf *bytes.Buffer) {
+	mw := &metricsWriter{buf: buf}
+	if e.temps != nil {
+		e.writeTemps(ctx, mw)
+	}
+	if e.nic != nil {
+		e.writeNICStates(mw)
+	}
+	if e.system != nil {
+		e.writeSystem(ctx, mw)
+	}
+	if e.logs != nil {
+		errorLines, warningLines := e.logs.Totals()
+		mw.family("pervigil_log_error_lines", "counter", "", "Log lines matching an error pattern")
+		mw.sample("_total", nil, float64(errorLines))
+		mw.family("pervigil_log_warning_lines", "counter", "", "Log lines matching a warning pattern")
+		mw.sample("_total", nil, float64(warningLines))
+	}
+	if e.cost != nil {
+		if cost, ok := e.cost.DailyCost(); ok {
+			mw.family("pervigil_claude_daily_cost_usd", "gauge", "usd", "Claude API cost of the current UTC day")
+			mw.sample("", nil, cost)
+		}
+	}
+	if e.suppress != nil {
+		e.writeSuppressor(mw)
+	}
+	if e.outboxes != nil {
+		e.writeOutboxes(mw)
+	}
+	buf.WriteString("# EOF\n")
+}
+
+func (e *MetricsExporter) writeTemps(ctx context.Context, mw *metricsWriter) {
+	type nicTemp struct {
+		iface   string
+		reading *temperature.TempReading
+	}
+	var nics []nicTemp
+	for _, iface := range e.ifaces {
+		if reading, err := e.temps.GetNICTemp(ctx, iface); err == nil {
+			nics = append(nics, nicTemp{iface, reading})
+		}
+	}
+	if len(nics) > 0 {
+		mw.family("pervigil_nic_temperature_celsius", "gauge", "celsius", "NIC temperature")
+		for _, n := range nics {
+			mw.sample("", []string{"interface", n.iface}, n.reading.Value)
+		}
+		mw.family("pervigil_nic_temperature_threshold_celsius", "gauge", "celsius", "NIC temperature thresholds")
+		for _, n := range nics {
+			t := n.reading.Thresholds
+			for _, level := range []struct {
+				name  string
+				value float64
+			}{{"warning", t.Warning}, {"critical", t.Critical}, {"recovery", t.Recovery}} {
+				if level.value > 0 {
+					mw.sample("", []string{"interface", n.iface, "level", level.name}, level.value)
+				}
+			}
+		}
+	}
+
+	if cpu, err := e.temps.GetCPUTemps(ctx); err == nil && len(cpu) > 0 {
+		mw.family("pervigil_cpu_temperature_celsius", "gauge", "celsius", "CPU temperature")
+		for _, t := range cpu {
+			mw.sample("", []string{"sensor", t.Label}, t.Value)
+		}
+	}
+	if board, err := e.temps.GetBoardTemps(); err == nil && len(board) > 0 {
+		mw.family("pervigil_board_temperature_celsius", "gauge", "celsius", "Board (non-CPU hwmon) temperature")
+		for _, t := range board {
+			mw.sample("", []string{"sensor", t.Label}, t.Value)
+		}
+	}
+}
+
+func (e *MetricsExporter) writeNICStates(mw *metricsWriter) {
+	states, err := e.nic.States()
+	if err != nil || len(states) == 0 {
+		return
+	}
+	ifaces := make([]string, 0, len(states))
+	for iface := range states {
+		ifaces = append(ifaces, iface)
+	}
+	sort.Strings(ifaces)
+
+	mw.family("pervigil_nic_state", "stateset", "", "NIC temperature state machine")
+	for _, iface := range ifaces {
+		for _, s := range []NICState{StateNormal, StateWarning, StateCritical} {
+			mw.sample("", []string{"interface", iface, "pervigil_nic_state", string(s)}, boolValue(states[iface].TempState == s))
+		}
+	}
+	mw.family("pervigil_nic_speed_limited", "gauge", "", "Whether the NIC speed is limited by the monitor")
+	for _, iface := range ifaces {
+		mw.sample("", []string{"interface", iface}, boolValue(states[iface].SpeedLimited))
+	}
+	mw.family("pervigil_nic_throttle_level", "gauge", "", "Throttle ladder steps applied")
+	for _, iface := range ifaces {
+		mw.sample("", []string{"interface", iface}, float64(states[iface].ThrottleLevel))
+	}
+}
+
+func (e *MetricsExporter) writeSystem(ctx context.Context, mw *metricsWriter) {
+	if cpu, err := e.system.GetCPUInfo(); err == nil {
+		mw.family("pervigil_cpu_usage_percent", "gauge", "percent", "CPU usage")
+		mw.sample("", nil, cpu.Usage)
+		mw.family("pervigil_load_average", "gauge", "", "System load average")
+		for i, period := range []string{"1m", "5m", "15m"} {
+			mw.sample("", []string{"period", period}, cpu.LoadAvg[i])
+		}
+	}
+	if mem, err := e.system.GetMemoryInfo(); err == nil {
+		mw.family("pervigil_memory_bytes", "gauge", "bytes", "Memory size by type")
+		mw.sample("", []string{"type", "total"}, float64(mem.Total))
+		mw.sample("", []string{"type", "used"}, float64(mem.Used))
+		mw.sample("", []string{"type", "available"}, float64(mem.Available))
+		mw.family("pervigil_memory_usage_percent", "gauge", "percent", "Memory usage")
+		mw.sample("", nil, mem.UsagePercent)
+	}
+	if disk, err := e.system.GetDiskInfo(e.diskPath); err == nil {
+		mw.family("pervigil_disk_bytes", "gauge", "bytes", "Filesystem size by type")
+		mw.sample("", []string{"path", e.diskPath, "type", "total"}, float64(disk.Total))
+		mw.sample("", []string{"path", e.diskPath, "type", "used"}, float64(disk.Used))
+		mw.sample("", []string{"path", e.diskPath, "type", "available"}, float64(disk.Available))
+		mw.family("pervigil_disk_usage_percent", "gauge", "percent", "Filesystem usage")
+		mw.sample("", []string{"path", e.diskPath}, disk.UsagePercent)
+	}
+
+	var infos []ifaceCounters
+	for _, iface := range e.ifaces {
+		info, err := e.system.GetNICInfo(ctx, iface)
+		if err != nil {
+			continue
+		}
+		infos = append(infos, ifaceCounters{
+			iface: iface,
+			up:    info.State == "up",
+			rx:    [3]uint64{info.RxBytes, info.RxPackets, info.RxErrors},
+			tx:    [3]uint64{info.TxBytes, info.TxPackets, info.TxErrors},
+		})
+	}
+	if len(infos) == 0 {
+		return
+	}
+	mw.family("pervigil_interface_up", "gauge", "", "Whether the interface link is up")
+	for _, n := range infos {
+		mw.sample("", []string{"interface", n.iface}, boolValue(n.up))
+	}
+	for k, name := range []string{"bytes", "packets", "errors"} {
+		for _, dir := range []string{"receive", "transmit"} {
+			family := fmt.Sprintf("pervigil_interface_%s_%s", dir, name)
+			unit := ""
+			if name == "bytes" {
+				unit = "bytes"
+			}
+			mw.family(family, "counter", unit, fmt.Sprintf("Interface %s %s", dir, name))
+			for _, n := range infos {
+				v := n.rx[k]
+				if dir == "transmit" {
+					v = n.tx[k]
+				}
+				mw.sample("_total", []string{"interface", n.iface}, float64(v))
+			}
+		}
+	}
+}
+
+// ifaceCounters are the exported statistics of one interface
+type ifaceCounters struct {
+	iface  string
+	up     bool
+	rx, tx [3]uint64 // bytes, packets, errors
+}
+
+func (e *MetricsExporter) writeSuppressor(mw *metricsWriter) {
+	stats := e.suppress.Stats()
+	if len(stats) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(stats))
+	for k := range stats {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	mw.family("pervigil_check_errors", "counter", "", "Check errors, logged or suppressed")
+	for _, k := range keys {
+		mw.sample("_total", []string{"check", k}, float64(stats[k].Errors))
+	}
+	mw.family("pervigil_check_errors_suppressed", "counter", "", "Repeated check errors not logged")
+	for _, k := range keys {
+		mw.sample("_total", []string{"check", k}, float64(stats[k].Suppressed))
+	}
+}
+
+func (e *MetricsExporter) writeOutboxes(mw *metricsWriter) {
+	stats := e.outboxes.Stats()
+	if len(stats) == 0 {
+		return
+	}
+	backends := make([]string, 0, len(stats))
+	for b := range stats {
+		backends = append(backends, b)
+	}
+	sort.Strings(backends)
+
+	mw.family("pervigil_notify_queued", "gauge", "", "Notifications queued for retry")
+	for _, b := range backends {
+		mw.sample("", []string{"backend", b}, float64(stats[b].Queued))
+	}
+	mw.family("pervigil_notify_delivered_late", "counter", "", "Queued notifications delivered by a retry")
+	for _, b := range backends {
+		mw.sample("_total", []string{"backend", b}, float64(stats[b].Late))
+	}
+	mw.family("pervigil_notify_dropped", "counter", "", "Notifications dropped from a full queue or on a permanent failure")
+	for _, b := range backends {
+		mw.sample("_total", []string{"backend", b}, float64(stats[b].Dropped))
+	}
+}
+
+// metricsWriter formats OpenMetrics metric families
+type metricsWriter struct {
+	buf  *bytes.Buffer
+	name string // current family
+}
+
+// family starts a metric family. A unit, when given, must be the suffix of name.
+func (w *metricsWriter) family(name, typ, unit, help string) {
+	w.name = name
+	fmt.Fprintf(w.buf, "# TYPE %s %s\n", name, typ)
+	if unit != "" {
+		fmt.Fprintf(w.buf, "# UNIT %s %s\n", name, unit)
+	}
+	fmt.Fprintf(w.buf, "# HELP %s %s\n", name, help)
+}
+
+// sample writes one sample of the current family. labels are name/value pairs.
+func (w *metricsWriter) sample(suffix string, labels []string, v float64) {
+	w.buf.WriteString(w.name + suffix)
+	if len(labels) > 0 {
+		pairs := make([]string, 0, len(labels)/2)
+		for i := 0; i+1 < len(labels); i += 2 {
+			pairs = append(pairs, labels[i]+`="`+escapeLabel(labels[i+1])+`"`)
+		}
+		w.buf.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+	w.buf.WriteString(" " + strconv.FormatFloat(v, 'g', -1, 64) + "\n")
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
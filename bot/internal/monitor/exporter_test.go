@@ -0,0 +1,109 @@
+package monitor
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+	"github.com/murata-lab/pervigil/bot/internal/sysinfo"
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
+)
+
+type mockNICStates struct {
+	states map[string]MonitorState
+}
+
+func (m *mockNICStates) States() (map[string]MonitorState, error) {
+	return m.states, nil
+}
+
+type mockCostReader struct {
+	cost float64
+}
+
+func (m *mockCostReader) DailyCost() (float64, bool) {
+	return m.cost, true
+}
+
+type mockOutboxes map[string]notifier.OutboxStats
+
+func (m mockOutboxes) Stats() map[string]notifier.OutboxStats {
+	return m
+}
+
+func TestMetricsExporter_ServeHTTP(t *testing.T) {
+	temps := &mockHistoryTempReader{
+		mockPerIfaceTempReader: mockPerIfaceTempReader{
+			temps: map[string]float64{"eth1": 62},
+			errs:  map[string]error{"eth2": temperature.ErrSensorUnavailable},
+		},
+		cpu: []temperature.TempReading{{Label: `Core "0"`, Value: 45}},
+	}
+	sys := &mockSystemReader{nic: map[string]*sysinfo.NICInfo{
+		"eth1": {State: "up", RxBytes: 1000, TxErrors: 2},
+	}}
+	lg := NewLogMonitor(WithLogReader(&mockLogReader{lines: []string{"kernel: error on eth1"}}), WithLogNotifier(&mockNotifier{}))
+	if _, err := lg.Process(); err != nil {
+		t.Fatal(err)
+	}
+	suppress := NewErrorSuppressor()
+	suppress.Check("nic", errors.New("boom"))
+	suppress.Check("nic", errors.New("boom"))
+
+	e := NewMetricsExporter(
+		WithExporterTempReader(temps),
+		WithExporterSystemReader(sys),
+		WithExporterNICStates(&mockNICStates{states: map[string]MonitorState{
+			"eth1": {TempState: StateCritical, SpeedLimited: true, ThrottleLevel: 1},
+		}}),
+		WithExporterLogTotals(lg),
+		WithExporterCost(&mockCostReader{cost: 1.5}),
+		WithExporterSuppressor(suppress),
+		WithExporterOutboxes(mockOutboxes{"discord": {Queued: 3, Late: 2, Dropped: 1}}),
+		WithExporterInterface("eth1,eth2"),
+	)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/openmetrics-text") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		"# TYPE pervigil_nic_temperature_celsius gauge\n# UNIT pervigil_nic_temperature_celsius celsius\n",
+		`pervigil_nic_temperature_celsius{interface="eth1"} 62` + "\n",
+		`pervigil_nic_state{interface="eth1",pervigil_nic_state="critical"} 1` + "\n",
+		`pervigil_nic_state{interface="eth1",pervigil_nic_state="normal"} 0` + "\n",
+		`pervigil_nic_speed_limited{interface="eth1"} 1` + "\n",
+		`pervigil_cpu_temperature_celsius{sensor="Core \"0\""} 45` + "\n",
+		"pervigil_cpu_usage_percent 12.5\n",
+		`pervigil_load_average{period="1m"} 0.42` + "\n",
+		"pervigil_memory_usage_percent 40\n",
+		`pervigil_interface_up{interface="eth1"} 1` + "\n",
+		`pervigil_interface_receive_bytes_total{interface="eth1"} 1000` + "\n",
+		`pervigil_interface_transmit_errors_total{interface="eth1"} 2` + "\n",
+		"pervigil_log_error_lines_total 1\n",
+		"pervigil_claude_daily_cost_usd 1.5\n",
+		`pervigil_check_errors_total{check="nic"} 2` + "\n",
+		`pervigil_check_errors_suppressed_total{check="nic"} 1` + "\n",
+		`pervigil_notify_queued{backend="discord"} 3` + "\n",
+		`pervigil_notify_delivered_late_total{backend="discord"} 2` + "\n",
+		`pervigil_notify_dropped_total{backend="discord"} 1` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+	// Unavailable sensors and failed readers are left out
+	for _, unwanted := range []string{`interface="eth2"`, "pervigil_disk_usage_percent"} {
+		if strings.Contains(body, unwanted) {
+			t.Errorf("unexpected %q", unwanted)
+		}
+	}
+	if !strings.HasSuffix(body, "# EOF\n") {
+		t.Error("missing # EOF terminator")
+	}
+}
@@ -0,0 +1,216 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/murata-lab/pervigil/bot/internal/history"
+	"github.com/murata-lab/pervigil/bot/internal/sysinfo"
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
+)
+
+// historyWriter stores metric samples
+type historyWriter interface {
+	Record(metric string, t time.Time, v float64) error
+}
+
+// historyTempReader reads NIC, CPU and board temperatures
+type historyTempReader interface {
+	tempReader
+	GetCPUTemps(ctx context.Context) ([]temperature.TempReading, error)
+	GetBoardTemps() ([]temperature.TempReading, error)
+}
+
+// systemReader reads CPU, memory, disk and interface statistics
+type systemReader interface {
+	GetCPUInfo() (*sysinfo.CPUInfo, error)
+	GetMemoryInfo() (*sysinfo.MemInfo, error)
+	GetDiskInfo(path string) (*sysinfo.DiskInfo, error)
+	GetNICInfo(ctx context.Context, iface string) (*sysinfo.NICInfo, error)
+}
+
+// SysinfoAdapter wraps the sysinfo package
+type SysinfoAdapter struct{}
+
+// NewSysinfoAdapter creates a new sysinfo adapter
+func NewSysinfoAdapter() *SysinfoAdapter {
+	return &SysinfoAdapter{}
+}
+
+// GetCPUInfo returns CPU usage and load averages
+func (a *SysinfoAdapter) GetCPUInfo() (*sysinfo.CPUInfo, error) {
+	return sysinfo.GetCPUInfo()
+}
+
+// GetMemoryInfo returns memory usage
+func (a *SysinfoAdapter) GetMemoryInfo() (*sysinfo.MemInfo, error) {
+	return sysinfo.GetMemoryInfo()
+}
+
+// GetDiskInfo returns disk usage of path
+func (a *SysinfoAdapter) GetDiskInfo(path string) (*sysinfo.DiskInfo, error) {
+	return sysinfo.GetDiskInfo(path)
+}
+
+// GetNICInfo returns interface state and counters
+func (a *SysinfoAdapter) GetNICInfo(ctx context.Context, iface string) (*sysinfo.NICInfo, error) {
+	return sysinfo.GetNICInfo(ctx, iface)
+}
+
+// counterSample is the last reading of the interface counters
+type counterSample struct {
+	time   time.Time
+	values map[string]uint64
+}
+
+// HistoryRecorder samples temperatures and system metrics into the history store
+type HistoryRecorder struct {
+	store    historyWriter
+	temps    historyTempReader
+	system   systemReader
+	ifaces   []string
+	diskPath string
+	nowFunc  func() time.Time
+	counters map[string]counterSample // iface → last counters
+}
+
+// HistoryOption configures HistoryRecorder
+type HistoryOption func(*HistoryRecorder)
+
+// WithHistoryStore sets the store samples are written to
+func WithHistoryStore(s historyWriter) HistoryOption {
+	return func(r *HistoryRecorder) {
+		r.store = s
+	}
+}
+
+// WithHistoryTempReader sets the temperature reader
+func WithHistoryTempReader(t historyTempReader) HistoryOption {
+	return func(r *HistoryRecorder) {
+		r.temps = t
+	}
+}
+
+// WithHistorySystemReader sets the system metrics reader
+func WithHistorySystemReader(s systemReader) HistoryOption {
+	return func(r *HistoryRecorder) {
+		r.system = s
+	}
+}
+
+// WithHistoryInterface sets the interfaces to record (comma-separated)
+func WithHistoryInterface(iface string) HistoryOption {
+	return func(r *HistoryRecorder) {
+		r.ifaces = splitInterfaces(iface)
+	}
+}
+
+// WithHistoryNowFunc sets the clock (for testing)
+func WithHistoryNowFunc(f func() time.Time) HistoryOption {
+	return func(r *HistoryRecorder) {
+		r.nowFunc = f
+	}
+}
+
+// NewHistoryRecorder creates a new history recorder
+func NewHistoryRecorder(opts ...HistoryOption) *HistoryRecorder {
+	r := &HistoryRecorder{
+		ifaces:   []string{"eth1"},
+		diskPath: "/",
+		nowFunc:  time.Now,
+		counters: make(map[string]counterSample),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Check records one sample of every metric. Interface counters are stored
+// as per-second rates, so the first check only primes them. Nothing is
+// recorded once ctx is done.
+func (r *HistoryRecorder) Check(ctx context.Context) error {
+	now := r.nowFunc()
+	var errs []error
+	record := func(kind, label string, v float64) {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := r.store.Record(history.Name(kind, label), now, v); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// NIC metrics are keyed by interface so aliases can change freely
+	for _, iface := range r.ifaces {
+		reading, err := r.temps.GetNICTemp(ctx, iface)
+		if err != nil {
+			if !errors.Is(err, temperature.ErrSensorUnavailable) {
+				errs = append(errs, fmt.Errorf("%s temp: %w", iface, err))
+			}
+			continue
+		}
+		record(history.MetricNICTemp, iface, reading.Value)
+	}
+	// CPU and board sensors are optional on small routers
+	if cpu, err := r.temps.GetCPUTemps(ctx); err == nil {
+		for _, t := range cpu {
+			record(history.MetricCPUTemp, t.Label, t.Value)
+		}
+	}
+	if board, err := r.temps.GetBoardTemps(); err == nil {
+		for _, t := range board {
+			record(history.MetricBoardTemp, t.Label, t.Value)
+		}
+	}
+
+	if info, err := r.system.GetCPUInfo(); err != nil {
+		errs = append(errs, fmt.Errorf("cpu: %w", err))
+	} else {
+		record(history.MetricCPUUsage, "", info.Usage)
+		record(history.MetricLoad1, "", info.LoadAvg[0])
+	}
+	if info, err := r.system.GetMemoryInfo(); err != nil {
+		errs = append(errs, fmt.Errorf("memory: %w", err))
+	} else {
+		record(history.MetricMemoryUsage, "", info.UsagePercent)
+	}
+	if info, err := r.system.GetDiskInfo(r.diskPath); err != nil {
+		errs = append(errs, fmt.Errorf("disk: %w", err))
+	} else {
+		record(history.MetricDiskUsage, "", info.UsagePercent)
+	}
+
+	for _, iface := range r.ifaces {
+		info, err := r.system.GetNICInfo(ctx, iface)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", iface, err))
+			continue
+		}
+		cur := counterSample{time: now, values: map[string]uint64{
+			history.MetricRxBytes:  info.RxBytes,
+			history.MetricTxBytes:  info.TxBytes,
+			history.MetricRxErrors: info.RxErrors,
+			history.MetricTxErrors: info.TxErrors,
+		}}
+		prev, ok := r.counters[iface]
+		r.counters[iface] = cur
+		elapsed := cur.time.Sub(prev.time).Seconds()
+		if !ok || elapsed <= 0 {
+			continue
+		}
+		for kind, v := range cur.values {
+			// A counter that went backwards was reset (driver reload)
+			if p := prev.values[kind]; v >= p {
+				record(kind, iface, float64(v-p)/elapsed)
+			}
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,166 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/murata-lab/pervigil/bot/internal/history"
+	"github.com/murata-lab/pervigil/bot/internal/sysinfo"
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
+)
+
+type mockHistoryWriter struct {
+	samples map[string][]float64
+	err     error
+}
+
+func (m *mockHistoryWriter) Record(metric string, _ time.Time, v float64) error {
+	if m.err != nil {
+		return m.err
+	}
+	if m.samples == nil {
+		m.samples = make(map[string][]float64)
+	}
+	m.samples[metric] = append(m.samples[metric], v)
+	return nil
+}
+
+type mockHistoryTempReader struct {
+	mockPerIfaceTempReader
+	cpu   []temperature.TempReading
+	board []temperature.TempReading
+}
+
+func (m *mockHistoryTempReader) GetCPUTemps(context.Context) ([]temperature.TempReading, error) {
+	return m.cpu, nil
+}
+
+func (m *mockHistoryTempReader) GetBoardTemps() ([]temperature.TempReading, error) {
+	return m.board, nil
+}
+
+type mockSystemReader struct {
+	nic map[string]*sysinfo.NICInfo
+}
+
+func (m *mockSystemReader) GetCPUInfo() (*sysinfo.CPUInfo, error) {
+	return &sysinfo.CPUInfo{Usage: 12.5, LoadAvg: [3]float64{0.42, 0.35, 0.3}}, nil
+}
+
+func (m *mockSystemReader) GetMemoryInfo() (*sysinfo.MemInfo, error) {
+	return &sysinfo.MemInfo{UsagePercent: 40}, nil
+}
+
+func (m *mockSystemReader) GetDiskInfo(string) (*sysinfo.DiskInfo, error) {
+	return nil, errors.New("statfs failed")
+}
+
+func (m *mockSystemReader) GetNICInfo(_ context.Context, iface string) (*sysinfo.NICInfo, error) {
+	if info, ok := m.nic[iface]; ok {
+		return info, nil
+	}
+	return nil, errors.New("no such interface")
+}
+
+func TestHistoryRecorder_Check(t *testing.T) {
+	store := &mockHistoryWriter{}
+	temps := &mockHistoryTempReader{
+		mockPerIfaceTempReader: mockPerIfaceTempReader{temps: map[string]float64{"eth1": 62}},
+		cpu:                    []temperature.TempReading{{Label: "Package id 0", Value: 47}},
+		board:                  []temperature.TempReading{{Label: "nct6775 temp1", Value: 38}},
+	}
+	sys := &mockSystemReader{nic: map[string]*sysinfo.NICInfo{
+		"eth1": {RxBytes: 1000, TxBytes: 500},
+	}}
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	r := NewHistoryRecorder(
+		WithHistoryStore(store),
+		WithHistoryTempReader(temps),
+		WithHistorySystemReader(sys),
+		WithHistoryInterface("eth1"),
+		WithHistoryNowFunc(func() time.Time { return now }),
+	)
+
+	// The disk error is reported but other metrics are still recorded
+	if err := r.Check(t.Context()); err == nil {
+		t.Error("expected disk error")
+	}
+	for metric, want := range map[string]float64{
+		history.Name(history.MetricNICTemp, "eth1"):            62,
+		history.Name(history.MetricCPUTemp, "Package id 0"):    47,
+		history.Name(history.MetricBoardTemp, "nct6775 temp1"): 38,
+		history.MetricCPUUsage:                                 12.5,
+		history.MetricLoad1:                                    0.42,
+		history.MetricMemoryUsage:                              40,
+	} {
+		if got := store.samples[metric]; len(got) != 1 || got[0] != want {
+			t.Errorf("%s = %v, want [%v]", metric, got, want)
+		}
+	}
+	if _, ok := store.samples[history.Name(history.MetricRxBytes, "eth1")]; ok {
+		t.Error("first check should only prime the counters")
+	}
+
+	// 10s later: 2000 bytes received → 200 B/s; counter reset is skipped
+	now = now.Add(10 * time.Second)
+	sys.nic["eth1"] = &sysinfo.NICInfo{RxBytes: 3000, TxBytes: 100}
+	r.Check(t.Context())
+	if got := store.samples[history.Name(history.MetricRxBytes, "eth1")]; len(got) != 1 || got[0] != 200 {
+		t.Errorf("rx rate = %v, want [200]", got)
+	}
+	if got, ok := store.samples[history.Name(history.MetricTxBytes, "eth1")]; ok {
+		t.Errorf("tx counter went backwards, got %v", got)
+	}
+}
+
+func TestHistoryRecorder_SkipsUnavailableSensor(t *testing.T) {
+	store := &mockHistoryWriter{}
+	temps := &mockHistoryTempReader{mockPerIfaceTempReader: mockPerIfaceTempReader{
+		errs: map[string]error{"eth1": temperature.ErrSensorUnavailable},
+	}}
+	sys := &mockSystemReader{nic: map[string]*sysinfo.NICInfo{"eth1": {}}}
+	r := NewHistoryRecorder(
+		WithHistoryStore(store),
+		WithHistoryTempReader(temps),
+		WithHistorySystemReader(sys),
+	)
+
+	err := r.Check(t.Context())
+	if err == nil || errors.Is(err, temperature.ErrSensorUnavailable) {
+		t.Errorf("error = %v, want only the disk error", err)
+	}
+	if _, ok := store.samples[history.Name(history.MetricNICTemp, "eth1")]; ok {
+		t.Error("unavailable sensor should not be recorded")
+	}
+}
+
+func TestCostMonitor_RecordsHistory(t *testing.T) {
+	ss := &mockCostStateStore{state: CostStateData{State: CostNormal, Date: fixedDate}}
+	store := &mockHistoryWriter{}
+	m := NewCostMonitor(
+		WithCostFetcher(&mockFetcher{cost: 1.25}),
+		WithCostNotifier(&mockCostNotifier{}),
+		WithCostStateStore(ss),
+		WithCostHistory(store),
+		WithCostNowFunc(fixedNow),
+	)
+
+	if err := m.Check(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := store.samples[history.MetricDailyCost]; len(got) != 1 || got[0] != 1.25 {
+		t.Errorf("daily cost = %v, want [1.25]", got)
+	}
+
+	// A failing store does not block the state update
+	store.err = errors.New("disk full")
+	ss.state.Date = ""
+	if err := m.Check(context.Background()); err == nil {
+		t.Error("expected history error")
+	}
+	if ss.state.Date != fixedDate {
+		t.Error("state should still be saved")
+	}
+}
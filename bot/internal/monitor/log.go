@@ -5,6 +5,8 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/murata-lab/pervigil/bot/internal/notifier"
 )
@@ -31,6 +33,12 @@ type LogMonitor struct {
 	reader           LogReader
 	hostname         string
 	warningThreshold int
+
+	mu sync.Mutex // serializes Process and Reconfigure
+
+	// Totals since startup, read by the metrics endpoint
+	errorsTotal   atomic.Int64
+	warningsTotal atomic.Int64
 }
 
 // LogOption configures LogMonitor
@@ -57,6 +65,27 @@ func WithWarningThreshold(n int) LogOption {
 	}
 }
 
+// WithErrorPatterns replaces the patterns that mark a line as an error
+func WithErrorPatterns(patterns []string) LogOption {
+	return func(m *LogMonitor) {
+		m.errorPatterns = compilePatterns(patterns)
+	}
+}
+
+// WithWarningPatterns replaces the patterns that mark a line as a warning
+func WithWarningPatterns(patterns []string) LogOption {
+	return func(m *LogMonitor) {
+		m.warningPatterns = compilePatterns(patterns)
+	}
+}
+
+// WithExcludePatterns replaces the patterns of lines that are never reported
+func WithExcludePatterns(patterns []string) LogOption {
+	return func(m *LogMonitor) {
+		m.excludePatterns = compilePatterns(patterns)
+	}
+}
+
 // NewLogMonitor creates a new log monitor with default patterns
 func NewLogMonitor(opts ...LogOption) *LogMonitor {
 	hostname, _ := os.Hostname()
@@ -86,6 +115,23 @@ func NewLogMonitor(opts ...LogOption) *LogMonitor {
 	return m
 }
 
+// Reconfigure replaces the patterns, reader and notifier with those of
+// NewLogMonitor(opts...), keeping the totals since startup. The read
+// position is kept by the reader's position file.
+func (m *LogMonitor) Reconfigure(opts ...LogOption) {
+	next := NewLogMonitor(opts...)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errorPatterns = next.errorPatterns
+	m.warningPatterns = next.warningPatterns
+	m.excludePatterns = next.excludePatterns
+	m.notifier = next.notifier
+	m.reader = next.reader
+	m.hostname = next.hostname
+	m.warningThreshold = next.warningThreshold
+}
+
 func compilePatterns(patterns []string) []*regexp.Regexp {
 	result := make([]*regexp.Regexp, 0, len(patterns))
 	for _, p := range patterns {
@@ -120,6 +166,9 @@ func matchesAny(line string, patterns []*regexp.Regexp) bool {
 
 // Process reads and processes new log lines
 func (m *LogMonitor) Process() (*ProcessResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	lines, err := m.reader.ReadNewLines()
 	if err != nil {
 		return nil, fmt.Errorf("read lines: %w", err)
@@ -147,6 +196,9 @@ func (m *LogMonitor) Process() (*ProcessResult, error) {
 		}
 	}
 
+	m.errorsTotal.Add(int64(result.ErrorCount))
+	m.warningsTotal.Add(int64(result.WarningCount))
+
 	if err := m.sendNotifications(result); err != nil {
 		return result, err
 	}
@@ -154,6 +206,11 @@ func (m *LogMonitor) Process() (*ProcessResult, error) {
 	return result, nil
 }
 
+// Totals returns the number of error and warning lines seen since startup
+func (m *LogMonitor) Totals() (errorLines, warningLines int64) {
+	return m.errorsTotal.Load(), m.warningsTotal.Load()
+}
+
 func (m *LogMonitor) sendNotifications(result *ProcessResult) error {
 	if result.ErrorCount > 0 {
 		truncated := truncateLines(result.ErrorLines, 1000)
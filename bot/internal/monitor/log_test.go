@@ -192,3 +192,57 @@ func TestLogMonitor_NotifiesOnManyWarnings(t *testing.T) {
 		t.Errorf("title should contain 警告")
 	}
 }
+
+func TestLogMonitor_CustomPatterns(t *testing.T) {
+	reader := &mockLogReader{
+		lines: []string{
+			"ERROR: test error",            // default pattern replaced
+			"bgpd: neighbor 10.0.0.1 Down", // custom error
+			"bgpd: neighbor flap detected", // custom warning
+			"bgpd: neighbor 10.0.0.2 Down maintenance",
+		},
+	}
+
+	m := NewLogMonitor(
+		WithLogNotifier(&mockNotifier{}),
+		WithLogReader(reader),
+		WithErrorPatterns([]string{`neighbor \S+ Down`}),
+		WithWarningPatterns([]string{`flap`}),
+		WithExcludePatterns([]string{`maintenance`}),
+	)
+
+	result, err := m.Process()
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if result.ErrorCount != 1 || result.WarningCount != 1 {
+		t.Errorf("counts = %d errors, %d warnings; want 1, 1", result.ErrorCount, result.WarningCount)
+	}
+}
+
+func TestLogMonitor_Reconfigure(t *testing.T) {
+	reader := &mockLogReader{lines: []string{"ERROR: test error", "bgpd: neighbor 10.0.0.1 Down"}}
+	notif := &mockNotifier{}
+
+	m := NewLogMonitor(WithLogNotifier(notif), WithLogReader(reader))
+	if _, err := m.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	m.Reconfigure(
+		WithLogNotifier(notif),
+		WithLogReader(reader),
+		WithErrorPatterns([]string{`neighbor \S+ Down`}),
+	)
+	result, err := m.Process()
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if result.ErrorCount != 1 {
+		t.Errorf("ErrorCount = %d after reload, want 1", result.ErrorCount)
+	}
+	// Totals survive the reload
+	if errs, _ := m.Totals(); errs != 2 {
+		t.Errorf("errors total = %d, want 2", errs)
+	}
+}
@@ -1,10 +1,15 @@
 package monitor
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/murata-lab/pervigil/bot/internal/notifier"
 	"github.com/murata-lab/pervigil/bot/internal/temperature"
@@ -24,42 +29,33 @@ const (
 )
 
 // NICThresholds defines temperature thresholds
-type NICThresholds struct {
-	Warning  float64
-	Critical float64
-	Recovery float64
-}
-
-// DefaultThresholds returns the default NIC temperature thresholds
-func DefaultThresholds() NICThresholds {
-	return NICThresholds{
-		Warning:  70.0,
-		Critical: 85.0,
-		Recovery: 65.0,
-	}
-}
+type NICThresholds = temperature.Thresholds
 
-// tempReader abstracts temperature reading
+// tempReader abstracts temperature reading; commands run under ctx
 type tempReader interface {
-	GetNICTemp(iface string) (*temperature.TempReading, error)
+	GetNICTemp(ctx context.Context, iface string) (*temperature.TempReading, error)
 }
 
-// MonitorState holds both temperature state and speed limit status
+// MonitorState holds both temperature state and speed limit status of one interface
 type MonitorState struct {
-	TempState    NICState `json:"temp_state"`
-	SpeedLimited bool     `json:"speed_limited"`
+	TempState     NICState     `json:"temp_state"`
+	SpeedLimited  bool         `json:"speed_limited"`
+	ThrottleLevel int          `json:"throttle_level,omitempty"` // throttle ladder steps applied
+	LastThrottle  time.Time    `json:"last_throttle,omitzero"`
+	RiseAlerted   bool         `json:"rise_alerted,omitempty"`
+	Samples       []TempSample `json:"samples,omitempty"`
 }
 
-// StateStore persists monitor state
+// StateStore persists monitor state keyed by interface name
 type StateStore interface {
-	Load() (MonitorState, error)
-	Save(MonitorState) error
+	Load() (map[string]MonitorState, error)
+	Save(map[string]MonitorState) error
 }
 
 // SpeedController controls NIC speed
 type SpeedController interface {
-	Limit(iface string) error
-	Restore(iface string) error
+	Limit(ctx context.Context, iface string, step ThrottleStep) error
+	Restore(ctx context.Context, iface string) error
 }
 
 // NICMonitor monitors NIC temperature and takes action
@@ -68,9 +64,28 @@ type NICMonitor struct {
 	notifier   notifier.Notifier
 	stateStore StateStore
 	speedCtrl  SpeedController
-	thresholds NICThresholds
+	linkReader linkReader
+	charts     historyQuerier // optional: chart source for critical alerts
+	holds      TransitionHolds
+	rise       RiseAlert
+	throttle   ThrottlePolicy
 	ifaces     []string
 	hostname   string
+	dryRun     bool
+	nowFunc    func() time.Time
+
+	checkMu sync.Mutex // serializes Check, Reconcile, RestoreAll and Reconfigure
+
+	mu     sync.Mutex // guards last, speeds and the settings read by the status API during Reconfigure
+	last   map[string]NICReading
+	speeds map[string][]int // detected supported speeds, which never change at runtime
+}
+
+// NICReading is the last temperature read from one interface
+type NICReading struct {
+	Value      float64
+	Thresholds NICThresholds
+	Time       time.Time
 }
 
 // NICOption configures NICMonitor
@@ -128,10 +143,31 @@ func splitInterfaces(s string) []string {
 	return result
 }
 
-// WithThresholds sets custom thresholds
-func WithThresholds(t NICThresholds) NICOption {
+// WithHoldConditions sets how long each transition condition must hold
+func WithHoldConditions(h TransitionHolds) NICOption {
 	return func(m *NICMonitor) {
-		m.thresholds = t
+		m.holds = h
+	}
+}
+
+// WithRiseAlert enables the rate-of-rise alert
+func WithRiseAlert(r RiseAlert) NICOption {
+	return func(m *NICMonitor) {
+		m.rise = r
+	}
+}
+
+// WithThrottlePolicy sets the throttle ladder and step periods
+func WithThrottlePolicy(p ThrottlePolicy) NICOption {
+	return func(m *NICMonitor) {
+		m.throttle = p
+	}
+}
+
+// WithNowFunc sets a custom time source (for testing)
+func WithNowFunc(f func() time.Time) NICOption {
+	return func(m *NICMonitor) {
+		m.nowFunc = f
 	}
 }
 
@@ -139,26 +175,71 @@ func WithThresholds(t NICThresholds) NICOption {
 func NewNICMonitor(opts ...NICOption) *NICMonitor {
 	hostname, _ := os.Hostname()
 	m := &NICMonitor{
-		thresholds: DefaultThresholds(),
-		ifaces:     []string{"eth1"},
-		hostname:   hostname,
+		throttle: DefaultThrottlePolicy(),
+		ifaces:   []string{"eth1"},
+		hostname: hostname,
+		nowFunc:  time.Now,
+		last:     make(map[string]NICReading),
+		speeds:   make(map[string][]int),
 	}
 	for _, opt := range opts {
 		opt(m)
 	}
+	m.applyDryRun()
 	return m
 }
 
-// Check performs a temperature check and takes appropriate action
-func (m *NICMonitor) Check() error {
-	// Find the hottest NIC
-	var maxTemp float64
-	var hottestIface string
+// Reconfigure replaces the settings with those of NewNICMonitor(opts...).
+// Per-interface state lives in the state store and is kept, as are the last
+// readings of interfaces that are still monitored. The speed controller is
+// kept too, as it holds the link modes to restore on throttled links; a
+// WithSpeedController in opts is ignored. It waits for a running Check so
+// that no check mixes old and new settings.
+func (m *NICMonitor) Reconfigure(opts ...NICOption) {
+	m.checkMu.Lock()
+	defer m.checkMu.Unlock()
+	next := NewNICMonitor(append(slices.Clone(opts), WithSpeedController(m.baseSpeedController()))...)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tempReader = next.tempReader
+	m.notifier = next.notifier
+	m.stateStore = next.stateStore
+	m.speedCtrl = next.speedCtrl
+	m.linkReader = next.linkReader
+	m.charts = next.charts
+	m.holds = next.holds
+	m.rise = next.rise
+	m.throttle = next.throttle
+	m.ifaces = next.ifaces
+	m.hostname = next.hostname
+	m.dryRun = next.dryRun
+	m.nowFunc = next.nowFunc
+	maps.DeleteFunc(m.last, func(iface string, _ NICReading) bool {
+		return !slices.Contains(m.ifaces, iface)
+	})
+	maps.DeleteFunc(m.speeds, func(iface string, _ []int) bool {
+		return !slices.Contains(m.ifaces, iface)
+	})
+}
+
+// Check performs a temperature check and takes appropriate action per
+// interface. Speed changes run under ctx; a check canceled while waiting
+// for a running one returns without acting.
+func (m *NICMonitor) Check(ctx context.Context) error {
+	m.checkMu.Lock()
+	defer m.checkMu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	readings := make(map[string]*temperature.TempReading, len(m.ifaces))
 	var lastErr error
 	sensorUnavailable := false
 
 	for _, iface := range m.ifaces {
-		reading, err := m.tempReader.GetNICTemp(iface)
+		reading, err := m.tempReader.GetNICTemp(ctx, iface)
 		if err != nil {
 			if errors.Is(err, temperature.ErrSensorUnavailable) {
 				sensorUnavailable = true
@@ -167,16 +248,13 @@ func (m *NICMonitor) Check() error {
 			}
 			continue
 		}
-		if reading.Value > maxTemp {
-			maxTemp = reading.Value
-			hottestIface = iface
-		}
+		readings[iface] = reading
 	}
 
 	// If no NIC temperature was read, return appropriate error.
 	// Real errors take priority over sensor-unavailable so operators
 	// are alerted to actionable failures first.
-	if hottestIface == "" {
+	if len(readings) == 0 {
 		if sensorUnavailable && lastErr == nil {
 			return temperature.ErrSensorUnavailable
 		}
@@ -186,59 +264,200 @@ func (m *NICMonitor) Check() error {
 		return fmt.Errorf("no NIC temperature available")
 	}
 
-	state, err := m.stateStore.Load()
+	states, err := m.loadStates()
 	if err != nil {
 		return fmt.Errorf("load state: %w", err)
 	}
 
-	newTempState := m.determineState(maxTemp)
-	newState, err := m.handleTransition(state, newTempState, maxTemp, hottestIface)
+	// A failed transition keeps the previous state of that interface
+	// so it is re-attempted on the next check; other interfaces proceed.
+	now := m.nowFunc()
+	var errs []error
+	for _, iface := range m.ifaces {
+		reading, ok := readings[iface]
+		if !ok {
+			continue
+		}
+		temp, t := reading.Value, reading.Thresholds
+		if !t.Known() {
+			errs = append(errs, fmt.Errorf("%s: no temperature thresholds", iface))
+			continue
+		}
+		m.setLastReading(iface, NICReading{Value: temp, Thresholds: t, Time: now})
+		current := states[iface]
+		current.Samples = m.recordSample(current.Samples, now, temp)
+		states[iface] = current
+
+		newTempState, recovered := m.evaluateState(current, temp, t, now)
+		newState, err := m.handleTransition(ctx, current, newTempState, recovered, temp, t, iface, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", iface, err))
+			continue
+		}
+		newState, err = m.checkRise(newState, temp, t, iface, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", iface, err))
+		}
+		states[iface] = newState
+	}
+
+	if err := m.stateStore.Save(states); err != nil {
+		errs = append(errs, fmt.Errorf("save state: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// States returns the persisted state of every monitored interface
+func (m *NICMonitor) States() (map[string]MonitorState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	states, err := m.loadStates()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	result := make(map[string]MonitorState, len(m.ifaces))
+	for _, iface := range m.ifaces {
+		result[iface] = states[iface]
+	}
+	return result, nil
+}
+
+// LastReadings returns the last temperature read from each interface.
+// Interfaces that were never read successfully are absent.
+func (m *NICMonitor) LastReadings() map[string]NICReading {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return maps.Clone(m.last)
+}
+
+func (m *NICMonitor) setLastReading(iface string, r NICReading) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.last[iface] = r
+}
+
+// loadStates loads persisted states, defaulting unknown interfaces to normal.
+// A migrated legacy state belongs to the first interface, the only one the
+// single-interface monitor watched.
+func (m *NICMonitor) loadStates() (map[string]MonitorState, error) {
+	states, err := m.stateStore.Load()
+	if err != nil {
+		return nil, err
+	}
+	if states == nil {
+		states = make(map[string]MonitorState)
+	}
+
+	legacy, hasLegacy := states[legacyStateKey]
+	delete(states, legacyStateKey)
+
+	for i, iface := range m.ifaces {
+		if _, ok := states[iface]; ok {
+			continue
+		}
+		if hasLegacy && i == 0 {
+			states[iface] = legacy
+		} else {
+			states[iface] = MonitorState{TempState: StateNormal}
+		}
 	}
 
-	return m.stateStore.Save(newState)
+	// States written before the throttle ladder existed were limited one step
+	for iface, state := range states {
+		if state.SpeedLimited && state.ThrottleLevel == 0 {
+			state.ThrottleLevel = 1
+			states[iface] = state
+		}
+	}
+	return states, nil
 }
 
-func (m *NICMonitor) determineState(temp float64) NICState {
-	if temp >= m.thresholds.Critical {
+func determineState(temp float64, t NICThresholds) NICState {
+	if temp >= t.Critical {
 		return StateCritical
 	}
-	if temp >= m.thresholds.Warning {
+	if temp >= t.Warning {
 		return StateWarning
 	}
 	return StateNormal
 }
 
-func (m *NICMonitor) handleTransition(current MonitorState, newTempState NICState, temp float64, iface string) (MonitorState, error) {
-	newState := MonitorState{TempState: newTempState, SpeedLimited: current.SpeedLimited}
+func (m *NICMonitor) handleTransition(ctx context.Context, current MonitorState, newTempState NICState, recovered bool, temp float64, t NICThresholds, iface string, now time.Time) (MonitorState, error) {
+	newState := current
+	newState.TempState = newTempState
+	ladder := m.ladderFor(ctx, iface)
+	level := min(current.ThrottleLevel, len(ladder))
 
 	switch {
-	case newTempState == StateCritical && current.TempState != StateCritical:
-		if err := m.notifier.Send(
-			fmt.Sprintf("🔥 NIC過熱警報 - %s", m.hostname),
-			fmt.Sprintf("NIC(%s)温度が危険域に達しました。速度を1Gbpsに制限します。", iface),
+	case newTempState == StateCritical:
+		entering := current.TempState != StateCritical
+		stepDown := level < len(ladder) &&
+			(level == 0 || now.Sub(current.LastThrottle) >= m.throttle.StepDownAfter)
+		if !entering && !stepDown {
+			break
+		}
+
+		title := fmt.Sprintf("🔥 NIC過熱警報 - %s", m.hostname)
+		if !entering {
+			title = fmt.Sprintf("🔥 NIC過熱継続 - %s", m.hostname)
+		}
+		message := fmt.Sprintf("NIC(%s)温度が危険域に達しました。速度制限を継続します。", iface)
+		action := "Throttle unchanged"
+		if stepDown {
+			step := ladder[level]
+			message = fmt.Sprintf("NIC(%s)温度が危険域に達しました。速度を%sに制限します。", iface, step)
+			if !entering {
+				message = fmt.Sprintf("NIC(%s)温度が危険域のままです。速度を%sに制限します。", iface, step)
+			}
+			action = throttleAction(step)
+		}
+
+		if err := notifier.SendWithAttachments(
+			m.notifier,
+			title,
+			message,
 			notifier.ColorRed,
-			m.makeFields(temp, "Interface", iface, "Threshold", m.thresholds.Critical, "Action", "Speed limited to 1Gbps"),
+			m.makeFields(temp, "Interface", iface, "Threshold", t.Critical, "Action", action),
+			m.temperatureChart(iface, temp, t, now),
 		); err != nil {
 			return newState, fmt.Errorf("send notification: %w", err)
 		}
-		if err := m.speedCtrl.Limit(iface); err != nil {
-			return newState, err
+		if stepDown {
+			if err := m.speedCtrl.Limit(ctx, iface, ladder[level]); err != nil {
+				return newState, err
+			}
+			newState.ThrottleLevel = level + 1
+			newState.LastThrottle = now
 		}
-		newState.SpeedLimited = true
 
 	case newTempState == StateWarning && current.TempState == StateNormal:
 		if err := m.notifier.Send(
 			fmt.Sprintf("⚠️ NIC温度警告 - %s", m.hostname),
 			fmt.Sprintf("NIC(%s)温度が警告域に達しました。監視を継続します。", iface),
 			notifier.ColorYellow,
-			m.makeFields(temp, "Interface", iface, "Warning Threshold", m.thresholds.Warning, "Critical Threshold", m.thresholds.Critical),
+			m.makeFields(temp, "Interface", iface, "Warning Threshold", t.Warning, "Critical Threshold", t.Critical),
 		); err != nil {
 			return newState, err
 		}
 
-	case current.SpeedLimited && temp <= m.thresholds.Recovery:
+	case recovered && level > 1 && now.Sub(current.LastThrottle) >= m.throttle.StepUpAfter:
+		// Step back up one level per recovery period
+		step := ladder[level-2]
+		if err := m.notifier.Send(
+			fmt.Sprintf("🌡️ NIC速度制限緩和 - %s", m.hostname),
+			fmt.Sprintf("NIC(%s)温度が低下しました。速度制限を%sに緩和します。", iface, step),
+			notifier.ColorBlue,
+			m.makeFields(temp, "Interface", iface, "Action", throttleAction(step)),
+		); err != nil {
+			return newState, fmt.Errorf("send notification: %w", err)
+		}
+		if err := m.speedCtrl.Limit(ctx, iface, step); err != nil {
+			return newState, err
+		}
+		newState.ThrottleLevel = level - 1
+		newState.LastThrottle = now
+
+	case recovered && now.Sub(current.LastThrottle) >= m.throttle.StepUpAfter:
 		// Restore speed when below recovery threshold
 		if err := m.notifier.Send(
 			fmt.Sprintf("✅ NIC温度正常化 - %s", m.hostname),
@@ -248,10 +467,11 @@ func (m *NICMonitor) handleTransition(current MonitorState, newTempState NICStat
 		); err != nil {
 			return newState, fmt.Errorf("send notification: %w", err)
 		}
-		if err := m.speedCtrl.Restore(iface); err != nil {
+		if err := m.speedCtrl.Restore(ctx, iface); err != nil {
 			return newState, err
 		}
-		newState.SpeedLimited = false
+		newState.ThrottleLevel = 0
+		newState.LastThrottle = now
 
 	case newTempState == StateNormal && current.TempState == StateWarning:
 		if err := m.notifier.Send(
@@ -264,9 +484,18 @@ func (m *NICMonitor) handleTransition(current MonitorState, newTempState NICStat
 		}
 	}
 
+	newState.SpeedLimited = newState.ThrottleLevel > 0
 	return newState, nil
 }
 
+// throttleAction describes a throttle step for notification fields
+func throttleAction(step ThrottleStep) string {
+	if step == StepAdminDown {
+		return "Link administratively down"
+	}
+	return "Speed limited to " + step.String()
+}
+
 func (m *NICMonitor) makeFields(temp float64, extra ...any) []notifier.Field {
 	fields := []notifier.Field{
 		{Name: "Temperature", Value: fmt.Sprintf("%.1f°C", temp), Inline: true},
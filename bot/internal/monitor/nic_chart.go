@@ -0,0 +1,63 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/murata-lab/pervigil/bot/internal/chart"
+	"github.com/murata-lab/pervigil/bot/internal/history"
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+)
+
+// chartWindow is the span of the temperature chart attached to critical alerts
+const chartWindow = 6 * time.Hour
+
+// historyQuerier reads recorded metric samples
+type historyQuerier interface {
+	Query(metric string, from, to time.Time) (history.Series, error)
+}
+
+// WithChartHistory attaches a chart of the recent NIC temperature recorded
+// in h to critical alerts
+func WithChartHistory(h historyQuerier) NICOption {
+	return func(m *NICMonitor) {
+		m.charts = h
+	}
+}
+
+// temperatureChart renders the recent temperature of iface. The chart is a
+// best-effort extra: without history the alert is sent without one.
+func (m *NICMonitor) temperatureChart(iface string, temp float64, t NICThresholds, now time.Time) []notifier.Attachment {
+	if m.charts == nil {
+		return nil
+	}
+	from := now.Add(-chartWindow)
+	series, err := m.charts.Query(history.Name(history.MetricNICTemp, iface), from, now)
+	if err != nil {
+		if !errors.Is(err, history.ErrUnknownMetric) {
+			log.Printf("NIC chart %s: %v", iface, err)
+		}
+		return nil
+	}
+	if len(series.Points) == 0 {
+		return nil
+	}
+
+	// History is recorded after the checks, so the current reading is not in it yet
+	points := append(series.Points, history.Point{Time: now, Min: temp, Max: temp, Avg: temp, Count: 1})
+	png, err := chart.PNG(chart.Chart{
+		Title:  fmt.Sprintf("%s %s temperature (6h)", m.hostname, iface),
+		From:   from,
+		To:     now,
+		Step:   series.Step,
+		Series: []chart.Series{{Label: iface, Points: points}},
+		Lines:  chart.ThresholdLines(t),
+	})
+	if err != nil {
+		log.Printf("NIC chart %s: %v", iface, err)
+		return nil
+	}
+	return []notifier.Attachment{{Name: "nic-temp.png", ContentType: "image/png", Data: png}}
+}
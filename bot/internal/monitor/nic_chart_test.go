@@ -0,0 +1,88 @@
+package monitor
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/murata-lab/pervigil/bot/internal/history"
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+)
+
+type mockHistoryQuerier struct {
+	series history.Series
+	err    error
+}
+
+func (m *mockHistoryQuerier) Query(metric string, from, to time.Time) (history.Series, error) {
+	if m.err != nil {
+		return history.Series{}, m.err
+	}
+	s := m.series
+	s.Metric = metric
+	return s, nil
+}
+
+type mockAttachmentNotifier struct {
+	mockNotifier
+	files [][]notifier.Attachment
+}
+
+func (m *mockAttachmentNotifier) SendWithAttachments(title, message string, color notifier.Color, fields []notifier.Field, files []notifier.Attachment) error {
+	m.files = append(m.files, files)
+	return m.Send(title, message, color, fields)
+}
+
+func TestNICMonitor_CriticalAlertAttachesChart(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var points []history.Point
+	for m := 6 * 60; m > 0; m-- {
+		v := 90 - float64(m)/20
+		points = append(points, history.Point{Time: now.Add(-time.Duration(m) * time.Minute), Min: v, Max: v, Avg: v, Count: 1})
+	}
+	notif := &mockAttachmentNotifier{}
+
+	m := NewNICMonitor(
+		WithTempReader(&mockTempReader{temp: 90.0}),
+		WithNotifier(notif),
+		WithStateStore(newMockStateStore("eth1", MonitorState{TempState: StateNormal})),
+		WithSpeedController(&mockSpeedController{}),
+		WithChartHistory(&mockHistoryQuerier{series: history.Series{Step: time.Minute, Points: points}}),
+		WithNowFunc(func() time.Time { return now }),
+	)
+
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(notif.files) != 1 || len(notif.files[0]) != 1 {
+		t.Fatalf("attachments = %v, want one chart", notif.files)
+	}
+	f := notif.files[0][0]
+	if f.ContentType != "image/png" {
+		t.Errorf("content type = %q", f.ContentType)
+	}
+	if _, err := png.Decode(bytes.NewReader(f.Data)); err != nil {
+		t.Errorf("chart is not a PNG: %v", err)
+	}
+}
+
+func TestNICMonitor_CriticalAlertWithoutHistory(t *testing.T) {
+	notif := &mockAttachmentNotifier{}
+
+	m := NewNICMonitor(
+		WithTempReader(&mockTempReader{temp: 90.0}),
+		WithNotifier(notif),
+		WithStateStore(newMockStateStore("eth1", MonitorState{TempState: StateNormal})),
+		WithSpeedController(&mockSpeedController{}),
+		WithChartHistory(&mockHistoryQuerier{err: history.ErrUnknownMetric}),
+	)
+
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	// The alert still goes out, just without a chart
+	if len(notif.calls) != 1 || len(notif.files) != 0 {
+		t.Errorf("calls = %d, attachments = %v", len(notif.calls), notif.files)
+	}
+}
@@ -0,0 +1,166 @@
+package monitor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+)
+
+// maxHistorySamples bounds the persisted sample history per interface.
+const maxHistorySamples = 1440
+
+// TempSample is a single temperature observation
+type TempSample struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// HoldCondition requires a condition to persist before a transition fires.
+// The zero value fires on the first matching sample.
+type HoldCondition struct {
+	Samples  int           // consecutive matching samples required
+	Duration time.Duration // minimum time the condition must have held
+}
+
+// TransitionHolds configures hold conditions for each NIC state transition
+type TransitionHolds struct {
+	Warning  HoldCondition // normal → warning (temp >= Warning)
+	Critical HoldCondition // → critical (temp >= Critical)
+	Recovery HoldCondition // speed restore (temp <= Recovery)
+	Normal   HoldCondition // → normal (temp < Warning)
+}
+
+// RiseAlert fires a notification when the temperature rises by Delta
+// within Window, before the absolute warning threshold is reached.
+// A zero Delta disables the alert.
+type RiseAlert struct {
+	Delta  float64
+	Window time.Duration
+}
+
+// heldBy reports whether the newest samples satisfy pred for the required
+// number of consecutive samples and duration.
+func (c HoldCondition) heldBy(samples []TempSample, now time.Time, pred func(float64) bool) bool {
+	count := 0
+	var since time.Time
+	for i := len(samples) - 1; i >= 0; i-- {
+		if !pred(samples[i].Value) {
+			break
+		}
+		count++
+		since = samples[i].Time
+	}
+	if count == 0 || count < c.Samples {
+		return false
+	}
+	return now.Sub(since) >= c.Duration
+}
+
+// historyLimits returns how many samples and how much time the
+// configured conditions need to look back on.
+func (m *NICMonitor) historyLimits() (int, time.Duration) {
+	keep := 1
+	var age time.Duration
+	for _, c := range []HoldCondition{m.holds.Warning, m.holds.Critical, m.holds.Recovery, m.holds.Normal} {
+		keep = max(keep, c.Samples)
+		age = max(age, c.Duration)
+	}
+	age = max(age, m.rise.Window)
+	return keep, age
+}
+
+// recordSample appends a sample and drops history no condition needs.
+func (m *NICMonitor) recordSample(samples []TempSample, now time.Time, temp float64) []TempSample {
+	samples = append(samples, TempSample{Time: now, Value: temp})
+	keep, age := m.historyLimits()
+
+	// Keep the newest sample at or before the cutoff so a condition
+	// spanning the whole window can still be proven.
+	cutoff := now.Add(-age)
+	ageStart := 0
+	for i := len(samples) - 1; i >= 0; i-- {
+		if !samples[i].Time.After(cutoff) {
+			ageStart = i
+			break
+		}
+	}
+
+	start := min(max(len(samples)-keep, 0), ageStart)
+	start = max(start, len(samples)-maxHistorySamples)
+	return append([]TempSample(nil), samples[start:]...)
+}
+
+// evaluateState determines the next temperature state and whether the
+// speed limit may be lifted, applying the configured hold conditions.
+func (m *NICMonitor) evaluateState(current MonitorState, temp float64, t NICThresholds, now time.Time) (NICState, bool) {
+	samples := current.Samples
+	raw := determineState(temp, t)
+	next := current.TempState
+
+	switch {
+	case raw == StateCritical && m.holds.Critical.heldBy(samples, now, func(v float64) bool { return v >= t.Critical }):
+		next = StateCritical
+	case raw != StateNormal:
+		switch current.TempState {
+		case StateCritical:
+			if raw == StateWarning {
+				next = StateWarning
+			}
+		case StateNormal:
+			if m.holds.Warning.heldBy(samples, now, func(v float64) bool { return v >= t.Warning }) {
+				next = StateWarning
+			}
+		}
+	case current.TempState != StateNormal:
+		if m.holds.Normal.heldBy(samples, now, func(v float64) bool { return v < t.Warning }) {
+			next = StateNormal
+		}
+	}
+
+	recovered := current.SpeedLimited && temp <= t.Recovery &&
+		m.holds.Recovery.heldBy(samples, now, func(v float64) bool { return v <= t.Recovery })
+
+	return next, recovered
+}
+
+// riseWithin returns how much the temperature rose within the rise window.
+func (m *NICMonitor) riseWithin(samples []TempSample, now time.Time, temp float64) float64 {
+	lowest := temp
+	cutoff := now.Add(-m.rise.Window)
+	for _, s := range samples {
+		if s.Time.Before(cutoff) {
+			continue
+		}
+		lowest = min(lowest, s.Value)
+	}
+	return temp - lowest
+}
+
+// checkRise sends a rate-of-rise alert while the NIC is still below the
+// warning threshold. The alert re-arms once the rise falls below Delta.
+func (m *NICMonitor) checkRise(state MonitorState, temp float64, t NICThresholds, iface string, now time.Time) (MonitorState, error) {
+	if m.rise.Delta <= 0 {
+		return state, nil
+	}
+
+	rise := m.riseWithin(state.Samples, now, temp)
+	if rise < m.rise.Delta || state.TempState != StateNormal {
+		state.RiseAlerted = false
+		return state, nil
+	}
+	if state.RiseAlerted {
+		return state, nil
+	}
+
+	if err := m.notifier.Send(
+		fmt.Sprintf("📈 NIC温度急上昇 - %s", m.hostname),
+		fmt.Sprintf("NIC(%s)温度が%s以内に%.1f°C上昇しました。", iface, m.rise.Window, rise),
+		notifier.ColorYellow,
+		m.makeFields(temp, "Interface", iface, "Rise", fmt.Sprintf("+%.1f°C", rise), "Warning Threshold", t.Warning),
+	); err != nil {
+		return state, fmt.Errorf("send notification: %w", err)
+	}
+	state.RiseAlerted = true
+	return state, nil
+}
@@ -0,0 +1,221 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+	"github.com/murata-lab/pervigil/bot/internal/sysroot"
+)
+
+// linkReader reads the actual link state of a NIC
+type linkReader interface {
+	LinkSpeed(iface string) (int, error) // Mbps
+	LinkUp(iface string) (bool, error)   // administratively up
+}
+
+// SysfsLinkReader reads link state from /sys/class/net
+type SysfsLinkReader struct {
+	root string
+}
+
+// NewSysfsLinkReader creates a link reader for /sys/class/net below the default sysroot
+func NewSysfsLinkReader() *SysfsLinkReader {
+	return &SysfsLinkReader{root: sysroot.Default().Path("/sys/class/net")}
+}
+
+// LinkSpeed returns the negotiated link speed in Mbps.
+func (r *SysfsLinkReader) LinkSpeed(iface string) (int, error) {
+	data, err := os.ReadFile(filepath.Join(r.root, iface, "speed"))
+	if err != nil {
+		return 0, err
+	}
+	speed, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("parse speed of %s: %w", iface, err)
+	}
+	if speed <= 0 {
+		return 0, fmt.Errorf("link speed of %s unknown", iface)
+	}
+	return speed, nil
+}
+
+// LinkUp reports whether the IFF_UP flag is set.
+func (r *SysfsLinkReader) LinkUp(iface string) (bool, error) {
+	data, err := os.ReadFile(filepath.Join(r.root, iface, "flags"))
+	if err != nil {
+		return false, err
+	}
+	flags, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"), 16, 32)
+	if err != nil {
+		return false, fmt.Errorf("parse flags of %s: %w", iface, err)
+	}
+	return flags&0x1 != 0, nil
+}
+
+// WithLinkReader sets the link state reader used by Reconcile
+func WithLinkReader(r linkReader) NICOption {
+	return func(m *NICMonitor) {
+		m.linkReader = r
+	}
+}
+
+// Reconcile compares the persisted throttle state with the actual link
+// and repairs drift: a limit recorded on disk but not applied on the NIC is
+// cleared, and a NIC left throttled without a recorded limit is restored.
+// Repairs are reported in a single notification. Nothing is reconciled in
+// dry-run mode, whose recorded limits are never applied.
+func (m *NICMonitor) Reconcile(ctx context.Context) error {
+	m.checkMu.Lock()
+	defer m.checkMu.Unlock()
+	if m.dryRun {
+		return nil
+	}
+
+	states, err := m.loadStates()
+	if err != nil {
+		return fmt.Errorf("load state: %w", err)
+	}
+
+	var fixes []string
+	var errs []error
+	for _, iface := range m.ifaces {
+		state := states[iface]
+		ladder := m.ladderFor(ctx, iface)
+
+		if state.ThrottleLevel > 0 && len(ladder) > 0 {
+			step := ladder[min(state.ThrottleLevel, len(ladder))-1]
+			applied, known := m.stepApplied(iface, step)
+			if known && !applied {
+				state.ThrottleLevel = 0
+				state.SpeedLimited = false
+				states[iface] = state
+				fixes = append(fixes, fmt.Sprintf("%s: 状態ファイルでは%s制限中だがNICに適用されていないため、制限状態を解除", iface, step))
+			}
+			continue
+		}
+
+		if m.throttledWithoutRecord(ctx, iface, ladder) {
+			if err := m.speedCtrl.Restore(ctx, iface); err != nil {
+				errs = append(errs, fmt.Errorf("%s: restore: %w", iface, err))
+				continue
+			}
+			fixes = append(fixes, fmt.Sprintf("%s: 状態ファイルに記録のない速度制限を解除", iface))
+		}
+	}
+
+	if len(fixes) > 0 {
+		if err := m.stateStore.Save(states); err != nil {
+			errs = append(errs, fmt.Errorf("save state: %w", err))
+		}
+		if err := m.notifier.Send(
+			fmt.Sprintf("🔧 NIC状態を修復 - %s", m.hostname),
+			strings.Join(fixes, "\n"),
+			notifier.ColorBlue,
+			nil,
+		); err != nil {
+			errs = append(errs, fmt.Errorf("send notification: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RestoreAll lifts every speed limit recorded in the state store.
+// It is called on shutdown so a stopped monitor never leaves a NIC throttled,
+// and waits for a running Check so that Check cannot save its state over
+// the restored one.
+func (m *NICMonitor) RestoreAll(ctx context.Context) error {
+	m.checkMu.Lock()
+	defer m.checkMu.Unlock()
+
+	states, err := m.loadStates()
+	if err != nil {
+		return fmt.Errorf("load state: %w", err)
+	}
+
+	now := m.nowFunc()
+	var restored []string
+	var errs []error
+	for iface, state := range states {
+		if state.ThrottleLevel == 0 {
+			continue
+		}
+		if err := m.speedCtrl.Restore(ctx, iface); err != nil {
+			errs = append(errs, fmt.Errorf("%s: restore: %w", iface, err))
+			continue
+		}
+		state.ThrottleLevel = 0
+		state.SpeedLimited = false
+		state.LastThrottle = now
+		states[iface] = state
+		restored = append(restored, iface)
+	}
+
+	if len(restored) == 0 {
+		return errors.Join(errs...)
+	}
+	slices.Sort(restored)
+
+	if err := m.stateStore.Save(states); err != nil {
+		errs = append(errs, fmt.Errorf("save state: %w", err))
+	}
+	if err := m.notifier.Send(
+		fmt.Sprintf("🛑 監視停止 - %s", m.hostname),
+		fmt.Sprintf("監視を停止するため、NIC(%s)の速度制限を解除しました。", strings.Join(restored, ", ")),
+		notifier.ColorYellow,
+		nil,
+	); err != nil {
+		errs = append(errs, fmt.Errorf("send notification: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// stepApplied reports whether step is in effect on iface.
+// known is false when the link state cannot be read.
+func (m *NICMonitor) stepApplied(iface string, step ThrottleStep) (applied, known bool) {
+	if m.linkReader == nil {
+		return false, false
+	}
+	if step == StepAdminDown {
+		up, err := m.linkReader.LinkUp(iface)
+		return !up, err == nil
+	}
+	speed, err := m.linkReader.LinkSpeed(iface)
+	if err != nil {
+		return false, false
+	}
+	return speed == int(step), true
+}
+
+// throttledWithoutRecord reports whether iface runs at one of the ladder
+// speeds below its maximum supported speed with the speed forced. A link
+// that auto-negotiated down (e.g. a 10G NIC on a 1G switch) is left alone,
+// as is one whose link settings cannot be read.
+func (m *NICMonitor) throttledWithoutRecord(ctx context.Context, iface string, ladder []ThrottleStep) bool {
+	if m.linkReader == nil {
+		return false
+	}
+	speeds, ok := m.supportedSpeeds(ctx, iface)
+	if !ok {
+		return false
+	}
+	speed, err := m.linkReader.LinkSpeed(iface)
+	if err != nil {
+		return false
+	}
+	if speed >= slices.Max(speeds) || !slices.Contains(ladder, ThrottleStep(speed)) {
+		return false
+	}
+	detector, ok := m.speedCtrl.(pinDetector)
+	if !ok {
+		return false
+	}
+	pinned, err := detector.SpeedPinned(ctx, iface)
+	return err == nil && pinned
+}
@@ -0,0 +1,290 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// mockLinkReader returns fixed link speeds and admin states per interface.
+type mockLinkReader struct {
+	speeds map[string]int
+	down   map[string]bool
+}
+
+func (r *mockLinkReader) LinkSpeed(iface string) (int, error) {
+	if s, ok := r.speeds[iface]; ok {
+		return s, nil
+	}
+	return 0, os.ErrNotExist
+}
+
+func (r *mockLinkReader) LinkUp(iface string) (bool, error) {
+	return !r.down[iface], nil
+}
+
+func TestNICMonitor_Reconcile_ClearsStaleLimit(t *testing.T) {
+	// State says 1Gbps limited, NIC runs at 10Gbps (e.g. after a reboot)
+	notif := &mockNotifier{}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateCritical, SpeedLimited: true, ThrottleLevel: 1})
+	speed := &mockSpeedController{}
+
+	m := NewNICMonitor(
+		WithNotifier(notif),
+		WithStateStore(store),
+		WithSpeedController(speed),
+		WithLinkReader(&mockLinkReader{speeds: map[string]int{"eth1": 10000}}),
+	)
+
+	if err := m.Reconcile(t.Context()); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if store.states["eth1"].SpeedLimited || store.states["eth1"].ThrottleLevel != 0 {
+		t.Errorf("state = %+v, want limit cleared", store.states["eth1"])
+	}
+	if store.states["eth1"].TempState != StateCritical {
+		t.Errorf("TempState = %v, want preserved", store.states["eth1"].TempState)
+	}
+	if len(notif.calls) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(notif.calls))
+	}
+	if speed.restored {
+		t.Error("speed should not be touched")
+	}
+}
+
+func TestNICMonitor_Reconcile_RestoresUnrecordedLimit(t *testing.T) {
+	// State says unlimited, NIC is pinned at 1Gbps
+	notif := &mockNotifier{}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateNormal})
+	speed := &detectingSpeedController{speeds: []int{100, 1000, 10000}, pinned: true}
+
+	m := NewNICMonitor(
+		WithNotifier(notif),
+		WithStateStore(store),
+		WithSpeedController(speed),
+		WithLinkReader(&mockLinkReader{speeds: map[string]int{"eth1": 1000}}),
+	)
+
+	if err := m.Reconcile(t.Context()); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if len(speed.restoreIfaces) != 1 || speed.restoreIfaces[0] != "eth1" {
+		t.Errorf("restored = %v, want [eth1]", speed.restoreIfaces)
+	}
+	if len(notif.calls) != 1 {
+		t.Errorf("expected 1 notification, got %d", len(notif.calls))
+	}
+}
+
+func TestNICMonitor_Reconcile_NegotiatedDownKept(t *testing.T) {
+	// A 10G NIC on a 1G switch auto-negotiates 1Gbps; that is not a limit
+	notif := &mockNotifier{}
+	speed := &detectingSpeedController{speeds: []int{100, 1000, 10000}}
+
+	m := NewNICMonitor(
+		WithNotifier(notif),
+		WithStateStore(newMockStateStore("eth1", MonitorState{TempState: StateNormal})),
+		WithSpeedController(speed),
+		WithLinkReader(&mockLinkReader{speeds: map[string]int{"eth1": 1000}}),
+	)
+
+	if err := m.Reconcile(t.Context()); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if len(speed.restoreIfaces) != 0 || len(notif.calls) != 0 {
+		t.Errorf("restored = %v, notifications = %d; want the link left alone", speed.restoreIfaces, len(notif.calls))
+	}
+}
+
+func TestNICMonitor_Reconcile_Consistent(t *testing.T) {
+	notif := &mockNotifier{}
+	store := &mockStateStore{states: map[string]MonitorState{
+		"eth1": {TempState: StateCritical, SpeedLimited: true, ThrottleLevel: 1},
+		"eth2": {TempState: StateNormal},
+	}}
+	speed := &detectingSpeedController{speeds: []int{100, 1000, 10000}}
+
+	m := NewNICMonitor(
+		WithNotifier(notif),
+		WithStateStore(store),
+		WithSpeedController(speed),
+		WithInterface("eth1,eth2"),
+		WithLinkReader(&mockLinkReader{speeds: map[string]int{"eth1": 1000, "eth2": 10000}}),
+	)
+
+	if err := m.Reconcile(t.Context()); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if len(notif.calls) != 0 {
+		t.Errorf("expected no notifications, got %d", len(notif.calls))
+	}
+	if speed.restored {
+		t.Error("speed should not be restored")
+	}
+	if !store.states["eth1"].SpeedLimited {
+		t.Error("eth1 limit should be kept")
+	}
+}
+
+func TestNICMonitor_Reconcile_UnknownSpeedKeepsState(t *testing.T) {
+	// Link down (speed unreadable): do not guess
+	store := newMockStateStore("eth1", MonitorState{TempState: StateCritical, SpeedLimited: true, ThrottleLevel: 1})
+
+	m := NewNICMonitor(
+		WithNotifier(&mockNotifier{}),
+		WithStateStore(store),
+		WithSpeedController(&mockSpeedController{}),
+		WithLinkReader(&mockLinkReader{}),
+	)
+
+	if err := m.Reconcile(t.Context()); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if !store.states["eth1"].SpeedLimited {
+		t.Error("limit should be kept when link speed is unknown")
+	}
+}
+
+func TestNICMonitor_RestoreAll(t *testing.T) {
+	notif := &mockNotifier{}
+	store := &mockStateStore{states: map[string]MonitorState{
+		"eth1": {TempState: StateCritical, SpeedLimited: true, ThrottleLevel: 2},
+		"eth2": {TempState: StateNormal},
+	}}
+	speed := &mockSpeedController{}
+
+	m := NewNICMonitor(
+		WithNotifier(notif),
+		WithStateStore(store),
+		WithSpeedController(speed),
+		WithInterface("eth1,eth2"),
+	)
+
+	if err := m.RestoreAll(t.Context()); err != nil {
+		t.Fatalf("RestoreAll() error = %v", err)
+	}
+
+	if len(speed.restoreIfaces) != 1 || speed.restoreIfaces[0] != "eth1" {
+		t.Errorf("restored = %v, want [eth1]", speed.restoreIfaces)
+	}
+	if store.states["eth1"].SpeedLimited || store.states["eth1"].ThrottleLevel != 0 {
+		t.Errorf("eth1 state = %+v, want unthrottled", store.states["eth1"])
+	}
+	if len(notif.calls) != 1 {
+		t.Errorf("expected 1 notification, got %d", len(notif.calls))
+	}
+}
+
+func TestNICMonitor_RestoreAll_NothingLimited(t *testing.T) {
+	notif := &mockNotifier{}
+	m := NewNICMonitor(
+		WithNotifier(notif),
+		WithStateStore(newMockStateStore("eth1", MonitorState{TempState: StateWarning})),
+		WithSpeedController(&mockSpeedController{}),
+	)
+
+	if err := m.RestoreAll(t.Context()); err != nil {
+		t.Fatalf("RestoreAll() error = %v", err)
+	}
+	if len(notif.calls) != 0 {
+		t.Errorf("expected no notifications, got %d", len(notif.calls))
+	}
+}
+
+// blockingSpeedController holds Limit until release is closed
+type blockingSpeedController struct {
+	mockSpeedController
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingSpeedController) Limit(ctx context.Context, iface string, step ThrottleStep) error {
+	close(b.entered)
+	<-b.release
+	return b.mockSpeedController.Limit(ctx, iface, step)
+}
+
+func TestNICMonitor_RestoreAll_WaitsForCheck(t *testing.T) {
+	store := newMockStateStore("eth1", MonitorState{TempState: StateNormal})
+	speed := &blockingSpeedController{entered: make(chan struct{}), release: make(chan struct{})}
+	m := NewNICMonitor(
+		WithTempReader(&mockTempReader{temp: 90.0}),
+		WithNotifier(&mockNotifier{}),
+		WithStateStore(store),
+		WithSpeedController(speed),
+	)
+
+	go m.Check(t.Context())
+	<-speed.entered
+
+	restored := make(chan error)
+	go func() { restored <- m.RestoreAll(t.Context()) }()
+	select {
+	case <-restored:
+		t.Fatal("RestoreAll() returned while Check was running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(speed.release)
+	if err := <-restored; err != nil {
+		t.Fatalf("RestoreAll() error = %v", err)
+	}
+	if len(speed.restoreIfaces) != 1 {
+		t.Errorf("restored = %v, want [eth1]", speed.restoreIfaces)
+	}
+	if store.states["eth1"].SpeedLimited {
+		t.Errorf("eth1 state = %+v, want unthrottled", store.states["eth1"])
+	}
+}
+
+func TestNICMonitor_Check_Canceled(t *testing.T) {
+	speed := &mockSpeedController{}
+	m := NewNICMonitor(
+		WithTempReader(&mockTempReader{temp: 90.0}),
+		WithNotifier(&mockNotifier{}),
+		WithStateStore(newMockStateStore("eth1", MonitorState{TempState: StateNormal})),
+		WithSpeedController(speed),
+	)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if err := m.Check(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Check() error = %v, want context.Canceled", err)
+	}
+	if speed.limited {
+		t.Error("expected no speed change after cancel")
+	}
+}
+
+func TestSysfsLinkReader(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "eth1")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "speed"), []byte("1000\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "flags"), []byte("0x1002\n"), 0644)
+
+	r := &SysfsLinkReader{root: root}
+	speed, err := r.LinkSpeed("eth1")
+	if err != nil || speed != 1000 {
+		t.Errorf("LinkSpeed() = %d, %v, want 1000", speed, err)
+	}
+	up, err := r.LinkUp("eth1")
+	if err != nil || up {
+		t.Errorf("LinkUp() = %v, %v, want false", up, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "speed"), []byte("-1\n"), 0644)
+	if _, err := r.LinkSpeed("eth1"); err == nil {
+		t.Error("expected error for unknown speed")
+	}
+}
@@ -1,23 +1,37 @@
 package monitor
 
 import (
+	"context"
 	"errors"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/murata-lab/pervigil/bot/internal/ethtool"
 	"github.com/murata-lab/pervigil/bot/internal/notifier"
 	"github.com/murata-lab/pervigil/bot/internal/temperature"
 )
 
+// nicDefaults are the thresholds the temperature registry gives a NIC
+// reading without calibration
+var nicDefaults = temperature.DefaultThresholds(temperature.CategoryNIC)
+
 type mockTempReader struct {
-	temp float64
-	err  error
+	temp       float64
+	thresholds temperature.Thresholds // nicDefaults if zero
+	err        error
 }
 
-func (m *mockTempReader) GetNICTemp(iface string) (*temperature.TempReading, error) {
+func (m *mockTempReader) GetNICTemp(_ context.Context, iface string) (*temperature.TempReading, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
-	return &temperature.TempReading{Label: iface, Value: m.temp}, nil
+	t := m.thresholds
+	if t == (temperature.Thresholds{}) {
+		t = nicDefaults
+	}
+	return &temperature.TempReading{Label: iface, Value: m.temp, Thresholds: t}, nil
 }
 
 // mockPerIfaceTempReader returns different results per interface name.
@@ -26,12 +40,12 @@ type mockPerIfaceTempReader struct {
 	errs  map[string]error
 }
 
-func (m *mockPerIfaceTempReader) GetNICTemp(iface string) (*temperature.TempReading, error) {
+func (m *mockPerIfaceTempReader) GetNICTemp(_ context.Context, iface string) (*temperature.TempReading, error) {
 	if err, ok := m.errs[iface]; ok && err != nil {
 		return nil, err
 	}
 	if val, ok := m.temps[iface]; ok {
-		return &temperature.TempReading{Label: iface, Value: val}, nil
+		return &temperature.TempReading{Label: iface, Value: val, Thresholds: nicDefaults}, nil
 	}
 	return nil, errors.New("unknown interface")
 }
@@ -53,40 +67,52 @@ func (m *mockNotifier) Send(title, message string, color notifier.Color, fields
 }
 
 type mockStateStore struct {
-	state MonitorState
+	states map[string]MonitorState
 }
 
-func (m *mockStateStore) Load() (MonitorState, error) {
-	if m.state.TempState == "" {
-		return MonitorState{TempState: StateNormal}, nil
+// newMockStateStore creates a store holding one interface state.
+func newMockStateStore(iface string, s MonitorState) *mockStateStore {
+	return &mockStateStore{states: map[string]MonitorState{iface: s}}
+}
+
+func (m *mockStateStore) Load() (map[string]MonitorState, error) {
+	states := make(map[string]MonitorState, len(m.states))
+	for k, v := range m.states {
+		states[k] = v
 	}
-	return m.state, nil
+	return states, nil
 }
 
-func (m *mockStateStore) Save(s MonitorState) error {
-	m.state = s
+func (m *mockStateStore) Save(s map[string]MonitorState) error {
+	m.states = s
 	return nil
 }
 
 type mockSpeedController struct {
-	limited  bool
-	restored bool
+	limited       bool
+	restored      bool
+	limitedIfaces []string
+	restoreIfaces []string
+	steps         []ThrottleStep
 }
 
-func (m *mockSpeedController) Limit(iface string) error {
+func (m *mockSpeedController) Limit(_ context.Context, iface string, step ThrottleStep) error {
 	m.limited = true
+	m.limitedIfaces = append(m.limitedIfaces, iface)
+	m.steps = append(m.steps, step)
 	return nil
 }
 
-func (m *mockSpeedController) Restore(iface string) error {
+func (m *mockSpeedController) Restore(_ context.Context, iface string) error {
 	m.restored = true
+	m.restoreIfaces = append(m.restoreIfaces, iface)
 	return nil
 }
 
 func TestNICMonitor_Check_Normal(t *testing.T) {
 	temp := &mockTempReader{temp: 50.0}
 	notif := &mockNotifier{}
-	store := &mockStateStore{state: MonitorState{TempState: StateNormal}}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateNormal})
 	speed := &mockSpeedController{}
 
 	m := NewNICMonitor(
@@ -96,7 +122,7 @@ func TestNICMonitor_Check_Normal(t *testing.T) {
 		WithSpeedController(speed),
 	)
 
-	err := m.Check()
+	err := m.Check(t.Context())
 	if err != nil {
 		t.Fatalf("Check() error = %v", err)
 	}
@@ -104,15 +130,15 @@ func TestNICMonitor_Check_Normal(t *testing.T) {
 	if len(notif.calls) != 0 {
 		t.Errorf("expected no notifications, got %d", len(notif.calls))
 	}
-	if store.state.TempState != StateNormal {
-		t.Errorf("state = %v, want %v", store.state.TempState, StateNormal)
+	if store.states["eth1"].TempState != StateNormal {
+		t.Errorf("state = %v, want %v", store.states["eth1"].TempState, StateNormal)
 	}
 }
 
 func TestNICMonitor_Check_NormalToWarning(t *testing.T) {
 	temp := &mockTempReader{temp: 75.0}
 	notif := &mockNotifier{}
-	store := &mockStateStore{state: MonitorState{TempState: StateNormal}}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateNormal})
 	speed := &mockSpeedController{}
 
 	m := NewNICMonitor(
@@ -122,7 +148,7 @@ func TestNICMonitor_Check_NormalToWarning(t *testing.T) {
 		WithSpeedController(speed),
 	)
 
-	err := m.Check()
+	err := m.Check(t.Context())
 	if err != nil {
 		t.Fatalf("Check() error = %v", err)
 	}
@@ -133,15 +159,51 @@ func TestNICMonitor_Check_NormalToWarning(t *testing.T) {
 	if notif.calls[0].color != notifier.ColorYellow {
 		t.Errorf("color = %v, want Yellow", notif.calls[0].color)
 	}
-	if store.state.TempState != StateWarning {
-		t.Errorf("state = %v, want %v", store.state.TempState, StateWarning)
+	if store.states["eth1"].TempState != StateWarning {
+		t.Errorf("state = %v, want %v", store.states["eth1"].TempState, StateWarning)
+	}
+}
+
+func TestNICMonitor_Check_CalibratedThresholds(t *testing.T) {
+	// The monitor uses the calibrated thresholds carried by the reading
+	temp := &mockTempReader{temp: 78.0, thresholds: temperature.Thresholds{Warning: 80, Critical: 95}}
+	notif := &mockNotifier{}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateNormal})
+
+	m := NewNICMonitor(
+		WithTempReader(temp),
+		WithNotifier(notif),
+		WithStateStore(store),
+		WithSpeedController(&mockSpeedController{}),
+	)
+
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(notif.calls) != 0 {
+		t.Fatalf("78°C is below the calibrated warning, got %d notifications", len(notif.calls))
+	}
+
+	temp.temp = 90.0
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if store.states["eth1"].TempState != StateWarning {
+		t.Fatalf("state = %v, want %v", store.states["eth1"].TempState, StateWarning)
+	}
+	if len(notif.calls) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(notif.calls))
+	}
+	fields := notif.calls[0].fields
+	if fields[2].Value != "80°C" || fields[3].Value != "95°C" {
+		t.Errorf("threshold fields = %+v, want calibrated 80/95", fields)
 	}
 }
 
 func TestNICMonitor_Check_NormalToCritical(t *testing.T) {
 	temp := &mockTempReader{temp: 90.0}
 	notif := &mockNotifier{}
-	store := &mockStateStore{state: MonitorState{TempState: StateNormal}}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateNormal})
 	speed := &mockSpeedController{}
 
 	m := NewNICMonitor(
@@ -151,7 +213,7 @@ func TestNICMonitor_Check_NormalToCritical(t *testing.T) {
 		WithSpeedController(speed),
 	)
 
-	err := m.Check()
+	err := m.Check(t.Context())
 	if err != nil {
 		t.Fatalf("Check() error = %v", err)
 	}
@@ -162,13 +224,13 @@ func TestNICMonitor_Check_NormalToCritical(t *testing.T) {
 	if notif.calls[0].color != notifier.ColorRed {
 		t.Errorf("color = %v, want Red", notif.calls[0].color)
 	}
-	if store.state.TempState != StateCritical {
-		t.Errorf("state = %v, want %v", store.state.TempState, StateCritical)
+	if store.states["eth1"].TempState != StateCritical {
+		t.Errorf("state = %v, want %v", store.states["eth1"].TempState, StateCritical)
 	}
 	if !speed.limited {
 		t.Error("expected speed to be limited")
 	}
-	if !store.state.SpeedLimited {
+	if !store.states["eth1"].SpeedLimited {
 		t.Error("expected SpeedLimited to be true")
 	}
 }
@@ -176,7 +238,7 @@ func TestNICMonitor_Check_NormalToCritical(t *testing.T) {
 func TestNICMonitor_Check_CriticalToNormal(t *testing.T) {
 	temp := &mockTempReader{temp: 60.0} // below recovery threshold
 	notif := &mockNotifier{}
-	store := &mockStateStore{state: MonitorState{TempState: StateCritical, SpeedLimited: true}}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateCritical, SpeedLimited: true})
 	speed := &mockSpeedController{}
 
 	m := NewNICMonitor(
@@ -186,7 +248,7 @@ func TestNICMonitor_Check_CriticalToNormal(t *testing.T) {
 		WithSpeedController(speed),
 	)
 
-	err := m.Check()
+	err := m.Check(t.Context())
 	if err != nil {
 		t.Fatalf("Check() error = %v", err)
 	}
@@ -197,13 +259,13 @@ func TestNICMonitor_Check_CriticalToNormal(t *testing.T) {
 	if notif.calls[0].color != notifier.ColorGreen {
 		t.Errorf("color = %v, want Green", notif.calls[0].color)
 	}
-	if store.state.TempState != StateNormal {
-		t.Errorf("state = %v, want %v", store.state.TempState, StateNormal)
+	if store.states["eth1"].TempState != StateNormal {
+		t.Errorf("state = %v, want %v", store.states["eth1"].TempState, StateNormal)
 	}
 	if !speed.restored {
 		t.Error("expected speed to be restored")
 	}
-	if store.state.SpeedLimited {
+	if store.states["eth1"].SpeedLimited {
 		t.Error("expected SpeedLimited to be false")
 	}
 }
@@ -212,7 +274,7 @@ func TestNICMonitor_Check_CriticalToNormal_AboveRecovery(t *testing.T) {
 	// Temperature between recovery(65) and warning(70) - should NOT restore speed yet
 	temp := &mockTempReader{temp: 67.0}
 	notif := &mockNotifier{}
-	store := &mockStateStore{state: MonitorState{TempState: StateCritical, SpeedLimited: true}}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateCritical, SpeedLimited: true})
 	speed := &mockSpeedController{}
 
 	m := NewNICMonitor(
@@ -222,7 +284,7 @@ func TestNICMonitor_Check_CriticalToNormal_AboveRecovery(t *testing.T) {
 		WithSpeedController(speed),
 	)
 
-	err := m.Check()
+	err := m.Check(t.Context())
 	if err != nil {
 		t.Fatalf("Check() error = %v", err)
 	}
@@ -235,10 +297,10 @@ func TestNICMonitor_Check_CriticalToNormal_AboveRecovery(t *testing.T) {
 		t.Error("speed should NOT be restored above recovery threshold")
 	}
 	// State transitions to Normal but speed stays limited
-	if store.state.TempState != StateNormal {
-		t.Errorf("state = %v, want %v", store.state.TempState, StateNormal)
+	if store.states["eth1"].TempState != StateNormal {
+		t.Errorf("state = %v, want %v", store.states["eth1"].TempState, StateNormal)
 	}
-	if !store.state.SpeedLimited {
+	if !store.states["eth1"].SpeedLimited {
 		t.Error("SpeedLimited should remain true")
 	}
 }
@@ -247,7 +309,7 @@ func TestNICMonitor_Check_SpeedRestoreAfterRecovery(t *testing.T) {
 	// After temp drops below recovery, speed should be restored even if TempState is already Normal
 	temp := &mockTempReader{temp: 60.0}
 	notif := &mockNotifier{}
-	store := &mockStateStore{state: MonitorState{TempState: StateNormal, SpeedLimited: true}}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateNormal, SpeedLimited: true})
 	speed := &mockSpeedController{}
 
 	m := NewNICMonitor(
@@ -257,7 +319,7 @@ func TestNICMonitor_Check_SpeedRestoreAfterRecovery(t *testing.T) {
 		WithSpeedController(speed),
 	)
 
-	err := m.Check()
+	err := m.Check(t.Context())
 	if err != nil {
 		t.Fatalf("Check() error = %v", err)
 	}
@@ -265,7 +327,7 @@ func TestNICMonitor_Check_SpeedRestoreAfterRecovery(t *testing.T) {
 	if !speed.restored {
 		t.Error("expected speed to be restored")
 	}
-	if store.state.SpeedLimited {
+	if store.states["eth1"].SpeedLimited {
 		t.Error("expected SpeedLimited to be false")
 	}
 	if len(notif.calls) != 1 {
@@ -279,7 +341,7 @@ func TestNICMonitor_Check_SpeedRestoreAfterRecovery(t *testing.T) {
 func TestNICMonitor_Check_WarningToNormal(t *testing.T) {
 	temp := &mockTempReader{temp: 50.0}
 	notif := &mockNotifier{}
-	store := &mockStateStore{state: MonitorState{TempState: StateWarning}}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateWarning})
 	speed := &mockSpeedController{}
 
 	m := NewNICMonitor(
@@ -289,7 +351,7 @@ func TestNICMonitor_Check_WarningToNormal(t *testing.T) {
 		WithSpeedController(speed),
 	)
 
-	err := m.Check()
+	err := m.Check(t.Context())
 	if err != nil {
 		t.Fatalf("Check() error = %v", err)
 	}
@@ -300,8 +362,8 @@ func TestNICMonitor_Check_WarningToNormal(t *testing.T) {
 	if notif.calls[0].color != notifier.ColorGreen {
 		t.Errorf("color = %v, want Green", notif.calls[0].color)
 	}
-	if store.state.TempState != StateNormal {
-		t.Errorf("state = %v, want %v", store.state.TempState, StateNormal)
+	if store.states["eth1"].TempState != StateNormal {
+		t.Errorf("state = %v, want %v", store.states["eth1"].TempState, StateNormal)
 	}
 }
 
@@ -318,12 +380,46 @@ func TestNICMonitor_Check_TempReadError(t *testing.T) {
 		WithSpeedController(speed),
 	)
 
-	err := m.Check()
+	err := m.Check(t.Context())
 	if err == nil {
 		t.Error("expected error for sensor failure")
 	}
 }
 
+// hungTempReader blocks like a hung sensor command until ctx is done
+type hungTempReader struct{ mockTempReader }
+
+func (h *hungTempReader) GetNICTemp(ctx context.Context, iface string) (*temperature.TempReading, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestNICMonitor_Check_HungSensorCanceled(t *testing.T) {
+	store := newMockStateStore("eth1", MonitorState{TempState: StateNormal})
+	speed := &mockSpeedController{}
+	m := NewNICMonitor(
+		WithTempReader(&hungTempReader{}),
+		WithNotifier(&mockNotifier{}),
+		WithStateStore(store),
+		WithSpeedController(speed),
+	)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	if err := m.Check(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Check() error = %v, want the check deadline", err)
+	}
+
+	// The hung read released the check lock
+	store.states["eth1"] = MonitorState{TempState: StateCritical, SpeedLimited: true, ThrottleLevel: 1}
+	if err := m.RestoreAll(t.Context()); err != nil {
+		t.Fatalf("RestoreAll() error = %v", err)
+	}
+	if !speed.restored {
+		t.Error("expected the speed limit lifted after the hung check")
+	}
+}
+
 func TestNICMonitor_Check_SensorUnavailable(t *testing.T) {
 	temp := &mockTempReader{err: temperature.ErrSensorUnavailable}
 	notif := &mockNotifier{}
@@ -337,7 +433,7 @@ func TestNICMonitor_Check_SensorUnavailable(t *testing.T) {
 		WithSpeedController(speed),
 	)
 
-	err := m.Check()
+	err := m.Check(t.Context())
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -367,7 +463,7 @@ func TestNICMonitor_Check_MixedErrors_RealErrorPriority(t *testing.T) {
 		WithInterface("eth1,eth2"),
 	)
 
-	err := m.Check()
+	err := m.Check(t.Context())
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -386,7 +482,7 @@ func TestNICMonitor_Check_MixedErrors_OneSuccess(t *testing.T) {
 		errs:  map[string]error{"eth1": temperature.ErrSensorUnavailable},
 	}
 	notif := &mockNotifier{}
-	store := &mockStateStore{state: MonitorState{TempState: StateNormal}}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateNormal})
 	speed := &mockSpeedController{}
 
 	m := NewNICMonitor(
@@ -397,11 +493,524 @@ func TestNICMonitor_Check_MixedErrors_OneSuccess(t *testing.T) {
 		WithInterface("eth1,eth2"),
 	)
 
-	err := m.Check()
+	err := m.Check(t.Context())
 	if err != nil {
 		t.Fatalf("expected no error (one NIC succeeded), got %v", err)
 	}
-	if store.state.TempState != StateNormal {
-		t.Errorf("state = %v, want Normal", store.state.TempState)
+	if store.states["eth2"].TempState != StateNormal {
+		t.Errorf("state = %v, want Normal", store.states["eth2"].TempState)
+	}
+}
+
+func TestNICMonitor_Check_PerInterfaceRestore(t *testing.T) {
+	// eth1 is limited and cooling down while eth2 becomes the hottest NIC.
+	// eth1 must still be restored and the notification must name eth1.
+	temp := &mockPerIfaceTempReader{
+		temps: map[string]float64{"eth1": 60.0, "eth2": 75.0},
+	}
+	notif := &mockNotifier{}
+	store := &mockStateStore{states: map[string]MonitorState{
+		"eth1": {TempState: StateCritical, SpeedLimited: true},
+		"eth2": {TempState: StateNormal},
+	}}
+	speed := &mockSpeedController{}
+
+	m := NewNICMonitor(
+		WithTempReader(temp),
+		WithNotifier(notif),
+		WithStateStore(store),
+		WithSpeedController(speed),
+		WithInterface("eth1,eth2"),
+	)
+
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	if len(speed.restoreIfaces) != 1 || speed.restoreIfaces[0] != "eth1" {
+		t.Errorf("restored = %v, want [eth1]", speed.restoreIfaces)
+	}
+	if len(speed.limitedIfaces) != 0 {
+		t.Errorf("limited = %v, want none", speed.limitedIfaces)
+	}
+	if store.states["eth1"].SpeedLimited {
+		t.Error("eth1 SpeedLimited should be false")
+	}
+	if store.states["eth2"].TempState != StateWarning {
+		t.Errorf("eth2 state = %v, want %v", store.states["eth2"].TempState, StateWarning)
+	}
+	if len(notif.calls) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(notif.calls))
+	}
+	if !strings.Contains(notif.calls[0].message, "eth1") || notif.calls[0].color != notifier.ColorGreen {
+		t.Errorf("first notification = %+v, want eth1 recovery", notif.calls[0])
+	}
+	if !strings.Contains(notif.calls[1].message, "eth2") || notif.calls[1].color != notifier.ColorYellow {
+		t.Errorf("second notification = %+v, want eth2 warning", notif.calls[1])
+	}
+}
+
+func TestNICMonitor_Check_BothInterfacesCritical(t *testing.T) {
+	temp := &mockPerIfaceTempReader{
+		temps: map[string]float64{"eth1": 90.0, "eth2": 88.0},
+	}
+	notif := &mockNotifier{}
+	store := &mockStateStore{}
+	speed := &mockSpeedController{}
+
+	m := NewNICMonitor(
+		WithTempReader(temp),
+		WithNotifier(notif),
+		WithStateStore(store),
+		WithSpeedController(speed),
+		WithInterface("eth1,eth2"),
+	)
+
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	if len(speed.limitedIfaces) != 2 {
+		t.Fatalf("limited = %v, want [eth1 eth2]", speed.limitedIfaces)
+	}
+	for _, iface := range []string{"eth1", "eth2"} {
+		if !store.states[iface].SpeedLimited {
+			t.Errorf("%s SpeedLimited should be true", iface)
+		}
+		if store.states[iface].TempState != StateCritical {
+			t.Errorf("%s state = %v, want %v", iface, store.states[iface].TempState, StateCritical)
+		}
+	}
+}
+
+func TestNICMonitor_Check_LegacyStateMigrated(t *testing.T) {
+	// A migrated single-state entry belongs to the first interface only; the
+	// others were never throttled
+	temp := &mockPerIfaceTempReader{
+		temps: map[string]float64{"eth1": 60.0, "eth2": 60.0},
+	}
+	notif := &mockNotifier{}
+	store := newMockStateStore(legacyStateKey, MonitorState{TempState: StateCritical, SpeedLimited: true})
+	speed := &mockSpeedController{}
+
+	m := NewNICMonitor(
+		WithTempReader(temp),
+		WithNotifier(notif),
+		WithStateStore(store),
+		WithSpeedController(speed),
+		WithInterface("eth1,eth2"),
+	)
+
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(speed.restoreIfaces, []string{"eth1"}) {
+		t.Errorf("restored = %v, want [eth1]", speed.restoreIfaces)
+	}
+	if len(notif.calls) != 1 {
+		t.Errorf("notifications = %d, want 1 recovery for eth1", len(notif.calls))
+	}
+	if got := store.states["eth2"]; got.TempState != StateNormal || got.SpeedLimited {
+		t.Errorf("eth2 state = %+v, want normal and unlimited", got)
+	}
+	if _, ok := store.states[legacyStateKey]; ok {
+		t.Error("legacy state key should not be saved")
+	}
+}
+
+// fakeClock returns a time that advances by step on each call.
+type fakeClock struct {
+	now  time.Time
+	step time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	t := c.now
+	c.now = c.now.Add(c.step)
+	return t
+}
+
+func newHoldTestMonitor(temp *mockTempReader, notif *mockNotifier, store *mockStateStore, speed *mockSpeedController, opts ...NICOption) *NICMonitor {
+	clock := &fakeClock{now: time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC), step: time.Minute}
+	base := []NICOption{
+		WithTempReader(temp),
+		WithNotifier(notif),
+		WithStateStore(store),
+		WithSpeedController(speed),
+		WithNowFunc(clock.Now),
+	}
+	return NewNICMonitor(append(base, opts...)...)
+}
+
+func TestNICMonitor_Check_CriticalHoldSamples(t *testing.T) {
+	temp := &mockTempReader{temp: 90.0}
+	notif := &mockNotifier{}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateNormal})
+	speed := &mockSpeedController{}
+
+	m := newHoldTestMonitor(temp, notif, store, speed,
+		WithHoldConditions(TransitionHolds{Critical: HoldCondition{Samples: 3}}),
+	)
+
+	// First two samples: warning only, no throttle
+	for i := 0; i < 2; i++ {
+		if err := m.Check(t.Context()); err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+		if speed.limited {
+			t.Fatalf("sample %d: speed limited before hold satisfied", i+1)
+		}
+	}
+	if store.states["eth1"].TempState != StateWarning {
+		t.Errorf("state = %v, want %v", store.states["eth1"].TempState, StateWarning)
+	}
+
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !speed.limited {
+		t.Error("expected speed to be limited after 3 samples")
+	}
+	if store.states["eth1"].TempState != StateCritical {
+		t.Errorf("state = %v, want %v", store.states["eth1"].TempState, StateCritical)
+	}
+}
+
+func TestNICMonitor_Check_NoisySampleResetsHold(t *testing.T) {
+	temp := &mockTempReader{}
+	notif := &mockNotifier{}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateNormal})
+	speed := &mockSpeedController{}
+
+	m := newHoldTestMonitor(temp, notif, store, speed,
+		WithHoldConditions(TransitionHolds{
+			Warning:  HoldCondition{Samples: 2},
+			Critical: HoldCondition{Samples: 2},
+		}),
+	)
+
+	for _, v := range []float64{90, 50, 90, 50} {
+		temp.temp = v
+		if err := m.Check(t.Context()); err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+	}
+
+	if speed.limited {
+		t.Error("isolated spikes should not limit speed")
+	}
+	if len(notif.calls) != 0 {
+		t.Errorf("expected no notifications, got %d", len(notif.calls))
+	}
+}
+
+func TestNICMonitor_Check_RecoveryHoldDuration(t *testing.T) {
+	temp := &mockTempReader{temp: 60.0}
+	notif := &mockNotifier{}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateCritical, SpeedLimited: true})
+	speed := &mockSpeedController{}
+
+	m := newHoldTestMonitor(temp, notif, store, speed,
+		WithHoldConditions(TransitionHolds{Recovery: HoldCondition{Duration: 3 * time.Minute}}),
+	)
+
+	// Samples at t=0,1,2 minutes: held for 2 minutes only
+	for i := 0; i < 3; i++ {
+		if err := m.Check(t.Context()); err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+	}
+	if speed.restored {
+		t.Fatal("speed restored before recovery duration elapsed")
+	}
+
+	// t=3 minutes: held for 3 minutes
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !speed.restored {
+		t.Error("expected speed to be restored")
+	}
+	if store.states["eth1"].SpeedLimited {
+		t.Error("expected SpeedLimited to be false")
+	}
+}
+
+func TestNICMonitor_Check_HoldSurvivesRestart(t *testing.T) {
+	temp := &mockTempReader{temp: 90.0}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateNormal})
+	holds := WithHoldConditions(TransitionHolds{Critical: HoldCondition{Samples: 2}})
+
+	first := newHoldTestMonitor(temp, &mockNotifier{}, store, &mockSpeedController{}, holds)
+	if err := first.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	// A new monitor instance picks up the persisted sample history
+	speed := &mockSpeedController{}
+	second := newHoldTestMonitor(temp, &mockNotifier{}, store, speed, holds)
+	if err := second.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !speed.limited {
+		t.Error("expected speed to be limited using persisted samples")
+	}
+}
+
+func TestNICMonitor_Check_RiseAlert(t *testing.T) {
+	temp := &mockTempReader{}
+	notif := &mockNotifier{}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateNormal})
+	speed := &mockSpeedController{}
+
+	m := newHoldTestMonitor(temp, notif, store, speed,
+		WithRiseAlert(RiseAlert{Delta: 5, Window: 5 * time.Minute}),
+	)
+
+	for _, v := range []float64{55, 57, 61, 62} {
+		temp.temp = v
+		if err := m.Check(t.Context()); err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+	}
+
+	if len(notif.calls) != 1 {
+		t.Fatalf("expected 1 rise notification, got %d", len(notif.calls))
+	}
+	if !strings.Contains(notif.calls[0].title, "急上昇") {
+		t.Errorf("title = %q, want rise alert", notif.calls[0].title)
+	}
+	if !store.states["eth1"].RiseAlerted {
+		t.Error("expected RiseAlerted to be true")
+	}
+	if store.states["eth1"].TempState != StateNormal {
+		t.Errorf("state = %v, want %v", store.states["eth1"].TempState, StateNormal)
+	}
+}
+
+func TestNICMonitor_RecordSample_TrimsHistory(t *testing.T) {
+	m := NewNICMonitor(WithHoldConditions(TransitionHolds{Critical: HoldCondition{Samples: 3}}))
+	base := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	var samples []TempSample
+	for i := 0; i < 10; i++ {
+		samples = m.recordSample(samples, base.Add(time.Duration(i)*time.Minute), float64(i))
+	}
+
+	if len(samples) != 3 {
+		t.Fatalf("len(samples) = %d, want 3", len(samples))
+	}
+	if samples[2].Value != 9 {
+		t.Errorf("newest sample = %v, want 9", samples[2].Value)
+	}
+}
+
+// detectingSpeedController reports a fixed set of supported speeds and
+// whether the speed is forced.
+type detectingSpeedController struct {
+	mockSpeedController
+	speeds     []int
+	pinned     bool
+	detections int
+}
+
+func (d *detectingSpeedController) SupportedSpeeds(context.Context, string) ([]int, error) {
+	d.detections++
+	return d.speeds, nil
+}
+
+func (d *detectingSpeedController) SpeedPinned(context.Context, string) (bool, error) {
+	return d.pinned, nil
+}
+
+func TestNICMonitor_LadderDetectedOnce(t *testing.T) {
+	speed := &detectingSpeedController{speeds: []int{100, 1000, 10000}}
+	m := NewNICMonitor(
+		WithTempReader(&mockPerIfaceTempReader{temps: map[string]float64{"eth1": 50, "eth2": 50}}),
+		WithNotifier(&mockNotifier{}),
+		WithStateStore(newMockStateStore("eth1", MonitorState{TempState: StateNormal})),
+		WithSpeedController(speed),
+		WithInterface("eth1,eth2"),
+	)
+	for range 3 {
+		if err := m.Check(t.Context()); err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+	}
+	if speed.detections != 2 {
+		t.Errorf("SupportedSpeeds called %d times, want once per interface", speed.detections)
+	}
+}
+
+func TestNICMonitor_Check_ThrottleLadder(t *testing.T) {
+	temp := &mockTempReader{temp: 90.0}
+	notif := &mockNotifier{}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateNormal})
+	speed := &detectingSpeedController{speeds: []int{100, 1000, 10000}}
+
+	clock := &fakeClock{now: time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC), step: time.Minute}
+	m := NewNICMonitor(
+		WithTempReader(temp),
+		WithNotifier(notif),
+		WithStateStore(store),
+		WithSpeedController(speed),
+		WithNowFunc(clock.Now),
+		WithThrottlePolicy(ThrottlePolicy{
+			Ladder:        []ThrottleStep{5000, 2500, 1000, StepAdminDown},
+			StepDownAfter: 2 * time.Minute,
+			StepUpAfter:   2 * time.Minute,
+		}),
+	)
+
+	// t=0..4 critical: 1Gbps at t=0, admin-down at t=2, ladder exhausted afterwards
+	for i := 0; i < 5; i++ {
+		if err := m.Check(t.Context()); err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+	}
+	wantDown := []ThrottleStep{1000, StepAdminDown}
+	if !reflect.DeepEqual(speed.steps, wantDown) {
+		t.Fatalf("steps = %v, want %v (5G/2.5G unsupported)", speed.steps, wantDown)
+	}
+	if got := store.states["eth1"].ThrottleLevel; got != 2 {
+		t.Errorf("ThrottleLevel = %d, want 2", got)
+	}
+
+	// t=5..8 recovered: step up to 1Gbps at t=5, full restore at t=7
+	temp.temp = 60.0
+	for i := 0; i < 4; i++ {
+		if err := m.Check(t.Context()); err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+	}
+	wantAll := []ThrottleStep{1000, StepAdminDown, 1000}
+	if !reflect.DeepEqual(speed.steps, wantAll) {
+		t.Errorf("steps = %v, want %v", speed.steps, wantAll)
+	}
+	if len(speed.restoreIfaces) != 1 {
+		t.Errorf("restore calls = %d, want 1", len(speed.restoreIfaces))
+	}
+	if store.states["eth1"].SpeedLimited || store.states["eth1"].ThrottleLevel != 0 {
+		t.Errorf("state = %+v, want unthrottled", store.states["eth1"])
+	}
+}
+
+func TestNICMonitor_Check_LegacySpeedLimitedRestores(t *testing.T) {
+	// SpeedLimited without ThrottleLevel is treated as one ladder step
+	temp := &mockTempReader{temp: 60.0}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateNormal, SpeedLimited: true})
+	speed := &mockSpeedController{}
+
+	m := NewNICMonitor(
+		WithTempReader(temp),
+		WithNotifier(&mockNotifier{}),
+		WithStateStore(store),
+		WithSpeedController(speed),
+		WithThrottlePolicy(ThrottlePolicy{Ladder: []ThrottleStep{2500, 1000}}),
+	)
+
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !speed.restored {
+		t.Error("expected speed to be restored")
+	}
+}
+
+func TestNICMonitor_Reconfigure(t *testing.T) {
+	temp := &mockTempReader{temp: 90.0}
+	notif := &mockNotifier{}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateNormal})
+	speed := &mockSpeedController{}
+	clock := &fakeClock{now: time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC), step: time.Minute}
+	opts := func(samples int, extra ...NICOption) []NICOption {
+		return append([]NICOption{
+			WithTempReader(temp),
+			WithNotifier(notif),
+			WithStateStore(store),
+			WithSpeedController(speed),
+			WithNowFunc(clock.Now),
+			WithInterface("eth1,eth2"),
+			WithHoldConditions(TransitionHolds{Critical: HoldCondition{Samples: samples}}),
+		}, extra...)
+	}
+
+	m := NewNICMonitor(opts(3)...)
+	for range 2 {
+		if err := m.Check(t.Context()); err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+	}
+	if speed.limited {
+		t.Fatal("speed limited before hold satisfied")
+	}
+
+	// Samples recorded under the old hold count toward the new one
+	m.Reconfigure(opts(5, WithInterface("eth1"))...)
+	if _, ok := m.LastReadings()["eth2"]; ok {
+		t.Error("reading of an interface no longer monitored was kept")
+	}
+	if _, ok := m.LastReadings()["eth1"]; !ok {
+		t.Error("reading of eth1 was dropped")
+	}
+	for range 3 {
+		if err := m.Check(t.Context()); err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+	}
+	if !speed.limited {
+		t.Error("expected speed to be limited after 5 samples across the reload")
+	}
+
+	// Dry-run wraps the kept speed controller instead of the old wrapper
+	m.Reconfigure(opts(1, WithDryRun(true))...)
+	if !m.DryRun() {
+		t.Error("DryRun() = false after enabling it")
+	}
+	speed.limited = false
+	temp.temp = 40.0
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if speed.restored {
+		t.Error("dry-run restored the NIC")
+	}
+	if last := notif.calls[len(notif.calls)-1]; !strings.HasPrefix(last.title, dryRunTitlePrefix) {
+		t.Errorf("last title = %q, want dry-run prefix", last.title)
+	}
+}
+
+func TestNICMonitor_Reconfigure_KeepsSavedAdvertising(t *testing.T) {
+	// The operator advertises only 1000baseT/Full | 10000baseT/Full
+	advertising := ethtool.Bitmap{1<<5 | 1<<12, 0, 0}
+	client := &fakeEthtoolClient{up: true, settings: ethtool.LinkSettings{
+		Speed: 10000, Autoneg: true, Supported: x540Bitmap, Advertising: advertising,
+	}}
+	temp := &mockTempReader{temp: 90.0}
+	store := newMockStateStore("eth1", MonitorState{TempState: StateNormal})
+	opts := []NICOption{
+		WithTempReader(temp),
+		WithNotifier(&mockNotifier{}),
+		WithStateStore(store),
+	}
+	m := NewNICMonitor(append(opts, WithSpeedController(NewNativeSpeedControllerWith(client, NewEthtoolSpeedControllerWith(&captureCommandRunner{}))))...)
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !store.states["eth1"].SpeedLimited {
+		t.Fatal("expected eth1 to be throttled")
+	}
+	client.settings = client.applied[len(client.applied)-1]
+
+	// A reload builds a fresh controller; the one holding the saved modes is kept
+	m.Reconfigure(append(opts, WithSpeedController(NewNativeSpeedController()))...)
+	temp.temp = 40.0
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	got := client.applied[len(client.applied)-1]
+	if !got.Autoneg || !reflect.DeepEqual(got.Advertising, advertising) {
+		t.Errorf("applied = %+v, want the advertising saved before the reload", got)
 	}
 }
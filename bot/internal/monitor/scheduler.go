@@ -0,0 +1,240 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check is a periodic task run by the Scheduler
+type Check interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+// checkFunc adapts a function to Check
+type checkFunc struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+func (c *checkFunc) Name() string                  { return c.name }
+func (c *checkFunc) Run(ctx context.Context) error { return c.run(ctx) }
+
+// NewCheck returns a Check that calls run
+func NewCheck(name string, run func(ctx context.Context) error) Check {
+	return &checkFunc{name: name, run: run}
+}
+
+// Schedule is when a check runs and how long one run may take
+type Schedule struct {
+	Interval     time.Duration // between the starts of two runs
+	StartupDelay time.Duration // before the first run
+	Jitter       time.Duration // random delay of up to Jitter added to every run
+	Timeout      time.Duration // deadline of one run; 0 means Interval
+}
+
+// timeout returns the deadline of one run
+func (s Schedule) timeout() time.Duration {
+	if s.Timeout > 0 {
+		return s.Timeout
+	}
+	return s.Interval
+}
+
+// ErrCheckRunning is reported when a check is due while its previous run,
+// abandoned at its deadline, has not returned yet
+var ErrCheckRunning = errors.New("previous run still in progress")
+
+// scheduledCheck is one registered check
+type scheduledCheck struct {
+	check   Check
+	mu      sync.Mutex // guards sched
+	sched   Schedule
+	reset   chan struct{} // wakes the loop after Reschedule
+	running atomic.Bool   // set until Run returns, even after the deadline
+}
+
+func (e *scheduledCheck) schedule() Schedule {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.sched
+}
+
+// Scheduler runs every registered check on its own schedule and goroutine,
+// so a slow or hung check never delays the others. Runs of one check never
+// overlap: a run that is still going when the next is due delays it, and a
+// run abandoned at its deadline blocks new runs until it returns. Missed
+// runs are not made up.
+type Scheduler struct {
+	entries  []*scheduledCheck
+	onResult func(name string, err error)
+	resultMu sync.Mutex // serializes onResult
+	jitter   func(limit time.Duration) time.Duration
+}
+
+// SchedulerOption configures Scheduler
+type SchedulerOption func(*Scheduler)
+
+// WithSchedulerResult sets the function called with the outcome of every
+// run (nil on success). Calls never overlap, so it may use state that is
+// not safe for concurrent use, such as an ErrorSuppressor.
+func WithSchedulerResult(f func(name string, err error)) SchedulerOption {
+	return func(s *Scheduler) {
+		s.onResult = f
+	}
+}
+
+// WithSchedulerJitter sets the source of random delays (for testing)
+func WithSchedulerJitter(f func(limit time.Duration) time.Duration) SchedulerOption {
+	return func(s *Scheduler) {
+		s.jitter = f
+	}
+}
+
+// NewScheduler creates a scheduler without checks
+func NewScheduler(opts ...SchedulerOption) *Scheduler {
+	s := &Scheduler{
+		onResult: func(string, error) {},
+		jitter:   randomJitter,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// randomJitter returns a random duration in [0, limit)
+func randomJitter(limit time.Duration) time.Duration {
+	if limit <= 0 {
+		return 0
+	}
+	return rand.N(limit)
+}
+
+// Register adds a check. Checks must be registered before Run.
+func (s *Scheduler) Register(c Check, sched Schedule) error {
+	if sched.Interval <= 0 {
+		return fmt.Errorf("check %s: interval must be greater than zero", c.Name())
+	}
+	if s.find(c.Name()) != nil {
+		return fmt.Errorf("check %s is already registered", c.Name())
+	}
+	s.entries = append(s.entries, &scheduledCheck{
+		check: c,
+		sched: sched,
+		reset: make(chan struct{}, 1),
+	})
+	return nil
+}
+
+// Reschedule changes the schedule of a registered check. A new interval
+// counts from the start of the last run; the startup delay only matters
+// before the first run.
+func (s *Scheduler) Reschedule(name string, sched Schedule) error {
+	e := s.find(name)
+	if e == nil {
+		return fmt.Errorf("check %s is not registered", name)
+	}
+	if sched.Interval <= 0 {
+		return fmt.Errorf("check %s: interval must be greater than zero", name)
+	}
+	e.mu.Lock()
+	e.sched = sched
+	e.mu.Unlock()
+	select {
+	case e.reset <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (s *Scheduler) find(name string) *scheduledCheck {
+	for _, e := range s.entries {
+		if e.check.Name() == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// Run runs the checks until ctx is done. Runs still going at that point
+// see ctx canceled; Run does not wait for the ones that ignore it.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, e := range s.entries {
+		wg.Go(func() { s.loop(ctx, e) })
+	}
+	wg.Wait()
+}
+
+// loop runs one check on its schedule
+func (s *Scheduler) loop(ctx context.Context, e *scheduledCheck) {
+	sched := e.schedule()
+	timer := time.NewTimer(sched.StartupDelay + s.jitter(sched.Jitter))
+	defer timer.Stop()
+
+	var lastStart time.Time
+	next := func() time.Duration {
+		return max(time.Until(lastStart.Add(sched.Interval)), 0) + s.jitter(sched.Jitter)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.reset:
+			sched = e.schedule()
+			if !lastStart.IsZero() {
+				timer.Reset(next())
+			}
+		case <-timer.C:
+			lastStart = time.Now()
+			s.runOnce(ctx, e, sched)
+			if ctx.Err() != nil {
+				return
+			}
+			sched = e.schedule()
+			timer.Reset(next())
+		}
+	}
+}
+
+// runOnce runs a check with its deadline and reports the result
+func (s *Scheduler) runOnce(ctx context.Context, e *scheduledCheck, sched Schedule) {
+	name := e.check.Name()
+	if !e.running.CompareAndSwap(false, true) {
+		s.report(name, ErrCheckRunning)
+		return
+	}
+
+	timeout := sched.timeout()
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		err := e.check.Run(runCtx)
+		e.running.Store(false)
+		done <- err
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-runCtx.Done():
+		if ctx.Err() != nil {
+			return // shutting down
+		}
+		err = fmt.Errorf("no result within %s: %w", timeout, context.DeadlineExceeded)
+	}
+	s.report(name, err)
+}
+
+func (s *Scheduler) report(name string, err error) {
+	s.resultMu.Lock()
+	defer s.resultMu.Unlock()
+	s.onResult(name, err)
+}
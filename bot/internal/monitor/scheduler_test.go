@@ -0,0 +1,230 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+)
+
+// recordedResults collects the results reported by a scheduler
+type recordedResults struct {
+	mu      sync.Mutex
+	results map[string][]error
+}
+
+func (r *recordedResults) record(name string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.results == nil {
+		r.results = make(map[string][]error)
+	}
+	r.results[name] = append(r.results[name], err)
+}
+
+func (r *recordedResults) get(name string) []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]error(nil), r.results[name]...)
+}
+
+// runScheduler runs s for d of fake time
+func runScheduler(s *Scheduler, d time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	time.Sleep(d)
+	cancel()
+	<-done
+}
+
+func TestScheduler_Intervals(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var results recordedResults
+		s := NewScheduler(WithSchedulerResult(results.record))
+
+		var fastRuns, slowRuns []time.Duration
+		start := time.Now()
+		if err := s.Register(NewCheck("fast", func(context.Context) error {
+			fastRuns = append(fastRuns, time.Since(start))
+			return nil
+		}), Schedule{Interval: time.Minute}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Register(NewCheck("slow", func(context.Context) error {
+			slowRuns = append(slowRuns, time.Since(start))
+			return errors.New("failed")
+		}), Schedule{Interval: time.Hour, StartupDelay: 10 * time.Second}); err != nil {
+			t.Fatal(err)
+		}
+
+		runScheduler(s, 150*time.Second)
+
+		wantFast := []time.Duration{0, time.Minute, 2 * time.Minute}
+		if len(fastRuns) != len(wantFast) {
+			t.Fatalf("fast runs at %v, want %v", fastRuns, wantFast)
+		}
+		for i, want := range wantFast {
+			if fastRuns[i] != want {
+				t.Errorf("fast run %d at %v, want %v", i, fastRuns[i], want)
+			}
+		}
+		if len(slowRuns) != 1 || slowRuns[0] != 10*time.Second {
+			t.Errorf("slow runs at %v, want [10s]", slowRuns)
+		}
+		if got := results.get("fast"); len(got) != 3 || got[0] != nil {
+			t.Errorf("fast results = %v", got)
+		}
+		if got := results.get("slow"); len(got) != 1 || got[0] == nil {
+			t.Errorf("slow results = %v, want the error", got)
+		}
+	})
+}
+
+func TestScheduler_Jitter(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		s := NewScheduler(WithSchedulerJitter(func(limit time.Duration) time.Duration {
+			return limit / 2
+		}))
+		var runs []time.Duration
+		start := time.Now()
+		s.Register(NewCheck("c", func(context.Context) error {
+			runs = append(runs, time.Since(start))
+			return nil
+		}), Schedule{Interval: time.Minute, StartupDelay: 5 * time.Second, Jitter: 10 * time.Second})
+
+		runScheduler(s, 90*time.Second)
+
+		// Jitter delays every run, including the first
+		want := []time.Duration{10 * time.Second, 75 * time.Second}
+		if len(runs) != 2 || runs[0] != want[0] || runs[1] != want[1] {
+			t.Errorf("runs at %v, want %v", runs, want)
+		}
+	})
+}
+
+func TestScheduler_TimeoutAndNoOverlap(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var results recordedResults
+		s := NewScheduler(WithSchedulerResult(results.record))
+
+		release := make(chan struct{})
+		var mu sync.Mutex
+		running, maxRunning, runs := 0, 0, 0
+		s.Register(NewCheck("hung", func(ctx context.Context) error {
+			mu.Lock()
+			running++
+			runs++
+			maxRunning = max(maxRunning, running)
+			first := runs == 1
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				running--
+				mu.Unlock()
+			}()
+			if first {
+				<-release // ignores its deadline, like a hung ioctl
+			}
+			return nil
+		}), Schedule{Interval: time.Minute, Timeout: 10 * time.Second})
+
+		var otherRuns int
+		s.Register(NewCheck("other", func(context.Context) error {
+			otherRuns++
+			return nil
+		}), Schedule{Interval: time.Minute})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			s.Run(ctx)
+			close(done)
+		}()
+		time.Sleep(90 * time.Second)
+		close(release)
+		time.Sleep(60 * time.Second)
+		cancel()
+		<-done
+
+		got := results.get("hung")
+		if len(got) != 3 {
+			t.Fatalf("hung results = %v, want 3", got)
+		}
+		if !errors.Is(got[0], context.DeadlineExceeded) {
+			t.Errorf("first result = %v, want deadline exceeded", got[0])
+		}
+		if !errors.Is(got[1], ErrCheckRunning) {
+			t.Errorf("second result = %v, want ErrCheckRunning", got[1])
+		}
+		if got[2] != nil {
+			t.Errorf("third result = %v, want success after the hung run returned", got[2])
+		}
+		if maxRunning != 1 {
+			t.Errorf("max concurrent runs = %d, want 1", maxRunning)
+		}
+		// The hung check does not hold up the others
+		if otherRuns != 3 {
+			t.Errorf("other runs = %d, want 3", otherRuns)
+		}
+	})
+}
+
+func TestScheduler_Reschedule(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		s := NewScheduler()
+		var runs []time.Duration
+		start := time.Now()
+		s.Register(NewCheck("c", func(context.Context) error {
+			runs = append(runs, time.Since(start))
+			return nil
+		}), Schedule{Interval: time.Hour})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			s.Run(ctx)
+			close(done)
+		}()
+		time.Sleep(30 * time.Second)
+		if err := s.Reschedule("c", Schedule{Interval: time.Minute}); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(100 * time.Second)
+		cancel()
+		<-done
+
+		// The new interval counts from the start of the last run
+		want := []time.Duration{0, time.Minute, 2 * time.Minute}
+		if len(runs) != len(want) {
+			t.Fatalf("runs at %v, want %v", runs, want)
+		}
+		for i := range want {
+			if runs[i] != want[i] {
+				t.Errorf("run %d at %v, want %v", i, runs[i], want[i])
+			}
+		}
+		if err := s.Reschedule("missing", Schedule{Interval: time.Minute}); err == nil {
+			t.Error("Reschedule() of an unknown check succeeded")
+		}
+	})
+}
+
+func TestScheduler_Register(t *testing.T) {
+	s := NewScheduler()
+	c := NewCheck("nic", func(context.Context) error { return nil })
+	if err := s.Register(c, Schedule{Interval: time.Minute}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := s.Register(c, Schedule{Interval: time.Minute}); err == nil {
+		t.Error("duplicate Register() succeeded")
+	}
+	if err := s.Register(NewCheck("zero", nil), Schedule{}); err == nil {
+		t.Error("Register() without an interval succeeded")
+	}
+}
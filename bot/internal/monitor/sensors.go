@@ -0,0 +1,167 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
+)
+
+// sensorReader reads hwmon sensors (fans, voltages, power, current, temps)
+type sensorReader interface {
+	GetHwmonSensors() ([]temperature.SensorReading, error)
+}
+
+// GetHwmonSensors returns all hwmon readings
+func (a *TempAdapter) GetHwmonSensors() ([]temperature.SensorReading, error) {
+	return temperature.GetHwmonSensors()
+}
+
+// sensorState is the alert state of one hwmon attribute
+type sensorState int
+
+const (
+	sensorOK sensorState = iota
+	sensorAlarm
+	sensorStalled
+)
+
+func (s sensorState) text() string {
+	switch s {
+	case sensorAlarm:
+		return "アラーム"
+	case sensorStalled:
+		return "停止"
+	default:
+		return "正常"
+	}
+}
+
+// SensorMonitor alerts on hwmon alarm flags and stalled fans
+type SensorMonitor struct {
+	reader   sensorReader
+	notifier notifier.Notifier
+	hostname string
+	states   map[string]sensorState // chip/attr → last reported state
+	lastRPM  map[string]float64
+}
+
+// SensorOption configures SensorMonitor
+type SensorOption func(*SensorMonitor)
+
+// WithSensorReader sets the hwmon reader
+func WithSensorReader(r sensorReader) SensorOption {
+	return func(m *SensorMonitor) {
+		m.reader = r
+	}
+}
+
+// WithSensorNotifier sets the notifier
+func WithSensorNotifier(n notifier.Notifier) SensorOption {
+	return func(m *SensorMonitor) {
+		m.notifier = n
+	}
+}
+
+// NewSensorMonitor creates a new hwmon sensor monitor
+func NewSensorMonitor(opts ...SensorOption) *SensorMonitor {
+	hostname, _ := os.Hostname()
+	m := &SensorMonitor{
+		hostname: hostname,
+		states:   make(map[string]sensorState),
+		lastRPM:  make(map[string]float64),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// sensorChange is a reading whose state differs from the last report
+type sensorChange struct {
+	reading temperature.SensorReading
+	prev    sensorState
+	state   sensorState
+}
+
+// Check reads all sensors and notifies on state changes.
+// A fan is stalled when it reads 0 RPM below a non-zero minimum, or when
+// it drops to 0 RPM after having been seen spinning. A check whose ctx is
+// done by the time the sensors are read does not notify.
+func (m *SensorMonitor) Check(ctx context.Context) error {
+	readings, err := m.reader.GetHwmonSensors()
+	if err != nil {
+		return fmt.Errorf("read sensors: %w", err)
+	}
+
+	var changes []sensorChange
+	for _, r := range readings {
+		key := r.Key()
+		state := m.stateOf(r)
+		if r.Kind == temperature.KindFan && r.Value > 0 {
+			m.lastRPM[key] = r.Value
+		}
+		if prev := m.states[key]; prev != state {
+			changes = append(changes, sensorChange{reading: r, prev: prev, state: state})
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := m.notify(changes); err != nil {
+		return fmt.Errorf("send notification: %w", err)
+	}
+	// Record only after a successful notification so failures are retried
+	for _, c := range changes {
+		m.states[c.reading.Key()] = c.state
+	}
+	return nil
+}
+
+func (m *SensorMonitor) stateOf(r temperature.SensorReading) sensorState {
+	if r.Kind == temperature.KindFan && r.Value == 0 && (r.Stalled() || m.lastRPM[r.Key()] > 0) {
+		return sensorStalled
+	}
+	if r.Alarm {
+		return sensorAlarm
+	}
+	return sensorOK
+}
+
+func (m *SensorMonitor) notify(changes []sensorChange) error {
+	worst := sensorOK
+	var lines []string
+	for _, c := range changes {
+		worst = max(worst, c.state)
+		lines = append(lines, fmt.Sprintf("%s %s: %s (%s → %s)",
+			c.reading.Chip, c.reading.Name(), c.reading.FormatValue(), c.prev.text(), c.state.text()))
+	}
+
+	var title, message string
+	var color notifier.Color
+	switch worst {
+	case sensorStalled:
+		title = fmt.Sprintf("🌀 ファン停止 - %s", m.hostname)
+		message = "ファンの停止を検知しました。"
+		color = notifier.ColorRed
+	case sensorAlarm:
+		title = fmt.Sprintf("⚠️ センサーアラーム - %s", m.hostname)
+		message = "ハードウェアセンサーがアラームを報告しました。"
+		color = notifier.ColorYellow
+	default:
+		title = fmt.Sprintf("✅ センサー正常化 - %s", m.hostname)
+		message = "ハードウェアセンサーが正常に戻りました。"
+		color = notifier.ColorGreen
+	}
+
+	return m.notifier.Send(title, message, color, []notifier.Field{
+		{Name: "Sensors", Value: strings.Join(lines, "\n")},
+	})
+}
@@ -0,0 +1,103 @@
+package monitor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
+)
+
+type mockSensorReader struct {
+	readings []temperature.SensorReading
+	err      error
+}
+
+func (r *mockSensorReader) GetHwmonSensors() ([]temperature.SensorReading, error) {
+	return r.readings, r.err
+}
+
+func fanReading(rpm float64, alarm bool) temperature.SensorReading {
+	return temperature.SensorReading{Chip: "nct6775", Attr: "fan1", Kind: temperature.KindFan, Label: "CPU Fan", Value: rpm, Alarm: alarm}
+}
+
+func TestSensorMonitor_Check_StalledFan(t *testing.T) {
+	reader := &mockSensorReader{readings: []temperature.SensorReading{fanReading(1200, false)}}
+	notif := &mockNotifier{}
+	m := NewSensorMonitor(WithSensorReader(reader), WithSensorNotifier(notif))
+
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(notif.calls) != 0 {
+		t.Fatalf("spinning fan should not notify, got %d", len(notif.calls))
+	}
+
+	// Fan seen spinning drops to 0 without min limit → stalled
+	reader.readings = []temperature.SensorReading{fanReading(0, false)}
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(notif.calls) != 1 || notif.calls[0].color != notifier.ColorRed {
+		t.Fatalf("expected stalled alert, got %+v", notif.calls)
+	}
+	if !strings.Contains(notif.calls[0].fields[0].Value, "nct6775 CPU Fan: 0 RPM (正常 → 停止)") {
+		t.Errorf("field = %q", notif.calls[0].fields[0].Value)
+	}
+
+	// Still stalled: no repeat
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(notif.calls) != 1 {
+		t.Errorf("expected no repeat, got %d", len(notif.calls))
+	}
+
+	reader.readings = []temperature.SensorReading{fanReading(1100, false)}
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(notif.calls) != 2 || notif.calls[1].color != notifier.ColorGreen {
+		t.Errorf("expected recovery, got %+v", notif.calls)
+	}
+}
+
+func TestSensorMonitor_Check_UnusedFanHeaderIgnored(t *testing.T) {
+	// 0 RPM from the start with no min limit: empty header, not a stall
+	reader := &mockSensorReader{readings: []temperature.SensorReading{fanReading(0, false)}}
+	notif := &mockNotifier{}
+	m := NewSensorMonitor(WithSensorReader(reader), WithSensorNotifier(notif))
+
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(notif.calls) != 0 {
+		t.Errorf("unused fan header should not alert, got %+v", notif.calls)
+	}
+}
+
+func TestSensorMonitor_Check_Alarm(t *testing.T) {
+	vmin := 1.0
+	vcore := temperature.SensorReading{Chip: "nct6775", Attr: "in0", Kind: temperature.KindVoltage, Label: "Vcore", Value: 0.8, Min: &vmin, Alarm: true}
+	reader := &mockSensorReader{readings: []temperature.SensorReading{vcore, fanReading(1200, false)}}
+	notif := &mockNotifier{}
+	m := NewSensorMonitor(WithSensorReader(reader), WithSensorNotifier(notif))
+
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(notif.calls) != 1 || notif.calls[0].color != notifier.ColorYellow {
+		t.Fatalf("expected alarm notification, got %+v", notif.calls)
+	}
+	if !strings.Contains(notif.calls[0].fields[0].Value, "Vcore: 0.800 V") {
+		t.Errorf("field = %q", notif.calls[0].fields[0].Value)
+	}
+}
+
+func TestSensorMonitor_Check_ReaderError(t *testing.T) {
+	m := NewSensorMonitor(WithSensorReader(&mockSensorReader{err: errors.New("boom")}), WithSensorNotifier(&mockNotifier{}))
+	if err := m.Check(t.Context()); err == nil {
+		t.Error("expected error")
+	}
+}
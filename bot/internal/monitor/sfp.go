@@ -0,0 +1,174 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+	"github.com/murata-lab/pervigil/bot/internal/sfp"
+)
+
+// sfpReader reads optical module diagnostics
+type sfpReader interface {
+	Diagnostics(ctx context.Context, iface string) (*sfp.Diagnostics, error)
+}
+
+// SFPAdapter adapts the sfp package for monitor use
+type SFPAdapter struct{}
+
+// NewSFPAdapter creates a new SFP diagnostics adapter
+func NewSFPAdapter() *SFPAdapter {
+	return &SFPAdapter{}
+}
+
+// Diagnostics returns the DOM diagnostics of the module in iface
+func (a *SFPAdapter) Diagnostics(ctx context.Context, iface string) (*sfp.Diagnostics, error) {
+	return sfp.Get(ctx, iface)
+}
+
+// SFPMonitor alerts when optical module readings cross the
+// module-reported warning/alarm thresholds
+type SFPMonitor struct {
+	reader   sfpReader
+	notifier notifier.Notifier
+	ifaces   []string
+	hostname string
+	levels   map[string]sfp.Level // iface/metric key → last reported level
+}
+
+// SFPOption configures SFPMonitor
+type SFPOption func(*SFPMonitor)
+
+// WithSFPReader sets the diagnostics reader
+func WithSFPReader(r sfpReader) SFPOption {
+	return func(m *SFPMonitor) {
+		m.reader = r
+	}
+}
+
+// WithSFPNotifier sets the notifier
+func WithSFPNotifier(n notifier.Notifier) SFPOption {
+	return func(m *SFPMonitor) {
+		m.notifier = n
+	}
+}
+
+// WithSFPInterface sets the monitored interfaces (comma-separated)
+func WithSFPInterface(ifaces string) SFPOption {
+	return func(m *SFPMonitor) {
+		m.ifaces = splitInterfaces(ifaces)
+	}
+}
+
+// NewSFPMonitor creates a new SFP diagnostics monitor
+func NewSFPMonitor(opts ...SFPOption) *SFPMonitor {
+	hostname, _ := os.Hostname()
+	m := &SFPMonitor{
+		ifaces:   []string{"eth1"},
+		hostname: hostname,
+		levels:   make(map[string]sfp.Level),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// sfpChange is a reading whose level differs from the last report
+type sfpChange struct {
+	reading sfp.Reading
+	prev    sfp.Level
+	level   sfp.Level
+}
+
+// Check reads every module and notifies on level changes.
+// Ports without a module or without DOM support are skipped.
+func (m *SFPMonitor) Check(ctx context.Context) error {
+	var errs []error
+	for _, iface := range m.ifaces {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		diag, err := m.reader.Diagnostics(ctx, iface)
+		if errors.Is(err, sfp.ErrNoDiagnostics) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", iface, err))
+			continue
+		}
+
+		var changes []sfpChange
+		for _, r := range diag.Readings {
+			key := iface + "/" + r.Key()
+			level := r.Level()
+			if prev := m.levels[key]; prev != level {
+				changes = append(changes, sfpChange{reading: r, prev: prev, level: level})
+			}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		if err := m.notify(diag, changes); err != nil {
+			errs = append(errs, fmt.Errorf("%s: send notification: %w", iface, err))
+			continue
+		}
+		// Record only after a successful notification so failures are retried
+		for _, c := range changes {
+			m.levels[iface+"/"+c.reading.Key()] = c.level
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *SFPMonitor) notify(diag *sfp.Diagnostics, changes []sfpChange) error {
+	worst := sfp.LevelOK
+	var lines []string
+	for _, c := range changes {
+		worst = max(worst, c.level)
+		lines = append(lines, fmt.Sprintf("%s: %s (%s → %s)",
+			c.reading.Label(), sfp.FormatValue(c.reading), sfpLevelText(c.prev), sfpLevelText(c.level)))
+	}
+
+	var title, message string
+	var color notifier.Color
+	switch worst {
+	case sfp.LevelAlarm:
+		title = fmt.Sprintf("🔦 光モジュール警報 - %s", m.hostname)
+		message = fmt.Sprintf("NIC(%s)の光モジュールがモジュール既定の警報閾値を超えました。", diag.Interface)
+		color = notifier.ColorRed
+	case sfp.LevelWarning:
+		title = fmt.Sprintf("⚠️ 光モジュール警告 - %s", m.hostname)
+		message = fmt.Sprintf("NIC(%s)の光モジュールがモジュール既定の警告閾値を超えました。", diag.Interface)
+		color = notifier.ColorYellow
+	default:
+		title = fmt.Sprintf("✅ 光モジュール正常化 - %s", m.hostname)
+		message = fmt.Sprintf("NIC(%s)の光モジュールの値が正常範囲に戻りました。", diag.Interface)
+		color = notifier.ColorGreen
+	}
+
+	fields := []notifier.Field{
+		{Name: "Interface", Value: diag.Interface, Inline: true},
+	}
+	if module := diag.Module(); module != "" {
+		fields = append(fields, notifier.Field{Name: "Module", Value: module, Inline: true})
+	}
+	fields = append(fields, notifier.Field{Name: "Readings", Value: strings.Join(lines, "\n")})
+
+	return m.notifier.Send(title, message, color, fields)
+}
+
+// sfpLevelText returns the Japanese label of a DOM level
+func sfpLevelText(l sfp.Level) string {
+	switch l {
+	case sfp.LevelAlarm:
+		return "警報"
+	case sfp.LevelWarning:
+		return "警告"
+	default:
+		return "正常"
+	}
+}
@@ -0,0 +1,107 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+	"github.com/murata-lab/pervigil/bot/internal/sfp"
+)
+
+type mockSFPReader struct {
+	diags map[string]*sfp.Diagnostics
+	errs  map[string]error
+}
+
+func (r *mockSFPReader) Diagnostics(_ context.Context, iface string) (*sfp.Diagnostics, error) {
+	if err, ok := r.errs[iface]; ok {
+		return nil, err
+	}
+	if d, ok := r.diags[iface]; ok {
+		return d, nil
+	}
+	return nil, fmt.Errorf("%s: %w", iface, sfp.ErrNoDiagnostics)
+}
+
+var rxThresholds = sfp.Thresholds{HighAlarm: 0, HighWarning: -1, LowWarning: -18, LowAlarm: -20, Known: true}
+
+func rxDiag(iface string, rx float64) *sfp.Diagnostics {
+	return &sfp.Diagnostics{
+		Interface:  iface,
+		Vendor:     "FINISAR CORP.",
+		PartNumber: "FTLX8571D3BCL",
+		Readings: []sfp.Reading{
+			{Metric: sfp.MetricRxPower, Value: rx, Thresholds: rxThresholds},
+		},
+	}
+}
+
+func TestSFPMonitor_Check_Transitions(t *testing.T) {
+	reader := &mockSFPReader{diags: map[string]*sfp.Diagnostics{"eth1": rxDiag("eth1", -5)}}
+	notif := &mockNotifier{}
+	m := NewSFPMonitor(WithSFPReader(reader), WithSFPNotifier(notif), WithSFPInterface("eth1"))
+
+	steps := []struct {
+		rx    float64
+		calls int
+		color notifier.Color
+	}{
+		{-5, 0, 0},                     // normal: no notification
+		{-19, 1, notifier.ColorYellow}, // warning
+		{-19, 1, 0},                    // unchanged: no repeat
+		{-25, 2, notifier.ColorRed},    // alarm
+		{-5, 3, notifier.ColorGreen},   // recovered
+	}
+	for i, s := range steps {
+		reader.diags["eth1"] = rxDiag("eth1", s.rx)
+		if err := m.Check(t.Context()); err != nil {
+			t.Fatalf("step %d: Check() error = %v", i, err)
+		}
+		if len(notif.calls) != s.calls {
+			t.Fatalf("step %d: expected %d notifications, got %d", i, s.calls, len(notif.calls))
+		}
+		if s.color != 0 && notif.calls[len(notif.calls)-1].color != s.color {
+			t.Errorf("step %d: color = %v, want %v", i, notif.calls[len(notif.calls)-1].color, s.color)
+		}
+	}
+
+	alarm := notif.calls[1]
+	if !strings.Contains(alarm.fields[2].Value, "Rx power: -25.00 dBm (警告 → 警報)") {
+		t.Errorf("readings field = %q", alarm.fields[2].Value)
+	}
+	if alarm.fields[1].Value != "FINISAR CORP. FTLX8571D3BCL" {
+		t.Errorf("module field = %q", alarm.fields[1].Value)
+	}
+}
+
+func TestSFPMonitor_Check_SkipsPortsWithoutModule(t *testing.T) {
+	reader := &mockSFPReader{diags: map[string]*sfp.Diagnostics{"eth2": rxDiag("eth2", -25)}}
+	notif := &mockNotifier{}
+	m := NewSFPMonitor(WithSFPReader(reader), WithSFPNotifier(notif), WithSFPInterface("eth0,eth2"))
+
+	if err := m.Check(t.Context()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(notif.calls) != 1 || !strings.Contains(notif.calls[0].message, "eth2") {
+		t.Errorf("expected one alarm for eth2, got %+v", notif.calls)
+	}
+}
+
+func TestSFPMonitor_Check_ReaderError(t *testing.T) {
+	reader := &mockSFPReader{
+		diags: map[string]*sfp.Diagnostics{"eth2": rxDiag("eth2", -25)},
+		errs:  map[string]error{"eth1": errors.New("boom")},
+	}
+	notif := &mockNotifier{}
+	m := NewSFPMonitor(WithSFPReader(reader), WithSFPNotifier(notif), WithSFPInterface("eth1,eth2"))
+
+	if err := m.Check(t.Context()); err == nil {
+		t.Error("expected error from failing interface")
+	}
+	if len(notif.calls) != 1 {
+		t.Errorf("other interfaces should still be checked, got %d notifications", len(notif.calls))
+	}
+}
@@ -1,54 +1,263 @@
 package monitor
 
 import (
+	"context"
+	"fmt"
 	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/murata-lab/pervigil/bot/internal/ethtool"
 )
 
-// commandRunner abstracts command execution
+// commandRunner abstracts command execution; commands are killed when
+// ctx is done
 type commandRunner interface {
-	Run(name string, args ...string) error
+	Run(ctx context.Context, name string, args ...string) error
+	Output(ctx context.Context, name string, args ...string) ([]byte, error)
 }
 
 // osCommandRunner is the production implementation
 type osCommandRunner struct{}
 
-func (r *osCommandRunner) Run(name string, args ...string) error {
-	return exec.Command(name, args...).Run()
+func (r *osCommandRunner) Run(ctx context.Context, name string, args ...string) error {
+	return exec.CommandContext(ctx, name, args...).Run()
+}
+
+func (r *osCommandRunner) Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).Output()
 }
 
-const (
-	// advertiseX540 is the bitmask for Intel X540-T2 (ixgbe) supported link modes:
-	//
-	//	0x0020 = 1000baseT/Full
-	//	0x1000 = 10000baseT/Full
-	advertiseX540 = "0x1020"
+// advertiseX540 is the fallback bitmask for Intel X540-T2 (ixgbe) link modes,
+// used when the supported link modes cannot be detected:
+//
+//	0x0020 = 1000baseT/Full
+//	0x1000 = 10000baseT/Full
+const advertiseX540 = "0x1020"
 
-	// speedLimitMbps is the NIC speed limit in Mbps applied during thermal throttling.
-	speedLimitMbps = "1000"
-)
+var linkModeSpeedRe = regexp.MustCompile(`^(\d+)base`)
 
 // EthtoolSpeedController controls NIC speed using ethtool
 type EthtoolSpeedController struct {
 	runner    commandRunner
 	advertise string
+
+	mu         sync.Mutex
+	advertised map[string]string // advertise mask in effect before the first limit
 }
 
 // NewEthtoolSpeedController creates a new ethtool-based speed controller
 func NewEthtoolSpeedController() *EthtoolSpeedController {
-	return &EthtoolSpeedController{runner: &osCommandRunner{}, advertise: advertiseX540}
+	return NewEthtoolSpeedControllerWith(&osCommandRunner{})
 }
 
 // NewEthtoolSpeedControllerWith creates a controller with custom runner (for testing)
 func NewEthtoolSpeedControllerWith(runner commandRunner) *EthtoolSpeedController {
-	return &EthtoolSpeedController{runner: runner, advertise: advertiseX540}
+	return &EthtoolSpeedController{runner: runner, advertise: advertiseX540, advertised: make(map[string]string)}
+}
+
+// Limit applies a throttle step: a fixed link speed or taking the link down.
+// The advertised link modes are saved first so Restore can bring them back.
+func (c *EthtoolSpeedController) Limit(ctx context.Context, iface string, step ThrottleStep) error {
+	c.saveAdvertised(ctx, iface)
+	if step == StepAdminDown {
+		return c.runner.Run(ctx, "ip", "link", "set", "dev", iface, "down")
+	}
+	if err := c.ensureUp(ctx, iface); err != nil {
+		return err
+	}
+	return c.runner.Run(ctx, "ethtool", "-s", iface, "speed", strconv.Itoa(int(step)), "duplex", "full", "autoneg", "off")
+}
+
+// Restore enables auto-negotiation with the link modes advertised before
+// the first Limit, or every supported mode if none were saved (e.g. after
+// a restart).
+func (c *EthtoolSpeedController) Restore(ctx context.Context, iface string) error {
+	if err := c.ensureUp(ctx, iface); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	advertise, saved := c.advertised[iface]
+	c.mu.Unlock()
+	if !saved {
+		advertise = c.advertise
+		if modes, err := c.supportedModes(ctx, iface); err == nil {
+			if mask := advertiseMask(modes); mask != "" {
+				advertise = mask
+			}
+		}
+	}
+	if err := c.runner.Run(ctx, "ethtool", "-s", iface, "autoneg", "on", "advertise", advertise); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	delete(c.advertised, iface)
+	c.mu.Unlock()
+	return nil
+}
+
+// SpeedPinned reports whether auto-negotiation is off or advertises less
+// than the fastest supported mode.
+func (c *EthtoolSpeedController) SpeedPinned(ctx context.Context, iface string) (bool, error) {
+	out, err := c.runner.Output(ctx, "ethtool", iface)
+	if err != nil {
+		return false, err
+	}
+	autoneg, ok := parseAutoneg(string(out))
+	if !ok {
+		return false, fmt.Errorf("no auto-negotiation state in ethtool output for %s", iface)
+	}
+	if !autoneg {
+		return true, nil
+	}
+	supported := linkModeSpeeds(parseLinkModes(string(out), "Supported link modes:"))
+	advertised := linkModeSpeeds(parseLinkModes(string(out), "Advertised link modes:"))
+	return advertisingRestricted(supported, advertised), nil
+}
+
+// SupportedSpeeds returns the link speeds (Mbps) the NIC supports.
+func (c *EthtoolSpeedController) SupportedSpeeds(ctx context.Context, iface string) ([]int, error) {
+	modes, err := c.supportedModes(ctx, iface)
+	if err != nil {
+		return nil, err
+	}
+	speeds := linkModeSpeeds(modes)
+	if len(speeds) == 0 {
+		return nil, fmt.Errorf("no link speeds detected for %s", iface)
+	}
+	return speeds, nil
+}
+
+// ensureUp brings the link back up if a previous throttle step took it down.
+func (c *EthtoolSpeedController) ensureUp(ctx context.Context, iface string) error {
+	out, err := c.runner.Output(ctx, "ip", "-o", "link", "show", "dev", iface)
+	if err != nil || linkAdminUp(string(out)) {
+		return nil
+	}
+	return c.runner.Run(ctx, "ip", "link", "set", "dev", iface, "up")
+}
+
+// saveAdvertised remembers the advertise mask of iface unless one is saved
+// already or the speed is forced, so a ladder of limits keeps the original.
+func (c *EthtoolSpeedController) saveAdvertised(ctx context.Context, iface string) {
+	c.mu.Lock()
+	_, saved := c.advertised[iface]
+	c.mu.Unlock()
+	if saved {
+		return
+	}
+	out, err := c.runner.Output(ctx, "ethtool", iface)
+	if err != nil {
+		return
+	}
+	if autoneg, ok := parseAutoneg(string(out)); !ok || !autoneg {
+		return
+	}
+	if mask := advertiseMask(parseLinkModes(string(out), "Advertised link modes:")); mask != "" {
+		c.mu.Lock()
+		c.advertised[iface] = mask
+		c.mu.Unlock()
+	}
+}
+
+func (c *EthtoolSpeedController) supportedModes(ctx context.Context, iface string) ([]string, error) {
+	out, err := c.runner.Output(ctx, "ethtool", iface)
+	if err != nil {
+		return nil, err
+	}
+	modes := parseLinkModes(string(out), "Supported link modes:")
+	if len(modes) == 0 {
+		return nil, fmt.Errorf("no supported link modes in ethtool output for %s", iface)
+	}
+	return modes, nil
+}
+
+// linkAdminUp reports whether `ip -o link show` output has the UP flag.
+func linkAdminUp(out string) bool {
+	start := strings.Index(out, "<")
+	end := strings.Index(out, ">")
+	if start < 0 || end < start {
+		return true
+	}
+	for _, flag := range strings.Split(out[start+1:end], ",") {
+		if flag == "UP" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseLinkModes extracts a link mode list such as "Supported link modes:"
+// from `ethtool <iface>` output.
+func parseLinkModes(out, key string) []string {
+	var modes []string
+	inModes := false
+	for _, line := range strings.Split(out, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(trimmed, key); ok {
+			inModes = true
+			trimmed = rest
+		} else if inModes && strings.Contains(trimmed, ":") {
+			break
+		}
+		if inModes {
+			modes = append(modes, strings.Fields(trimmed)...)
+		}
+	}
+	return modes
+}
+
+// parseAutoneg reads the "Auto-negotiation: on|off" line of `ethtool <iface>`
+// output. ok is false when the line is missing.
+func parseAutoneg(out string) (on, ok bool) {
+	for _, line := range strings.Split(out, "\n") {
+		if v, found := strings.CutPrefix(strings.TrimSpace(line), "Auto-negotiation:"); found {
+			return strings.TrimSpace(v) == "on", true
+		}
+	}
+	return false, false
+}
+
+// linkModeSpeeds returns the distinct speeds (Mbps) of link mode names
+// such as "1000baseT/Full", in order of appearance.
+func linkModeSpeeds(modes []string) []int {
+	var speeds []int
+	for _, mode := range modes {
+		m := linkModeSpeedRe.FindStringSubmatch(mode)
+		if m == nil {
+			continue
+		}
+		speed, _ := strconv.Atoi(m[1])
+		if !slices.Contains(speeds, speed) {
+			speeds = append(speeds, speed)
+		}
+	}
+	return speeds
 }
 
-// Limit sets the NIC speed to 1Gbps
-func (c *EthtoolSpeedController) Limit(iface string) error {
-	return c.runner.Run("ethtool", "-s", iface, "speed", speedLimitMbps, "duplex", "full", "autoneg", "off")
+// advertisingRestricted reports whether the advertised speeds stop below
+// the fastest supported one. Unknown lists count as unrestricted.
+func advertisingRestricted(supported, advertised []int) bool {
+	if len(supported) == 0 || len(advertised) == 0 {
+		return false
+	}
+	return slices.Max(advertised) < slices.Max(supported)
 }
 
-// Restore enables auto-negotiation with advertised link modes for the NIC.
-func (c *EthtoolSpeedController) Restore(iface string) error {
-	return c.runner.Run("ethtool", "-s", iface, "autoneg", "on", "advertise", c.advertise)
+// advertiseMask builds an ethtool advertise bitmask from link mode names.
+// Unknown modes are ignored; an empty string means no known mode was found.
+func advertiseMask(modes []string) string {
+	var mask uint64
+	for _, mode := range modes {
+		if lm, ok := ethtool.LinkModeByName(mode); ok {
+			mask |= 1 << lm.Bit
+		}
+	}
+	if mask == 0 {
+		return ""
+	}
+	return fmt.Sprintf("0x%x", mask)
 }
@@ -0,0 +1,169 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/murata-lab/pervigil/bot/internal/ethtool"
+)
+
+// ethtoolClient abstracts kernel ethtool requests
+type ethtoolClient interface {
+	LinkSettings(iface string) (*ethtool.LinkSettings, error)
+	SetLinkSettings(iface string, ls *ethtool.LinkSettings) error
+	LinkUp(iface string) (bool, error)
+	SetLinkUp(iface string, up bool) error
+}
+
+// speedFallback is a SpeedController that can also report supported speeds
+// and whether the speed is forced
+type speedFallback interface {
+	SpeedController
+	speedDetector
+	pinDetector
+}
+
+// NativeSpeedController controls NIC speed through the SIOCETHTOOL ioctl
+// and falls back to the ethtool binary when the kernel request fails.
+type NativeSpeedController struct {
+	client   ethtoolClient
+	fallback speedFallback
+
+	mu          sync.Mutex
+	advertising map[string]ethtool.Bitmap // advertising in effect before the first limit
+}
+
+// NewNativeSpeedController creates a kernel-based speed controller
+// with an ethtool binary fallback
+func NewNativeSpeedController() *NativeSpeedController {
+	return NewNativeSpeedControllerWith(ethtool.New(), NewEthtoolSpeedController())
+}
+
+// NewNativeSpeedControllerWith creates a controller with custom client and fallback (for testing)
+func NewNativeSpeedControllerWith(client ethtoolClient, fallback speedFallback) *NativeSpeedController {
+	return &NativeSpeedController{client: client, fallback: fallback, advertising: make(map[string]ethtool.Bitmap)}
+}
+
+// Limit applies a throttle step: a fixed link speed or taking the link down.
+// The advertised link modes are saved first so Restore can bring them back.
+func (c *NativeSpeedController) Limit(ctx context.Context, iface string, step ThrottleStep) error {
+	c.saveAdvertising(iface)
+	nerr := c.limitNative(iface, step)
+	if nerr == nil {
+		return nil
+	}
+	if err := c.fallback.Limit(ctx, iface, step); err != nil {
+		return fmt.Errorf("native: %v; ethtool: %w", nerr, err)
+	}
+	return nil
+}
+
+// Restore enables auto-negotiation with the link modes advertised before
+// the first Limit, or every supported mode if none were saved (e.g. after
+// a restart).
+func (c *NativeSpeedController) Restore(ctx context.Context, iface string) error {
+	nerr := c.restoreNative(iface)
+	if nerr == nil {
+		return nil
+	}
+	if err := c.fallback.Restore(ctx, iface); err != nil {
+		return fmt.Errorf("native: %v; ethtool: %w", nerr, err)
+	}
+	return nil
+}
+
+// SupportedSpeeds returns the link speeds (Mbps) the NIC supports.
+func (c *NativeSpeedController) SupportedSpeeds(ctx context.Context, iface string) ([]int, error) {
+	ls, err := c.client.LinkSettings(iface)
+	if err == nil {
+		if speeds := ls.Supported.Speeds(); len(speeds) > 0 {
+			return speeds, nil
+		}
+	}
+	return c.fallback.SupportedSpeeds(ctx, iface)
+}
+
+// SpeedPinned reports whether auto-negotiation is off or advertises less
+// than the fastest supported mode.
+func (c *NativeSpeedController) SpeedPinned(ctx context.Context, iface string) (bool, error) {
+	ls, err := c.client.LinkSettings(iface)
+	if err != nil {
+		return c.fallback.SpeedPinned(ctx, iface)
+	}
+	return !ls.Autoneg || advertisingRestricted(ls.Supported.Speeds(), ls.Advertising.Speeds()), nil
+}
+
+// saveAdvertising remembers the advertising mask of iface unless one is
+// saved already or the speed is forced, so a ladder of limits keeps the
+// original.
+func (c *NativeSpeedController) saveAdvertising(iface string) {
+	c.mu.Lock()
+	_, saved := c.advertising[iface]
+	c.mu.Unlock()
+	if saved {
+		return
+	}
+	ls, err := c.client.LinkSettings(iface)
+	if err != nil || !ls.Autoneg || len(ls.Advertising.Speeds()) == 0 {
+		return
+	}
+	c.mu.Lock()
+	c.advertising[iface] = slices.Clone(ls.Advertising)
+	c.mu.Unlock()
+}
+
+func (c *NativeSpeedController) limitNative(iface string, step ThrottleStep) error {
+	if step == StepAdminDown {
+		return c.client.SetLinkUp(iface, false)
+	}
+	if err := c.ensureUp(iface); err != nil {
+		return err
+	}
+	ls, err := c.client.LinkSettings(iface)
+	if err != nil {
+		return err
+	}
+	ls.Speed = uint32(step)
+	ls.Duplex = ethtool.DuplexFull
+	ls.Autoneg = false
+	return c.client.SetLinkSettings(iface, ls)
+}
+
+func (c *NativeSpeedController) restoreNative(iface string) error {
+	if err := c.ensureUp(iface); err != nil {
+		return err
+	}
+	ls, err := c.client.LinkSettings(iface)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	advertising, saved := c.advertising[iface]
+	c.mu.Unlock()
+	if !saved {
+		advertising = ls.Supported
+	}
+	ls.Autoneg = true
+	ls.Advertising = advertising
+	if err := c.client.SetLinkSettings(iface, ls); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	delete(c.advertising, iface)
+	c.mu.Unlock()
+	return nil
+}
+
+// ensureUp brings the link back up if a previous throttle step took it down.
+func (c *NativeSpeedController) ensureUp(iface string) error {
+	up, err := c.client.LinkUp(iface)
+	if err != nil {
+		return err
+	}
+	if up {
+		return nil
+	}
+	return c.client.SetLinkUp(iface, true)
+}
@@ -0,0 +1,183 @@
+package monitor
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/murata-lab/pervigil/bot/internal/ethtool"
+)
+
+// fakeEthtoolClient records link settings applied through the kernel path.
+type fakeEthtoolClient struct {
+	settings ethtool.LinkSettings
+	up       bool
+	applied  []ethtool.LinkSettings
+	err      error
+}
+
+func (f *fakeEthtoolClient) LinkSettings(string) (*ethtool.LinkSettings, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	ls := f.settings
+	return &ls, nil
+}
+
+func (f *fakeEthtoolClient) SetLinkSettings(_ string, ls *ethtool.LinkSettings) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.applied = append(f.applied, *ls)
+	return nil
+}
+
+func (f *fakeEthtoolClient) LinkUp(string) (bool, error) {
+	return f.up, f.err
+}
+
+func (f *fakeEthtoolClient) SetLinkUp(_ string, up bool) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.up = up
+	return nil
+}
+
+// x540Bitmap is 100baseT/Full | 1000baseT/Full | 10000baseT/Full
+var x540Bitmap = ethtool.Bitmap{1<<3 | 1<<5 | 1<<12, 0, 0}
+
+func TestNativeSpeedController_Limit(t *testing.T) {
+	client := &fakeEthtoolClient{up: true, settings: ethtool.LinkSettings{Speed: 10000, Autoneg: true, Supported: x540Bitmap}}
+	runner := &captureCommandRunner{}
+	ctrl := NewNativeSpeedControllerWith(client, NewEthtoolSpeedControllerWith(runner))
+
+	if err := ctrl.Limit(t.Context(), "eth1", 1000); err != nil {
+		t.Fatalf("Limit() error = %v", err)
+	}
+
+	if len(client.applied) != 1 {
+		t.Fatalf("expected 1 kernel request, got %d", len(client.applied))
+	}
+	got := client.applied[0]
+	if got.Speed != 1000 || got.Autoneg || got.Duplex != ethtool.DuplexFull {
+		t.Errorf("applied = %+v, want 1000 full autoneg off", got)
+	}
+	if len(runner.calls) != 0 {
+		t.Errorf("ethtool binary should not be used, got %v", runner.calls)
+	}
+}
+
+func TestNativeSpeedController_Restore(t *testing.T) {
+	client := &fakeEthtoolClient{settings: ethtool.LinkSettings{Speed: 1000, Supported: x540Bitmap}}
+	ctrl := NewNativeSpeedControllerWith(client, NewEthtoolSpeedControllerWith(&captureCommandRunner{}))
+
+	if err := ctrl.Restore(t.Context(), "eth1"); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	if !client.up {
+		t.Error("expected link to be brought up")
+	}
+	got := client.applied[0]
+	if !got.Autoneg || !reflect.DeepEqual(got.Advertising, x540Bitmap) {
+		t.Errorf("applied = %+v, want autoneg advertising all supported modes", got)
+	}
+}
+
+func TestNativeSpeedController_Restore_SavedAdvertising(t *testing.T) {
+	// The operator advertises only 1000baseT/Full | 10000baseT/Full
+	advertising := ethtool.Bitmap{1<<5 | 1<<12, 0, 0}
+	client := &fakeEthtoolClient{up: true, settings: ethtool.LinkSettings{
+		Speed: 10000, Autoneg: true, Supported: x540Bitmap, Advertising: advertising,
+	}}
+	ctrl := NewNativeSpeedControllerWith(client, NewEthtoolSpeedControllerWith(&captureCommandRunner{}))
+
+	if err := ctrl.Limit(t.Context(), "eth1", 1000); err != nil {
+		t.Fatalf("Limit() error = %v", err)
+	}
+	client.settings = client.applied[0]
+	if err := ctrl.Restore(t.Context(), "eth1"); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	got := client.applied[1]
+	if !got.Autoneg || !reflect.DeepEqual(got.Advertising, advertising) {
+		t.Errorf("applied = %+v, want the advertising saved before the limit", got)
+	}
+	if len(ctrl.advertising) != 0 {
+		t.Errorf("saved advertising kept after restore: %v", ctrl.advertising)
+	}
+}
+
+func TestNativeSpeedController_SpeedPinned(t *testing.T) {
+	tests := []struct {
+		name string
+		ls   ethtool.LinkSettings
+		want bool
+	}{
+		{"negotiated", ethtool.LinkSettings{Autoneg: true, Supported: x540Bitmap, Advertising: x540Bitmap}, false},
+		{"autoneg off", ethtool.LinkSettings{Supported: x540Bitmap, Advertising: x540Bitmap}, true},
+		{"advertising restricted", ethtool.LinkSettings{Autoneg: true, Supported: x540Bitmap, Advertising: ethtool.Bitmap{1<<3 | 1<<5, 0, 0}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := NewNativeSpeedControllerWith(&fakeEthtoolClient{settings: tt.ls}, NewEthtoolSpeedControllerWith(&captureCommandRunner{}))
+			got, err := ctrl.SpeedPinned(t.Context(), "eth1")
+			if err != nil || got != tt.want {
+				t.Errorf("SpeedPinned() = %v, %v; want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNativeSpeedController_AdminDown(t *testing.T) {
+	client := &fakeEthtoolClient{up: true}
+	ctrl := NewNativeSpeedControllerWith(client, NewEthtoolSpeedControllerWith(&captureCommandRunner{}))
+
+	if err := ctrl.Limit(t.Context(), "eth1", StepAdminDown); err != nil {
+		t.Fatalf("Limit() error = %v", err)
+	}
+	if client.up {
+		t.Error("expected link to be down")
+	}
+}
+
+func TestNativeSpeedController_FallbackToBinary(t *testing.T) {
+	client := &fakeEthtoolClient{err: errors.New("operation not supported")}
+	runner := &captureCommandRunner{}
+	ctrl := NewNativeSpeedControllerWith(client, NewEthtoolSpeedControllerWith(runner))
+
+	if err := ctrl.Limit(t.Context(), "eth1", 1000); err != nil {
+		t.Fatalf("Limit() error = %v", err)
+	}
+
+	want := [][]string{{"ethtool", "-s", "eth1", "speed", "1000", "duplex", "full", "autoneg", "off"}}
+	if !reflect.DeepEqual(runner.calls, want) {
+		t.Errorf("fallback calls = %v, want %v", runner.calls, want)
+	}
+}
+
+func TestNativeSpeedController_BothFail(t *testing.T) {
+	errExec := errors.New("ethtool: command failed")
+	client := &fakeEthtoolClient{err: errors.New("operation not supported")}
+	ctrl := NewNativeSpeedControllerWith(client, NewEthtoolSpeedControllerWith(&failCommandRunner{err: errExec}))
+
+	if err := ctrl.Restore(t.Context(), "eth1"); !errors.Is(err, errExec) {
+		t.Errorf("Restore() error = %v, want %v", err, errExec)
+	}
+}
+
+func TestNativeSpeedController_SupportedSpeeds(t *testing.T) {
+	client := &fakeEthtoolClient{settings: ethtool.LinkSettings{Supported: x540Bitmap}}
+	ctrl := NewNativeSpeedControllerWith(client, NewEthtoolSpeedControllerWith(&captureCommandRunner{}))
+
+	speeds, err := ctrl.SupportedSpeeds(t.Context(), "eth1")
+	if err != nil {
+		t.Fatalf("SupportedSpeeds() error = %v", err)
+	}
+	if !reflect.DeepEqual(speeds, []int{100, 1000, 10000}) {
+		t.Errorf("SupportedSpeeds() = %v", speeds)
+	}
+}
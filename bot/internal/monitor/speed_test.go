@@ -1,40 +1,80 @@
 package monitor
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 )
 
 // captureCommandRunner records command invocations for testing.
+// Output returns canned results keyed by the joined command line.
 type captureCommandRunner struct {
-	calls [][]string
+	calls   [][]string
+	outputs map[string]string
 }
 
-func (r *captureCommandRunner) Run(name string, args ...string) error {
+func (r *captureCommandRunner) Run(_ context.Context, name string, args ...string) error {
 	call := append([]string{name}, args...)
 	r.calls = append(r.calls, call)
 	return nil
 }
 
+func (r *captureCommandRunner) Output(_ context.Context, name string, args ...string) ([]byte, error) {
+	key := strings.Join(append([]string{name}, args...), " ")
+	if out, ok := r.outputs[key]; ok {
+		return []byte(out), nil
+	}
+	return nil, fmt.Errorf("command not found: %s", key)
+}
+
 // failCommandRunner always returns the configured error.
 type failCommandRunner struct {
 	err error
 }
 
-func (r *failCommandRunner) Run(_ string, _ ...string) error {
+func (r *failCommandRunner) Run(_ context.Context, _ string, _ ...string) error {
 	return r.err
 }
 
+func (r *failCommandRunner) Output(_ context.Context, _ string, _ ...string) ([]byte, error) {
+	return nil, r.err
+}
+
+const ethtoolX540Output = `Settings for eth2:
+	Supported ports: [ TP ]
+	Supported link modes:   100baseT/Full
+	                        1000baseT/Full
+	                        10000baseT/Full
+	Supported pause frame use: Symmetric
+	Supports auto-negotiation: Yes
+	Advertised link modes:  1000baseT/Full
+	                        10000baseT/Full
+	Speed: 10000Mb/s
+	Auto-negotiation: on
+`
+
+const ethtoolNBaseTOutput = `Settings for eth2:
+	Supported ports: [ TP ]
+	Supported link modes:   100baseT/Full
+	                        1000baseT/Full
+	                        10000baseT/Full
+	                        2500baseT/Full
+	                        5000baseT/Full
+	Supported pause frame use: Symmetric
+`
+
 func TestEthtoolSpeedController_Limit(t *testing.T) {
 	runner := &captureCommandRunner{}
 	ctrl := NewEthtoolSpeedControllerWith(runner)
 
-	if err := ctrl.Limit("eth2"); err != nil {
+	if err := ctrl.Limit(t.Context(), "eth2", 1000); err != nil {
 		t.Fatalf("Limit() error = %v", err)
 	}
 
-	want := []string{"ethtool", "-s", "eth2", "speed", speedLimitMbps, "duplex", "full", "autoneg", "off"}
+	want := []string{"ethtool", "-s", "eth2", "speed", "1000", "duplex", "full", "autoneg", "off"}
 	if len(runner.calls) != 1 {
 		t.Fatalf("expected 1 call, got %d", len(runner.calls))
 	}
@@ -47,7 +87,7 @@ func TestEthtoolSpeedController_Limit_Error(t *testing.T) {
 	errExec := errors.New("ethtool: command failed")
 	ctrl := NewEthtoolSpeedControllerWith(&failCommandRunner{err: errExec})
 
-	err := ctrl.Limit("eth2")
+	err := ctrl.Limit(t.Context(), "eth2", 1000)
 	if !errors.Is(err, errExec) {
 		t.Errorf("Limit() error = %v, want %v", err, errExec)
 	}
@@ -57,7 +97,7 @@ func TestEthtoolSpeedController_Restore(t *testing.T) {
 	runner := &captureCommandRunner{}
 	ctrl := NewEthtoolSpeedControllerWith(runner)
 
-	if err := ctrl.Restore("eth2"); err != nil {
+	if err := ctrl.Restore(t.Context(), "eth2"); err != nil {
 		t.Fatalf("Restore() error = %v", err)
 	}
 
@@ -74,7 +114,7 @@ func TestEthtoolSpeedController_Restore_Error(t *testing.T) {
 	errExec := errors.New("ethtool: command failed")
 	ctrl := NewEthtoolSpeedControllerWith(&failCommandRunner{err: errExec})
 
-	err := ctrl.Restore("eth2")
+	err := ctrl.Restore(t.Context(), "eth2")
 	if !errors.Is(err, errExec) {
 		t.Errorf("Restore() error = %v, want %v", err, errExec)
 	}
@@ -94,7 +134,7 @@ func TestEthtoolSpeedController_Restore_UsesAdvertiseField(t *testing.T) {
 	ctrl := NewEthtoolSpeedControllerWith(runner)
 	ctrl.advertise = "0x0020"
 
-	if err := ctrl.Restore("eth2"); err != nil {
+	if err := ctrl.Restore(t.Context(), "eth2"); err != nil {
 		t.Fatalf("Restore() error = %v", err)
 	}
 
@@ -106,3 +146,165 @@ func TestEthtoolSpeedController_Restore_UsesAdvertiseField(t *testing.T) {
 		t.Errorf("Restore() args = %v, want %v", runner.calls[0], want)
 	}
 }
+
+func TestEthtoolSpeedController_Limit_AdminDown(t *testing.T) {
+	runner := &captureCommandRunner{}
+	ctrl := NewEthtoolSpeedControllerWith(runner)
+
+	if err := ctrl.Limit(t.Context(), "eth2", StepAdminDown); err != nil {
+		t.Fatalf("Limit() error = %v", err)
+	}
+
+	want := [][]string{{"ip", "link", "set", "dev", "eth2", "down"}}
+	if !reflect.DeepEqual(runner.calls, want) {
+		t.Errorf("Limit() calls = %v, want %v", runner.calls, want)
+	}
+}
+
+func TestEthtoolSpeedController_Restore_BringsLinkUp(t *testing.T) {
+	runner := &captureCommandRunner{outputs: map[string]string{
+		"ip -o link show dev eth2": "4: eth2: <BROADCAST,MULTICAST> mtu 1500 qdisc mq state DOWN",
+	}}
+	ctrl := NewEthtoolSpeedControllerWith(runner)
+
+	if err := ctrl.Restore(t.Context(), "eth2"); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	want := [][]string{
+		{"ip", "link", "set", "dev", "eth2", "up"},
+		{"ethtool", "-s", "eth2", "autoneg", "on", "advertise", advertiseX540},
+	}
+	if !reflect.DeepEqual(runner.calls, want) {
+		t.Errorf("Restore() calls = %v, want %v", runner.calls, want)
+	}
+}
+
+func TestEthtoolSpeedController_Restore_DetectedModes(t *testing.T) {
+	runner := &captureCommandRunner{outputs: map[string]string{
+		"ip -o link show dev eth2": "4: eth2: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500",
+		"ethtool eth2":             ethtoolNBaseTOutput,
+	}}
+	ctrl := NewEthtoolSpeedControllerWith(runner)
+
+	if err := ctrl.Restore(t.Context(), "eth2"); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	// 100baseT/Full | 1000baseT/Full | 10000baseT/Full | 2500baseT/Full | 5000baseT/Full
+	want := [][]string{{"ethtool", "-s", "eth2", "autoneg", "on", "advertise", "0x1800000001028"}}
+	if !reflect.DeepEqual(runner.calls, want) {
+		t.Errorf("Restore() calls = %v, want %v", runner.calls, want)
+	}
+}
+
+func TestEthtoolSpeedController_SupportedSpeeds(t *testing.T) {
+	runner := &captureCommandRunner{outputs: map[string]string{"ethtool eth2": ethtoolX540Output}}
+	ctrl := NewEthtoolSpeedControllerWith(runner)
+
+	speeds, err := ctrl.SupportedSpeeds(t.Context(), "eth2")
+	if err != nil {
+		t.Fatalf("SupportedSpeeds() error = %v", err)
+	}
+	want := []int{100, 1000, 10000}
+	if !reflect.DeepEqual(speeds, want) {
+		t.Errorf("SupportedSpeeds() = %v, want %v", speeds, want)
+	}
+}
+
+func TestEthtoolSpeedController_Restore_SavedAdvertising(t *testing.T) {
+	// The operator dropped 100baseT/Full from the advertised modes
+	runner := &captureCommandRunner{outputs: map[string]string{
+		"ip -o link show dev eth2": "4: eth2: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500",
+		"ethtool eth2":             ethtoolX540Output,
+	}}
+	ctrl := NewEthtoolSpeedControllerWith(runner)
+
+	if err := ctrl.Limit(t.Context(), "eth2", 1000); err != nil {
+		t.Fatalf("Limit() error = %v", err)
+	}
+	// Once limited, ethtool reports the forced speed; it must not be saved
+	runner.outputs["ethtool eth2"] = strings.Replace(ethtoolX540Output, "Auto-negotiation: on", "Auto-negotiation: off", 1)
+	if err := ctrl.Limit(t.Context(), "eth2", 100); err != nil {
+		t.Fatalf("Limit() error = %v", err)
+	}
+	if err := ctrl.Restore(t.Context(), "eth2"); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	// 1000baseT/Full | 10000baseT/Full
+	want := []string{"ethtool", "-s", "eth2", "autoneg", "on", "advertise", "0x1020"}
+	if got := runner.calls[len(runner.calls)-1]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Restore() args = %v, want %v", got, want)
+	}
+	if len(ctrl.advertised) != 0 {
+		t.Errorf("saved advertising kept after restore: %v", ctrl.advertised)
+	}
+}
+
+func TestEthtoolSpeedController_SpeedPinned(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		want    bool
+		wantErr bool
+	}{
+		{"negotiated", ethtoolX540Output, false, false},
+		{"autoneg off", strings.Replace(ethtoolX540Output, "Auto-negotiation: on", "Auto-negotiation: off", 1), true, false},
+		{"advertising restricted", strings.Replace(ethtoolX540Output, "\t                        10000baseT/Full\n\tSpeed", "\tSpeed", 1), true, false},
+		{"unknown", ethtoolNBaseTOutput, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := NewEthtoolSpeedControllerWith(&captureCommandRunner{outputs: map[string]string{"ethtool eth2": tt.out}})
+			got, err := ctrl.SpeedPinned(t.Context(), "eth2")
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("SpeedPinned() = %v, %v; want %v, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseLinkModes_StopsAtNextKey(t *testing.T) {
+	modes := parseLinkModes(ethtoolX540Output, "Supported link modes:")
+	want := []string{"100baseT/Full", "1000baseT/Full", "10000baseT/Full"}
+	if !reflect.DeepEqual(modes, want) {
+		t.Errorf("parseLinkModes() = %v, want %v", modes, want)
+	}
+	modes = parseLinkModes(ethtoolX540Output, "Advertised link modes:")
+	if want := []string{"1000baseT/Full", "10000baseT/Full"}; !reflect.DeepEqual(modes, want) {
+		t.Errorf("parseLinkModes() = %v, want %v", modes, want)
+	}
+}
+
+func TestParseThrottleLadder(t *testing.T) {
+	ladder, err := ParseThrottleLadder("5000, 2500,1000,down")
+	if err != nil {
+		t.Fatalf("ParseThrottleLadder() error = %v", err)
+	}
+	want := []ThrottleStep{5000, 2500, 1000, StepAdminDown}
+	if !reflect.DeepEqual(ladder, want) {
+		t.Errorf("ParseThrottleLadder() = %v, want %v", ladder, want)
+	}
+
+	for _, bad := range []string{"", "fast", "1000,-5"} {
+		if _, err := ParseThrottleLadder(bad); err == nil {
+			t.Errorf("ParseThrottleLadder(%q) expected error", bad)
+		}
+	}
+}
+
+func TestThrottleStep_String(t *testing.T) {
+	tests := map[ThrottleStep]string{
+		1000:          "1Gbps",
+		2500:          "2.5Gbps",
+		100:           "100Mbps",
+		StepAdminDown: "link down",
+	}
+	for step, want := range tests {
+		if got := step.String(); got != want {
+			t.Errorf("ThrottleStep(%d).String() = %q, want %q", int(step), got, want)
+		}
+	}
+}
@@ -3,8 +3,22 @@ package monitor
 import (
 	"encoding/json"
 	"os"
+
+	"github.com/murata-lab/pervigil/bot/internal/atomicfile"
 )
 
+// legacyStateKey marks a state migrated from the single-state file format.
+// NICMonitor assigns it to the first monitored interface on first load.
+const legacyStateKey = "*"
+
+// stateFile is the on-disk JSON structure.
+// TempState/SpeedLimited are only read to migrate the legacy single-state format.
+type stateFile struct {
+	Interfaces   map[string]MonitorState `json:"interfaces,omitempty"`
+	TempState    NICState                `json:"temp_state,omitempty"`
+	SpeedLimited bool                    `json:"speed_limited,omitempty"`
+}
+
 // FileStateStore persists state to a file
 type FileStateStore struct {
 	path string
@@ -15,36 +29,52 @@ func NewFileStateStore(path string) *FileStateStore {
 	return &FileStateStore{path: path}
 }
 
-// Load reads the state from file
-func (s *FileStateStore) Load() (MonitorState, error) {
-	defaultState := MonitorState{TempState: StateNormal, SpeedLimited: false}
+// Load reads the per-interface states from file
+func (s *FileStateStore) Load() (map[string]MonitorState, error) {
+	states := make(map[string]MonitorState)
 
 	data, err := os.ReadFile(s.path)
 	if os.IsNotExist(err) {
-		return defaultState, nil
+		return states, nil
 	}
 	if err != nil {
-		return defaultState, err
+		return states, err
 	}
 
-	var state MonitorState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return defaultState, nil
+	var file stateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return states, nil
 	}
 
-	// Validate TempState
-	if state.TempState != StateNormal && state.TempState != StateWarning && state.TempState != StateCritical {
-		state.TempState = StateNormal
+	// Legacy format: one state shared by the hottest NIC
+	if file.Interfaces == nil && (file.TempState != "" || file.SpeedLimited) {
+		states[legacyStateKey] = validateState(MonitorState{
+			TempState:    file.TempState,
+			SpeedLimited: file.SpeedLimited,
+		})
+		return states, nil
 	}
 
-	return state, nil
+	for iface, state := range file.Interfaces {
+		states[iface] = validateState(state)
+	}
+	return states, nil
 }
 
-// Save writes the state to file
-func (s *FileStateStore) Save(state MonitorState) error {
-	data, err := json.Marshal(state)
+// Save writes the per-interface states to file. The file is replaced
+// atomically so the status API and a restart never read a partial write.
+func (s *FileStateStore) Save(states map[string]MonitorState) error {
+	data, err := json.Marshal(stateFile{Interfaces: states})
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(s.path, data, 0644)
+	return atomicfile.WriteFile(s.path, data, 0644)
+}
+
+// validateState resets an unknown TempState to normal
+func validateState(state MonitorState) MonitorState {
+	if state.TempState != StateNormal && state.TempState != StateWarning && state.TempState != StateCritical {
+		state.TempState = StateNormal
+	}
+	return state
 }
@@ -0,0 +1,75 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFileStateStore_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state")
+	store := NewFileStateStore(path)
+
+	want := map[string]MonitorState{
+		"eth1": {TempState: StateCritical, SpeedLimited: true},
+		"eth2": {TempState: StateWarning},
+	}
+	if err := store.Save(want); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFileStateStore_NotExist(t *testing.T) {
+	store := NewFileStateStore(filepath.Join(t.TempDir(), "missing"))
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Load() = %+v, want empty", got)
+	}
+}
+
+func TestFileStateStore_MigratesLegacyFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state")
+	if err := os.WriteFile(path, []byte(`{"temp_state":"critical","speed_limited":true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := NewFileStateStore(path).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := MonitorState{TempState: StateCritical, SpeedLimited: true}
+	if len(got) != 1 || !reflect.DeepEqual(got[legacyStateKey], want) {
+		t.Errorf("Load() = %+v, want {%q: %+v}", got, legacyStateKey, want)
+	}
+}
+
+func TestFileStateStore_InvalidTempState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state")
+	if err := os.WriteFile(path, []byte(`{"interfaces":{"eth1":{"temp_state":"bogus","speed_limited":true}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := NewFileStateStore(path).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got["eth1"].TempState != StateNormal {
+		t.Errorf("TempState = %v, want %v", got["eth1"].TempState, StateNormal)
+	}
+	if !got["eth1"].SpeedLimited {
+		t.Error("SpeedLimited should be preserved")
+	}
+}
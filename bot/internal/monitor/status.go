@@ -0,0 +1,247 @@
+package monitor
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+	"github.com/murata-lab/pervigil/bot/internal/statusapi"
+)
+
+// DefaultAlertLogSize is how many recent alerts the status API returns
+const DefaultAlertLogSize = 20
+
+// AlertLog is a notifier that remembers the alerts passed on to the next
+// notifier, so the status API can show what was sent
+type AlertLog struct {
+	next    notifier.Notifier
+	size    int
+	nowFunc func() time.Time
+
+	mu     sync.Mutex
+	alerts []statusapi.Alert // oldest first
+}
+
+// AlertLogOption configures AlertLog
+type AlertLogOption func(*AlertLog)
+
+// WithAlertLogSize sets how many alerts are kept
+func WithAlertLogSize(n int) AlertLogOption {
+	return func(l *AlertLog) {
+		l.size = n
+	}
+}
+
+// WithAlertLogNowFunc sets a custom time source (for testing)
+func WithAlertLogNowFunc(f func() time.Time) AlertLogOption {
+	return func(l *AlertLog) {
+		l.nowFunc = f
+	}
+}
+
+// NewAlertLog wraps next, recording every alert it delivers
+func NewAlertLog(next notifier.Notifier, opts ...AlertLogOption) *AlertLog {
+	l := &AlertLog{
+		next:    next,
+		size:    DefaultAlertLogSize,
+		nowFunc: time.Now,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Send sends through the next notifier and records the alert on success
+func (l *AlertLog) Send(title, message string, color notifier.Color, fields []notifier.Field) error {
+	if err := l.next.Send(title, message, color, fields); err != nil {
+		return err
+	}
+	l.record("", title, message, color)
+	return nil
+}
+
+// SendWithAttachments sends through the next notifier and records the alert on success
+func (l *AlertLog) SendWithAttachments(title, message string, color notifier.Color, fields []notifier.Field, files []notifier.Attachment) error {
+	return l.SendFrom("", title, message, color, fields, files)
+}
+
+// SendFrom sends through the next notifier, passing the source on, and
+// records the alert on success
+func (l *AlertLog) SendFrom(source, title, message string, color notifier.Color, fields []notifier.Field, files []notifier.Attachment) error {
+	if err := notifier.SendFrom(l.next, source, title, message, color, fields, files); err != nil {
+		return err
+	}
+	l.record(source, title, message, color)
+	return nil
+}
+
+// Alerts returns the recorded alerts, newest first
+func (l *AlertLog) Alerts() []statusapi.Alert {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	alerts := slices.Clone(l.alerts)
+	slices.Reverse(alerts)
+	return alerts
+}
+
+func (l *AlertLog) record(source, title, message string, color notifier.Color) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.alerts = append(l.alerts, statusapi.Alert{Time: l.nowFunc(), Title: title, Message: message, Color: color, Source: source})
+	if over := len(l.alerts) - l.size; over > 0 {
+		l.alerts = slices.Delete(l.alerts, 0, over)
+	}
+}
+
+// nicStatusReader returns the NIC state machine and last readings
+type nicStatusReader interface {
+	nicStateReader
+	LastReadings() map[string]NICReading
+	DryRun() bool
+}
+
+// alertLister returns recent alerts
+type alertLister interface {
+	Alerts() []statusapi.Alert
+}
+
+// StatusHandler serves the monitor state to pervigil-bot as JSON
+type StatusHandler struct {
+	nic       nicStatusReader
+	suppress  suppressStatter
+	alerts    alertLister
+	ifaces    []string
+	hostname  string
+	startedAt time.Time
+}
+
+// StatusOption configures StatusHandler
+type StatusOption func(*StatusHandler)
+
+// WithStatusNIC sets the NIC monitor
+func WithStatusNIC(n nicStatusReader) StatusOption {
+	return func(h *StatusHandler) {
+		h.nic = n
+	}
+}
+
+// WithStatusSuppressor sets the source of check errors
+func WithStatusSuppressor(s suppressStatter) StatusOption {
+	return func(h *StatusHandler) {
+		h.suppress = s
+	}
+}
+
+// WithStatusAlerts sets the source of recent alerts
+func WithStatusAlerts(a alertLister) StatusOption {
+	return func(h *StatusHandler) {
+		h.alerts = a
+	}
+}
+
+// WithStatusInterface sets the interfaces to report (comma-separated)
+func WithStatusInterface(iface string) StatusOption {
+	return func(h *StatusHandler) {
+		h.ifaces = splitInterfaces(iface)
+	}
+}
+
+// WithStatusStartedAt sets the monitor start time
+func WithStatusStartedAt(t time.Time) StatusOption {
+	return func(h *StatusHandler) {
+		h.startedAt = t
+	}
+}
+
+// NewStatusHandler creates a new status API handler
+func NewStatusHandler(opts ...StatusOption) *StatusHandler {
+	hostname, _ := os.Hostname()
+	h := &StatusHandler{
+		ifaces:    []string{"eth1"},
+		hostname:  hostname,
+		startedAt: time.Now(),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// ServeHTTP serves statusapi.StatusPath
+func (h *StatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != statusapi.StatusPath {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	st, err := h.Status()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(st)
+}
+
+// Status collects the current monitor state
+func (h *StatusHandler) Status() (*statusapi.Status, error) {
+	st := &statusapi.Status{
+		Hostname:   h.hostname,
+		StartedAt:  h.startedAt,
+		Interfaces: []statusapi.InterfaceStatus{},
+	}
+
+	if h.nic != nil {
+		states, err := h.nic.States()
+		if err != nil {
+			return nil, err
+		}
+		readings := h.nic.LastReadings()
+		st.DryRun = h.nic.DryRun()
+		for _, iface := range h.ifaces {
+			state := states[iface]
+			is := statusapi.InterfaceStatus{
+				Name:          iface,
+				State:         string(state.TempState),
+				SpeedLimited:  state.SpeedLimited,
+				ThrottleLevel: state.ThrottleLevel,
+			}
+			if r, ok := readings[iface]; ok {
+				is.Temperature = r.Value
+				is.Warning = r.Thresholds.Warning
+				is.Critical = r.Thresholds.Critical
+				is.ReadAt = r.Time
+			}
+			st.Interfaces = append(st.Interfaces, is)
+		}
+	}
+
+	if h.suppress != nil {
+		stats := h.suppress.Stats()
+		for _, key := range slices.Sorted(maps.Keys(stats)) {
+			s := stats[key]
+			st.Errors = append(st.Errors, statusapi.CheckError{
+				Check:      key,
+				Message:    s.LastError,
+				Time:       s.LastErrorAt,
+				Failing:    s.Failing,
+				Errors:     s.Errors,
+				Suppressed: s.Suppressed,
+			})
+		}
+	}
+
+	if h.alerts != nil {
+		st.Alerts = h.alerts.Alerts()
+	}
+	return st, nil
+}
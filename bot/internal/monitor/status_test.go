@@ -0,0 +1,113 @@
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+	"github.com/murata-lab/pervigil/bot/internal/statusapi"
+)
+
+type failingNotifier struct{}
+
+func (failingNotifier) Send(string, string, notifier.Color, []notifier.Field) error {
+	return errors.New("webhook down")
+}
+
+func TestAlertLog(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	next := &mockAttachmentNotifier{}
+	l := NewAlertLog(next, WithAlertLogSize(2), WithAlertLogNowFunc(func() time.Time { return now }))
+
+	for _, title := range []string{"a", "b"} {
+		if err := l.Send(title, "msg", notifier.ColorYellow, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	nic := notifier.From(l, "nic")
+	if err := notifier.SendWithAttachments(nic, "c", "msg", notifier.ColorRed, nil, []notifier.Attachment{{Name: "x.png"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(next.calls) != 3 || len(next.files) != 1 {
+		t.Errorf("next got %d sends, %d with files; want 3, 1", len(next.calls), len(next.files))
+	}
+	alerts := l.Alerts()
+	if len(alerts) != 2 || alerts[0].Title != "c" || alerts[1].Title != "b" {
+		t.Fatalf("Alerts() = %+v, want c, b", alerts)
+	}
+	if alerts[0].Color != notifier.ColorRed || alerts[0].Source != "nic" || !alerts[0].Time.Equal(now) {
+		t.Errorf("alert = %+v", alerts[0])
+	}
+
+	failing := NewAlertLog(failingNotifier{})
+	if err := failing.Send("lost", "msg", notifier.ColorBlue, nil); err == nil {
+		t.Error("Send() should return the delivery error")
+	}
+	if got := failing.Alerts(); len(got) != 0 {
+		t.Errorf("undelivered alert recorded: %+v", got)
+	}
+}
+
+func TestStatusHandler_ServeHTTP(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	alerts := NewAlertLog(&mockNotifier{}, WithAlertLogNowFunc(func() time.Time { return now }))
+	nic := NewNICMonitor(
+		WithTempReader(&mockPerIfaceTempReader{temps: map[string]float64{"eth1": 72}}),
+		WithNotifier(alerts),
+		WithStateStore(newMockStateStore("eth1", MonitorState{TempState: StateNormal})),
+		WithSpeedController(&mockSpeedController{}),
+		WithInterface("eth1,eth2"),
+		WithNowFunc(func() time.Time { return now }),
+	)
+	if err := nic.Check(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	suppress := NewErrorSuppressor(WithSuppressNowFunc(func() time.Time { return now }))
+	suppress.Check("sfp", errors.New("no module"))
+	suppress.Check("log", errors.New("permission denied"))
+	suppress.Check("log", nil)
+
+	h := NewStatusHandler(
+		WithStatusNIC(nic),
+		WithStatusSuppressor(suppress),
+		WithStatusAlerts(alerts),
+		WithStatusInterface("eth1,eth2"),
+	)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, statusapi.StatusPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+
+	var st statusapi.Status
+	if err := json.NewDecoder(rec.Body).Decode(&st); err != nil {
+		t.Fatal(err)
+	}
+	if len(st.Interfaces) != 2 {
+		t.Fatalf("interfaces = %+v", st.Interfaces)
+	}
+	eth1, eth2 := st.Interfaces[0], st.Interfaces[1]
+	if eth1.Name != "eth1" || eth1.State != "warning" || eth1.Temperature != 72 || eth1.Warning == 0 || !eth1.ReadAt.Equal(now) {
+		t.Errorf("eth1 = %+v", eth1)
+	}
+	if eth2.State != "normal" || !eth2.ReadAt.IsZero() {
+		t.Errorf("eth2 = %+v, want normal and never read", eth2)
+	}
+	if len(st.Errors) != 2 || st.Errors[0].Check != "log" || st.Errors[0].Failing || !st.Errors[1].Failing {
+		t.Errorf("errors = %+v", st.Errors)
+	}
+	if len(st.Alerts) != 1 || !st.Alerts[0].Time.Equal(now) {
+		t.Errorf("alerts = %+v, want the warning alert", st.Alerts)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/other", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown path status = %d, want 404", rec.Code)
+	}
+}
@@ -1,6 +1,10 @@
 package monitor
 
-import "github.com/murata-lab/pervigil/bot/internal/temperature"
+import (
+	"context"
+
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
+)
 
 // TempAdapter adapts the temperature package for monitor use
 type TempAdapter struct{}
@@ -11,6 +15,16 @@ func NewTempAdapter() *TempAdapter {
 }
 
 // GetNICTemp returns the NIC temperature
-func (a *TempAdapter) GetNICTemp(iface string) (*temperature.TempReading, error) {
-	return temperature.GetNICTemp(iface)
+func (a *TempAdapter) GetNICTemp(ctx context.Context, iface string) (*temperature.TempReading, error) {
+	return temperature.GetNICTemp(ctx, iface)
+}
+
+// GetCPUTemps returns the CPU temperatures
+func (a *TempAdapter) GetCPUTemps(ctx context.Context) ([]temperature.TempReading, error) {
+	return temperature.GetCPUTemps(ctx)
+}
+
+// GetBoardTemps returns the non-CPU hwmon temperatures
+func (a *TempAdapter) GetBoardTemps() ([]temperature.TempReading, error) {
+	return temperature.GetBoardTemps()
 }
@@ -0,0 +1,130 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ThrottleStep is one level of the throttle ladder: a link speed in Mbps,
+// or StepAdminDown to take the link down as a last resort.
+type ThrottleStep int
+
+// StepAdminDown takes the link administratively down.
+const StepAdminDown ThrottleStep = -1
+
+// String returns a human readable label such as "1Gbps" or "link down".
+func (s ThrottleStep) String() string {
+	switch {
+	case s == StepAdminDown:
+		return "link down"
+	case s >= 1000 && s%1000 == 0:
+		return fmt.Sprintf("%dGbps", s/1000)
+	case s >= 1000:
+		return fmt.Sprintf("%.1fGbps", float64(s)/1000)
+	default:
+		return fmt.Sprintf("%dMbps", s)
+	}
+}
+
+// ThrottlePolicy configures stepwise thermal throttling.
+// Ladder lists throttled steps from mildest to most severe; full speed is implicit.
+type ThrottlePolicy struct {
+	Ladder        []ThrottleStep
+	StepDownAfter time.Duration // sustained critical period before the next step down
+	StepUpAfter   time.Duration // recovery period between steps back up
+}
+
+// DefaultThrottlePolicy returns the single-step 1Gbps policy.
+func DefaultThrottlePolicy() ThrottlePolicy {
+	return ThrottlePolicy{
+		Ladder:        []ThrottleStep{1000},
+		StepDownAfter: 5 * time.Minute,
+	}
+}
+
+// ParseThrottleLadder parses a comma-separated ladder such as "5000,2500,1000,down".
+func ParseThrottleLadder(s string) ([]ThrottleStep, error) {
+	var ladder []ThrottleStep
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if part == "down" {
+			ladder = append(ladder, StepAdminDown)
+			continue
+		}
+		mbps, err := strconv.Atoi(part)
+		if err != nil || mbps <= 0 {
+			return nil, fmt.Errorf("invalid throttle step %q", part)
+		}
+		ladder = append(ladder, ThrottleStep(mbps))
+	}
+	if len(ladder) == 0 {
+		return nil, fmt.Errorf("empty throttle ladder")
+	}
+	return ladder, nil
+}
+
+// speedDetector is implemented by speed controllers that can report
+// which link speeds (Mbps) a NIC supports.
+type speedDetector interface {
+	SupportedSpeeds(ctx context.Context, iface string) ([]int, error)
+}
+
+// pinDetector is implemented by speed controllers that can report whether
+// a NIC's speed is forced: auto-negotiation off or advertising restricted
+// below the fastest supported mode.
+type pinDetector interface {
+	SpeedPinned(ctx context.Context, iface string) (bool, error)
+}
+
+// ladderFor returns the throttle ladder restricted to steps the NIC supports.
+// The configured ladder is used as-is when detection is unavailable.
+func (m *NICMonitor) ladderFor(ctx context.Context, iface string) []ThrottleStep {
+	ladder := m.throttle.Ladder
+	speeds, ok := m.supportedSpeeds(ctx, iface)
+	if !ok {
+		return ladder
+	}
+
+	top := slices.Max(speeds)
+	var supported []ThrottleStep
+	for _, step := range ladder {
+		if step == StepAdminDown || (int(step) < top && slices.Contains(speeds, int(step))) {
+			supported = append(supported, step)
+		}
+	}
+	if len(supported) == 0 {
+		return ladder
+	}
+	return supported
+}
+
+// supportedSpeeds returns the link speeds iface supports, detecting them
+// once per interface. A failed detection is retried on the next call.
+func (m *NICMonitor) supportedSpeeds(ctx context.Context, iface string) ([]int, bool) {
+	m.mu.Lock()
+	speeds, ok := m.speeds[iface]
+	m.mu.Unlock()
+	if ok {
+		return speeds, true
+	}
+
+	detector, ok := m.speedCtrl.(speedDetector)
+	if !ok {
+		return nil, false
+	}
+	speeds, err := detector.SupportedSpeeds(ctx, iface)
+	if err != nil || len(speeds) == 0 {
+		return nil, false
+	}
+	m.mu.Lock()
+	m.speeds[iface] = speeds
+	m.mu.Unlock()
+	return speeds, true
+}
@@ -0,0 +1,23 @@
+package notifier
+
+// Attachment is a file sent along with a notification (e.g. a chart).
+type Attachment struct {
+	Name        string // file name, e.g. "nic-temp.png"
+	ContentType string
+	Data        []byte
+}
+
+// AttachmentSender is implemented by notifiers that can attach files.
+// The first image is shown inside the message where the service supports it.
+type AttachmentSender interface {
+	SendWithAttachments(title, message string, color Color, fields []Field, files []Attachment) error
+}
+
+// SendWithAttachments sends through n, dropping the files when n cannot
+// attach them so the notification itself is never lost.
+func SendWithAttachments(n Notifier, title, message string, color Color, fields []Field, files []Attachment) error {
+	if s, ok := n.(AttachmentSender); ok && len(files) > 0 {
+		return s.SendWithAttachments(title, message, color, fields, files)
+	}
+	return n.Send(title, message, color, fields)
+}
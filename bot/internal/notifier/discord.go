@@ -4,7 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"strings"
 	"time"
 )
 
@@ -74,9 +78,14 @@ type embed struct {
 	Description string       `json:"description"`
 	Color       int          `json:"color"`
 	Fields      []embedField `json:"fields,omitempty"`
+	Image       *embedImage  `json:"image,omitempty"`
 	Timestamp   string       `json:"timestamp"`
 }
 
+type embedImage struct {
+	URL string `json:"url"`
+}
+
 type embedField struct {
 	Name   string `json:"name"`
 	Value  string `json:"value"`
@@ -85,12 +94,59 @@ type embedField struct {
 
 // Send sends a notification to Discord
 func (d *DiscordNotifier) Send(title, message string, color Color, fields []Field) error {
+	body, err := json.Marshal(d.payload(title, message, color, fields))
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+	return d.post(bytes.NewReader(body), "application/json; charset=utf-8")
+}
+
+// SendWithAttachments sends a notification with files as a multipart
+// webhook request. The first image is shown inside the embed.
+func (d *DiscordNotifier) SendWithAttachments(title, message string, color Color, fields []Field, files []Attachment) error {
+	payload := d.payload(title, message, color, fields)
+	for _, f := range files {
+		if strings.HasPrefix(f.ContentType, "image/") {
+			payload.Embeds[0].Image = &embedImage{URL: "attachment://" + f.Name}
+			break
+		}
+	}
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	if err := w.WriteField("payload_json", string(payloadJSON)); err != nil {
+		return fmt.Errorf("write payload: %w", err)
+	}
+	for i, f := range files {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="files[%d]"; filename=%q`, i, f.Name))
+		h.Set("Content-Type", f.ContentType)
+		part, err := w.CreatePart(h)
+		if err != nil {
+			return fmt.Errorf("write %s: %w", f.Name, err)
+		}
+		if _, err := part.Write(f.Data); err != nil {
+			return fmt.Errorf("write %s: %w", f.Name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("write multipart: %w", err)
+	}
+	return d.post(&body, w.FormDataContentType())
+}
+
+// payload builds the webhook message with a single embed
+func (d *DiscordNotifier) payload(title, message string, color Color, fields []Field) webhookPayload {
 	embedFields := make([]embedField, len(fields))
 	for i, f := range fields {
 		embedFields[i] = embedField(f)
 	}
 
-	payload := webhookPayload{
+	return webhookPayload{
 		Username: "Pervigil",
 		Embeds: []embed{
 			{
@@ -102,17 +158,15 @@ func (d *DiscordNotifier) Send(title, message string, color Color, fields []Fiel
 			},
 		},
 	}
+}
 
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("marshal payload: %w", err)
-	}
-
-	req, err := http.NewRequest(http.MethodPost, d.webhookURL, bytes.NewReader(body))
+// post sends a request body to the webhook
+func (d *DiscordNotifier) post(body io.Reader, contentType string) error {
+	req, err := http.NewRequest(http.MethodPost, d.webhookURL, body)
 	if err != nil {
-		return fmt.Errorf("create request: %w", err)
+		return Permanent(fmt.Errorf("create request: %w", err))
 	}
-	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Content-Type", contentType)
 
 	resp, err := d.client.Do(req)
 	if err != nil {
@@ -121,7 +175,7 @@ func (d *DiscordNotifier) Send(title, message string, color Color, fields []Fiel
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("discord API error: status %d", resp.StatusCode)
+		return statusError(resp.StatusCode, fmt.Errorf("discord API error: status %d", resp.StatusCode))
 	}
 
 	return nil
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"testing"
 )
@@ -106,6 +108,80 @@ func TestDiscordNotifier_Send_PayloadFormat(t *testing.T) {
 	}
 }
 
+func TestDiscordNotifier_SendWithAttachments(t *testing.T) {
+	var (
+		payload webhookPayload
+		file    []byte
+		name    string
+	)
+	client := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			_, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+			if err != nil {
+				t.Fatalf("content type: %v", err)
+			}
+			r := multipart.NewReader(req.Body, params["boundary"])
+			for {
+				part, err := r.NextPart()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("next part: %v", err)
+				}
+				data, _ := io.ReadAll(part)
+				switch part.FormName() {
+				case "payload_json":
+					if err := json.Unmarshal(data, &payload); err != nil {
+						t.Fatalf("invalid payload_json: %v", err)
+					}
+				case "files[0]":
+					file, name = data, part.FileName()
+				}
+			}
+			return &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(bytes.NewReader([]byte{})),
+			}, nil
+		},
+	}
+
+	n := NewDiscordNotifier("https://example.com/webhook", WithHTTPClient(client))
+	err := SendWithAttachments(n, "Title", "Description", ColorRed, nil, []Attachment{
+		{Name: "chart.png", ContentType: "image/png", Data: []byte("png")},
+	})
+	if err != nil {
+		t.Fatalf("SendWithAttachments() error = %v", err)
+	}
+
+	if name != "chart.png" || string(file) != "png" {
+		t.Errorf("file = %q (%q), want chart.png", name, file)
+	}
+	if len(payload.Embeds) != 1 || payload.Embeds[0].Title != "Title" {
+		t.Fatalf("embeds = %+v", payload.Embeds)
+	}
+	if img := payload.Embeds[0].Image; img == nil || img.URL != "attachment://chart.png" {
+		t.Errorf("embed image = %+v, want attachment://chart.png", img)
+	}
+}
+
+type plainNotifier struct {
+	titles []string
+}
+
+func (p *plainNotifier) Send(title, _ string, _ Color, _ []Field) error {
+	p.titles = append(p.titles, title)
+	return nil
+}
+
+func TestSendWithAttachments_Fallback(t *testing.T) {
+	n := &plainNotifier{}
+	err := SendWithAttachments(n, "Title", "", ColorRed, nil, []Attachment{{Name: "chart.png"}})
+	if err != nil || len(n.titles) != 1 {
+		t.Errorf("fallback Send: titles = %v, err = %v", n.titles, err)
+	}
+}
+
 func TestColor_Values(t *testing.T) {
 	if ColorGreen != 5763719 {
 		t.Errorf("ColorGreen = %d, want 5763719", ColorGreen)
@@ -0,0 +1,39 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsPermanent(t *testing.T) {
+	permanent := Permanent(errors.New("render body: missing key"))
+	temporary := errors.New("send request: connection refused")
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain", temporary, false},
+		{"permanent", permanent, true},
+		{"wrapped", fmt.Errorf("webhook: %w", permanent), true},
+		{"all joined permanent", errors.Join(permanent, statusError(404, temporary)), true},
+		{"one joined temporary", errors.Join(permanent, temporary), false},
+		{"status 400", statusError(400, temporary), true},
+		{"status 408", statusError(408, temporary), false},
+		{"status 429", statusError(429, temporary), false},
+		{"status 503", statusError(503, temporary), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPermanent(tt.err); got != tt.want {
+				t.Errorf("IsPermanent(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+	if Permanent(nil) != nil {
+		t.Error("Permanent(nil) != nil")
+	}
+}
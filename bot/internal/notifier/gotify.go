@@ -0,0 +1,81 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GotifyNotifier sends notifications to a Gotify server
+type GotifyNotifier struct {
+	baseURL string
+	token   string
+	client  httpClient
+}
+
+// GotifyOption configures GotifyNotifier
+type GotifyOption func(*GotifyNotifier)
+
+// WithGotifyHTTPClient sets a custom HTTP client
+func WithGotifyHTTPClient(c httpClient) GotifyOption {
+	return func(n *GotifyNotifier) {
+		n.client = c
+	}
+}
+
+// NewGotifyNotifier creates a notifier for the Gotify server at baseURL,
+// sending as the application with the given token
+func NewGotifyNotifier(baseURL, token string, opts ...GotifyOption) *GotifyNotifier {
+	n := &GotifyNotifier{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+type gotifyMessage struct {
+	Title    string         `json:"title"`
+	Message  string         `json:"message"`
+	Priority int            `json:"priority"`
+	Extras   map[string]any `json:"extras"`
+}
+
+// gotifyPriority maps a severity to Gotify's 0-10 priorities; the Android
+// app makes a sound from 4 and pops up from 8
+var gotifyPriority = map[Severity]int{
+	SeverityCritical: 8,
+	SeverityWarning:  5,
+	SeverityRecovery: 3,
+	SeverityInfo:     1,
+}
+
+// Send sends a notification
+func (g *GotifyNotifier) Send(title, message string, color Color, fields []Field) error {
+	header := make(http.Header)
+	header.Set("X-Gotify-Key", g.token)
+	status, body, err := postJSON(g.client, g.baseURL+"/message", header, gotifyMessage{
+		Title:    title,
+		Message:  markdownBody(message, fields),
+		Priority: gotifyPriority[color.Severity()],
+		Extras: map[string]any{
+			"client::display": map[string]string{"contentType": "text/markdown"},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if status < 200 || status >= 300 {
+		var reply struct {
+			Error       string `json:"error"`
+			Description string `json:"errorDescription"`
+		}
+		json.Unmarshal(body, &reply)
+		return statusError(status, fmt.Errorf("gotify API error: status %d: %s", status, strings.TrimSpace(reply.Error+" "+reply.Description)))
+	}
+	return nil
+}
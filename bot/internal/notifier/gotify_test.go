@@ -0,0 +1,58 @@
+package notifier
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGotifyNotifier_Send(t *testing.T) {
+	var got gotifyMessage
+	var path, key string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, key = r.URL.Path, r.Header.Get("X-Gotify-Key")
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer srv.Close()
+
+	n := NewGotifyNotifier(srv.URL+"/gotify/", "AppToken")
+	if err := n.Send("コスト警告", "```\n$12.00\n```", ColorYellow, nil); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if path != "/gotify/message" || key != "AppToken" {
+		t.Errorf("path = %q, key = %q", path, key)
+	}
+	if got.Title != "コスト警告" || got.Message != "```\n$12.00\n```" || got.Priority != 5 {
+		t.Errorf("message = %+v", got)
+	}
+	display, _ := got.Extras["client::display"].(map[string]any)
+	if display["contentType"] != "text/markdown" {
+		t.Errorf("extras = %v", got.Extras)
+	}
+}
+
+func TestGotifyNotifier_Priority(t *testing.T) {
+	want := map[Color]int{ColorRed: 8, ColorYellow: 5, ColorGreen: 3, ColorBlue: 1}
+	for color, priority := range want {
+		if got := gotifyPriority[color.Severity()]; got != priority {
+			t.Errorf("priority of %s = %d, want %d", color.Severity(), got, priority)
+		}
+	}
+}
+
+func TestGotifyNotifier_Send_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"Unauthorized","errorCode":401,"errorDescription":"you need to provide a valid access token"}`))
+	}))
+	defer srv.Close()
+
+	err := NewGotifyNotifier(srv.URL, "bad").Send("t", "m", ColorRed, nil)
+	if err == nil || !strings.Contains(err.Error(), "valid access token") {
+		t.Errorf("Send() error = %v", err)
+	}
+}
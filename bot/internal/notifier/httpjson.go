@@ -0,0 +1,50 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// postJSON posts payload as JSON and returns the status and the start of
+// the reply. Errors building the request are permanent.
+func postJSON(client httpClient, url string, header http.Header, payload any) (int, []byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, nil, Permanent(fmt.Errorf("marshal payload: %w", err))
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, Permanent(fmt.Errorf("create request: %w", err))
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+	reply, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return resp.StatusCode, reply, nil
+}
+
+// markdownBody appends the fields to a message as Markdown lines
+func markdownBody(message string, fields []Field) string {
+	if len(fields) == 0 {
+		return message
+	}
+	lines := make([]string, len(fields))
+	for i, f := range fields {
+		lines[i] = fmt.Sprintf("**%s:** %s", f.Name, f.Value)
+	}
+	if message = strings.TrimRight(message, "\n"); message != "" {
+		return message + "\n\n" + strings.Join(lines, "\n")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,110 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const defaultNtfyBaseURL = "https://ntfy.sh"
+
+// NtfyNotifier publishes notifications to an ntfy topic
+type NtfyNotifier struct {
+	baseURL string
+	topic   string
+	token   string
+	client  httpClient
+}
+
+// NtfyOption configures NtfyNotifier
+type NtfyOption func(*NtfyNotifier)
+
+// WithNtfyBaseURL sets the ntfy server, e.g. a self-hosted one
+func WithNtfyBaseURL(u string) NtfyOption {
+	return func(n *NtfyNotifier) {
+		n.baseURL = strings.TrimRight(u, "/")
+	}
+}
+
+// WithNtfyToken sets the access token of a protected topic
+func WithNtfyToken(token string) NtfyOption {
+	return func(n *NtfyNotifier) {
+		n.token = token
+	}
+}
+
+// WithNtfyHTTPClient sets a custom HTTP client
+func WithNtfyHTTPClient(c httpClient) NtfyOption {
+	return func(n *NtfyNotifier) {
+		n.client = c
+	}
+}
+
+// NewNtfyNotifier creates a notifier that publishes to topic
+func NewNtfyNotifier(topic string, opts ...NtfyOption) *NtfyNotifier {
+	n := &NtfyNotifier{
+		baseURL: defaultNtfyBaseURL,
+		topic:   topic,
+		client:  http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// ntfyMessage is the ntfy JSON publish structure
+type ntfyMessage struct {
+	Topic    string   `json:"topic"`
+	Title    string   `json:"title"`
+	Message  string   `json:"message"`
+	Priority int      `json:"priority"`
+	Tags     []string `json:"tags"`
+	Markdown bool     `json:"markdown"`
+}
+
+// ntfyPriority and ntfyTags map a severity to ntfy's 1 (min) to 5 (max)
+// priorities and to the emoji tags shown before the title
+var (
+	ntfyPriority = map[Severity]int{
+		SeverityCritical: 5,
+		SeverityWarning:  4,
+		SeverityRecovery: 3,
+		SeverityInfo:     2,
+	}
+	ntfyTags = map[Severity]string{
+		SeverityCritical: "rotating_light",
+		SeverityWarning:  "warning",
+		SeverityRecovery: "white_check_mark",
+		SeverityInfo:     "information_source",
+	}
+)
+
+// Send publishes a notification
+func (n *NtfyNotifier) Send(title, message string, color Color, fields []Field) error {
+	header := make(http.Header)
+	if n.token != "" {
+		header.Set("Authorization", "Bearer "+n.token)
+	}
+	sev := color.Severity()
+	status, body, err := postJSON(n.client, n.baseURL, header, ntfyMessage{
+		Topic:    n.topic,
+		Title:    title,
+		Message:  markdownBody(message, fields),
+		Priority: ntfyPriority[sev],
+		Tags:     []string{ntfyTags[sev], string(sev)},
+		Markdown: true,
+	})
+	if err != nil {
+		return err
+	}
+	if status < 200 || status >= 300 {
+		var reply struct {
+			Error string `json:"error"`
+		}
+		json.Unmarshal(body, &reply)
+		return statusError(status, fmt.Errorf("ntfy API error: status %d: %s", status, reply.Error))
+	}
+	return nil
+}
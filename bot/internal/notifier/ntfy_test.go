@@ -0,0 +1,71 @@
+package notifier
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNtfyNotifier_Send(t *testing.T) {
+	tests := []struct {
+		color        Color
+		wantPriority int
+		wantTag      string
+	}{
+		{ColorRed, 5, "rotating_light"},
+		{ColorYellow, 4, "warning"},
+		{ColorGreen, 3, "white_check_mark"},
+		{ColorBlue, 2, "information_source"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantTag, func(t *testing.T) {
+			var got ntfyMessage
+			var auth string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				auth = r.Header.Get("Authorization")
+				if r.URL.Path != "/" {
+					t.Errorf("path = %q, want the JSON publish endpoint", r.URL.Path)
+				}
+				json.NewDecoder(r.Body).Decode(&got)
+				w.Write([]byte(`{"id":"x"}`))
+			}))
+			defer srv.Close()
+
+			n := NewNtfyNotifier("pervigil-alerts", WithNtfyBaseURL(srv.URL), WithNtfyToken("tk_abc"))
+			fields := []Field{{Name: "Temperature", Value: "92°C"}}
+			if err := n.Send("NIC温度", "eth1", tt.color, fields); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			if got.Topic != "pervigil-alerts" || got.Title != "NIC温度" || !got.Markdown {
+				t.Errorf("message = %+v", got)
+			}
+			if got.Message != "eth1\n\n**Temperature:** 92°C" {
+				t.Errorf("message body = %q", got.Message)
+			}
+			if got.Priority != tt.wantPriority || !slices.Contains(got.Tags, tt.wantTag) {
+				t.Errorf("priority = %d, tags = %v; want %d, %s", got.Priority, got.Tags, tt.wantPriority, tt.wantTag)
+			}
+			if auth != "Bearer tk_abc" {
+				t.Errorf("Authorization = %q", auth)
+			}
+		})
+	}
+}
+
+func TestNtfyNotifier_Send_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"code":40301,"http":403,"error":"forbidden"}`))
+	}))
+	defer srv.Close()
+
+	err := NewNtfyNotifier("t", WithNtfyBaseURL(srv.URL)).Send("t", "m", ColorRed, nil)
+	if err == nil || !strings.Contains(err.Error(), "forbidden") {
+		t.Errorf("Send() error = %v", err)
+	}
+}
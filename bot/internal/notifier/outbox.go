@@ -0,0 +1,328 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/murata-lab/pervigil/bot/internal/atomicfile"
+)
+
+// Outbox defaults
+const (
+	DefaultOutboxMaxSize = 100
+	DefaultRetryMin      = 30 * time.Second
+	DefaultRetryMax      = 30 * time.Minute
+)
+
+// outboxEntry is a queued notification
+type outboxEntry struct {
+	ID          uint64       `json:"id"`
+	Title       string       `json:"title"`
+	Message     string       `json:"message"`
+	Color       Color        `json:"color"`
+	Fields      []Field      `json:"fields,omitempty"`
+	Files       []Attachment `json:"files,omitempty"`
+	QueuedAt    time.Time    `json:"queued_at"`
+	Attempts    int          `json:"attempts"`
+	NextAttempt time.Time    `json:"next_attempt"`
+	LastError   string       `json:"last_error"`
+}
+
+// outboxFile is the on-disk JSON structure
+type outboxFile struct {
+	Seq     uint64        `json:"seq"`
+	Entries []outboxEntry `json:"entries"` // oldest first
+}
+
+// OutboxStats reports the queue of one outbox
+type OutboxStats struct {
+	Queued    int
+	Oldest    time.Time // zero when the queue is empty
+	Late      int64     // queued alerts delivered since startup
+	Dropped   int64     // alerts dropped from a full queue or on a permanent failure since startup
+	LastError string
+}
+
+// Outbox is a notifier that queues the notifications the next notifier
+// fails to send in a file and retries them with exponential backoff and
+// jitter, so an outage of the notification service loses no alerts. The
+// queue survives restarts; when full, the oldest alerts are dropped.
+// Permanent failures (see IsPermanent) are never retried.
+type Outbox struct {
+	name     string // backend name used in logs
+	next     Notifier
+	path     string
+	maxSize  int
+	retryMin time.Duration
+	retryMax time.Duration
+	nowFunc  func() time.Time
+	randFunc func() float64
+
+	flushMu sync.Mutex // one Flush at a time
+
+	mu      sync.Mutex
+	queue   outboxFile
+	late    int64
+	dropped int64
+}
+
+// OutboxOption configures Outbox
+type OutboxOption func(*Outbox)
+
+// WithOutboxMaxSize sets how many alerts are queued
+func WithOutboxMaxSize(n int) OutboxOption {
+	return func(o *Outbox) {
+		o.maxSize = n
+	}
+}
+
+// WithOutboxBackoff sets the delay before the first retry, doubled per
+// attempt up to retryMax
+func WithOutboxBackoff(retryMin, retryMax time.Duration) OutboxOption {
+	return func(o *Outbox) {
+		o.retryMin = retryMin
+		o.retryMax = retryMax
+	}
+}
+
+// WithOutboxNowFunc sets a custom time source (for testing)
+func WithOutboxNowFunc(f func() time.Time) OutboxOption {
+	return func(o *Outbox) {
+		o.nowFunc = f
+	}
+}
+
+// WithOutboxRandFunc sets the jitter source returning [0, 1) (for testing)
+func WithOutboxRandFunc(f func() float64) OutboxOption {
+	return func(o *Outbox) {
+		o.randFunc = f
+	}
+}
+
+// NewOutbox wraps next, queueing failed notifications in the file at path.
+// Alerts queued by a previous run are loaded for retry.
+func NewOutbox(name string, next Notifier, path string, opts ...OutboxOption) (*Outbox, error) {
+	o := &Outbox{
+		name:     name,
+		next:     next,
+		path:     path,
+		maxSize:  DefaultOutboxMaxSize,
+		retryMin: DefaultRetryMin,
+		retryMax: DefaultRetryMax,
+		nowFunc:  time.Now,
+		randFunc: rand.Float64,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &o.queue); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return o, nil
+}
+
+// Send sends through the next notifier, queueing the notification if that fails
+func (o *Outbox) Send(title, message string, color Color, fields []Field) error {
+	return o.SendWithAttachments(title, message, color, fields, nil)
+}
+
+// SendWithAttachments sends through the next notifier, queueing the
+// notification if that fails. It fails on a permanent failure, which is
+// not queued, or if the queue cannot be saved.
+func (o *Outbox) SendWithAttachments(title, message string, color Color, fields []Field, files []Attachment) error {
+	err := SendWithAttachments(o.next, title, message, color, fields, files)
+	if err == nil {
+		return nil
+	}
+	if IsPermanent(err) {
+		o.mu.Lock()
+		o.dropped++
+		o.mu.Unlock()
+		log.Printf("notify %s: %v; dropped %q, retrying cannot succeed", o.name, err, title)
+		return err
+	}
+
+	now := o.nowFunc()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.queue.Seq++
+	o.queue.Entries = append(o.queue.Entries, outboxEntry{
+		ID:          o.queue.Seq,
+		Title:       title,
+		Message:     message,
+		Color:       color,
+		Fields:      fields,
+		Files:       files,
+		QueuedAt:    now,
+		Attempts:    1,
+		NextAttempt: now.Add(o.backoff(1)),
+		LastError:   err.Error(),
+	})
+	if over := len(o.queue.Entries) - o.maxSize; over > 0 {
+		for _, e := range o.queue.Entries[:over] {
+			log.Printf("notify %s: queue full, dropped %q queued at %s", o.name, e.Title, e.QueuedAt.Format(time.DateTime))
+		}
+		o.queue.Entries = slices.Delete(o.queue.Entries, 0, over)
+		o.dropped += int64(over)
+	}
+	if serr := o.save(); serr != nil {
+		return errors.Join(err, fmt.Errorf("queue: %w", serr))
+	}
+	log.Printf("notify %s: %v; queued %q for retry", o.name, err, title)
+	return nil
+}
+
+// Flush retries the queued notifications that are due, oldest first. It
+// stops at the first failure so a service that is down is not hammered;
+// a notification that fails permanently is dropped instead.
+func (o *Outbox) Flush(ctx context.Context) error {
+	o.flushMu.Lock()
+	defer o.flushMu.Unlock()
+
+	o.mu.Lock()
+	now := o.nowFunc()
+	var due []outboxEntry
+	for _, e := range o.queue.Entries {
+		if !e.NextAttempt.After(now) {
+			due = append(due, e)
+		}
+	}
+	o.mu.Unlock()
+
+	delivered, dropped := 0, 0
+	var delay time.Duration // longest delay of the delivered alerts
+	var sendErr error
+	for _, e := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		sendErr = SendWithAttachments(o.next, e.Title, e.Message, e.Color, lateFields(e), e.Files)
+		permanent := IsPermanent(sendErr)
+
+		o.mu.Lock()
+		// The entry is gone if a full queue dropped it meanwhile
+		if i := slices.IndexFunc(o.queue.Entries, func(q outboxEntry) bool { return q.ID == e.ID }); i >= 0 {
+			switch {
+			case sendErr == nil:
+				o.queue.Entries = slices.Delete(o.queue.Entries, i, i+1)
+				o.late++
+				delivered++
+				delay = max(delay, o.nowFunc().Sub(e.QueuedAt))
+			case permanent:
+				o.queue.Entries = slices.Delete(o.queue.Entries, i, i+1)
+				o.dropped++
+				dropped++
+				log.Printf("notify %s: %v; dropped %q queued at %s, retrying cannot succeed", o.name, sendErr, e.Title, e.QueuedAt.Format(time.DateTime))
+			default:
+				q := &o.queue.Entries[i]
+				q.Attempts++
+				q.NextAttempt = o.nowFunc().Add(o.backoff(q.Attempts))
+				q.LastError = sendErr.Error()
+			}
+		}
+		o.mu.Unlock()
+		if permanent {
+			sendErr = nil
+			continue
+		}
+		if sendErr != nil {
+			break
+		}
+	}
+
+	if delivered > 0 {
+		log.Printf("notify %s: delivered %d queued alerts late (up to %s)", o.name, delivered, delay.Round(time.Second))
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if delivered > 0 || dropped > 0 || sendErr != nil {
+		if err := o.save(); err != nil {
+			return fmt.Errorf("%s: save queue: %w", o.name, err)
+		}
+	}
+	if sendErr != nil {
+		return fmt.Errorf("%s: %d queued, retry failed: %w", o.name, len(o.queue.Entries), sendErr)
+	}
+	return nil
+}
+
+// Stats returns the queue length and delivery counts
+func (o *Outbox) Stats() OutboxStats {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	s := OutboxStats{Queued: len(o.queue.Entries), Late: o.late, Dropped: o.dropped}
+	if len(o.queue.Entries) > 0 {
+		s.Oldest = o.queue.Entries[0].QueuedAt
+		s.LastError = o.queue.Entries[0].LastError
+	}
+	return s
+}
+
+// backoff returns the delay before the given attempt: retryMin doubled per
+// earlier attempt up to retryMax, of which up to half is random jitter
+func (o *Outbox) backoff(attempts int) time.Duration {
+	d := o.retryMin
+	for i := 1; i < attempts && d < o.retryMax; i++ {
+		d *= 2
+	}
+	d = min(d, o.retryMax)
+	return d/2 + time.Duration(o.randFunc()*float64(d/2))
+}
+
+// save writes the queue; the caller holds o.mu. The file is replaced
+// atomically so a crash never leaves a truncated queue.
+func (o *Outbox) save() error {
+	data, err := json.Marshal(o.queue)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(o.path, data, 0600)
+}
+
+// lateFields adds when a retried alert originally fired
+func lateFields(e outboxEntry) []Field {
+	return append(slices.Clone(e.Fields), Field{
+		Name:  "遅延配信",
+		Value: fmt.Sprintf("%s に発生 (%d回目の送信)", e.QueuedAt.Local().Format(time.DateTime), e.Attempts+1),
+	})
+}
+
+// Outboxes are the outboxes of all notification backends
+type Outboxes map[string]*Outbox
+
+// Flush retries the due notifications of every outbox concurrently
+func (ob Outboxes) Flush(ctx context.Context) error {
+	names := slices.Sorted(maps.Keys(ob))
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Go(func() { errs[i] = ob[name].Flush(ctx) })
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Stats returns the stats of every outbox, keyed by backend name
+func (ob Outboxes) Stats() map[string]OutboxStats {
+	stats := make(map[string]OutboxStats, len(ob))
+	for name, o := range ob {
+		stats[name] = o.Stats()
+	}
+	return stats
+}
@@ -0,0 +1,270 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// flakyNotifier fails while down is set, permanently if err is set, and
+// records what it delivers
+type flakyNotifier struct {
+	down  bool
+	err   error
+	calls []flakyCall
+	files [][]Attachment
+}
+
+type flakyCall struct {
+	title  string
+	fields []Field
+}
+
+func (f *flakyNotifier) Send(title, message string, color Color, fields []Field) error {
+	return f.SendWithAttachments(title, message, color, fields, nil)
+}
+
+func (f *flakyNotifier) SendWithAttachments(title, message string, color Color, fields []Field, files []Attachment) error {
+	if f.err != nil {
+		return f.err
+	}
+	if f.down {
+		return errors.New("webhook down")
+	}
+	f.calls = append(f.calls, flakyCall{title, fields})
+	f.files = append(f.files, files)
+	return nil
+}
+
+func TestOutbox_RetriesAcrossRestart(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "discord.json")
+	next := &flakyNotifier{down: true}
+	opts := []OutboxOption{
+		WithOutboxBackoff(time.Minute, 10*time.Minute),
+		WithOutboxNowFunc(func() time.Time { return now }),
+		WithOutboxRandFunc(func() float64 { return 1 }),
+	}
+	o, err := NewOutbox("discord", next, path, opts...)
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+
+	chart := []Attachment{{Name: "nic-temp.png", ContentType: "image/png", Data: []byte{0x89, 'P'}}}
+	if err := o.SendWithAttachments("ログ異常検知", "```\nkernel: oops\n```", ColorRed, nil, chart); err != nil {
+		t.Fatalf("SendWithAttachments() error = %v, want queued", err)
+	}
+	if s := o.Stats(); s.Queued != 1 || s.LastError != "webhook down" {
+		t.Errorf("Stats() = %+v", s)
+	}
+
+	// Not due yet
+	now = now.Add(30 * time.Second)
+	if err := o.Flush(context.Background()); err != nil || len(next.calls) != 0 {
+		t.Fatalf("Flush() error = %v, calls = %d", err, len(next.calls))
+	}
+
+	// Restart with the service back
+	next.down = false
+	o, err = NewOutbox("discord", next, path, opts...)
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+	now = now.Add(time.Minute)
+	if err := o.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(next.calls) != 1 || next.calls[0].title != "ログ異常検知" {
+		t.Fatalf("calls = %+v", next.calls)
+	}
+	fields := next.calls[0].fields
+	if len(fields) != 1 || fields[0].Name != "遅延配信" || !strings.Contains(fields[0].Value, "2回目") {
+		t.Errorf("fields = %+v, want the late delivery note", fields)
+	}
+	if len(next.files) != 1 || next.files[0][0].Name != "nic-temp.png" || string(next.files[0][0].Data) != "\x89P" {
+		t.Errorf("files = %+v, want the chart", next.files)
+	}
+	if s := o.Stats(); s.Queued != 0 || s.Late != 1 {
+		t.Errorf("Stats() = %+v, want 1 late", s)
+	}
+
+	o, err = NewOutbox("discord", next, path, opts...)
+	if err != nil || o.Stats().Queued != 0 {
+		t.Errorf("queue after delivery = %+v, %v; want empty", o.Stats(), err)
+	}
+}
+
+func TestOutbox_Backoff(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	next := &flakyNotifier{down: true}
+	o, err := NewOutbox("slack", next, filepath.Join(t.TempDir(), "slack.json"),
+		WithOutboxBackoff(time.Minute, 5*time.Minute),
+		WithOutboxNowFunc(func() time.Time { return now }),
+		WithOutboxRandFunc(func() float64 { return 1 }),
+	)
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+	o.Send("Test", "Message", ColorYellow, nil)
+
+	// Delays without jitter: 1m, 2m, 4m, then capped at 5m
+	for i, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute} {
+		next := o.queue.Entries[0].NextAttempt
+		if got := next.Sub(now); got != want {
+			t.Fatalf("delay %d = %s, want %s", i+1, got, want)
+		}
+		now = next
+		if err := o.Flush(context.Background()); err == nil || !strings.Contains(err.Error(), "webhook down") {
+			t.Fatalf("Flush() error = %v", err)
+		}
+	}
+
+	jitter := func(r float64) time.Duration {
+		o.randFunc = func() float64 { return r }
+		return o.backoff(2)
+	}
+	if lo, hi := jitter(0), jitter(0.999); lo != time.Minute || hi <= lo || hi > 2*time.Minute {
+		t.Errorf("jitter range = %s..%s, want 1m..2m", lo, hi)
+	}
+}
+
+func TestOutbox_MaxSize(t *testing.T) {
+	next := &flakyNotifier{down: true}
+	o, err := NewOutbox("discord", next, filepath.Join(t.TempDir(), "discord.json"),
+		WithOutboxMaxSize(2),
+		WithOutboxBackoff(0, 0),
+	)
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+	for _, title := range []string{"a", "b", "c"} {
+		o.Send(title, "", ColorRed, nil)
+	}
+	if s := o.Stats(); s.Queued != 2 || s.Dropped != 1 {
+		t.Errorf("Stats() = %+v, want 2 queued, 1 dropped", s)
+	}
+
+	next.down = false
+	if err := o.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(next.calls) != 2 || next.calls[0].title != "b" || next.calls[1].title != "c" {
+		t.Errorf("calls = %+v, want b then c", next.calls)
+	}
+}
+
+func TestOutbox_StopsAtFirstFailure(t *testing.T) {
+	next := &flakyNotifier{down: true}
+	o, err := NewOutbox("discord", next, filepath.Join(t.TempDir(), "discord.json"), WithOutboxBackoff(0, 0))
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+	o.Send("a", "", ColorRed, nil)
+	o.Send("b", "", ColorRed, nil)
+
+	err = o.Flush(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "2 queued") {
+		t.Errorf("Flush() error = %v", err)
+	}
+	if a, b := o.queue.Entries[0].Attempts, o.queue.Entries[1].Attempts; a != 2 || b != 1 {
+		t.Errorf("attempts = %d, %d; want only the first retried", a, b)
+	}
+}
+
+func TestOutbox_SendDelivered(t *testing.T) {
+	next := &flakyNotifier{}
+	path := filepath.Join(t.TempDir(), "discord.json")
+	o, err := NewOutbox("discord", next, path)
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+	if err := o.Send("Test", "Message", ColorGreen, nil); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(next.calls) != 1 || len(next.calls[0].fields) != 0 {
+		t.Errorf("calls = %+v", next.calls)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("queue file written without a failure: %v", err)
+	}
+}
+
+func TestOutbox_SaveError(t *testing.T) {
+	o, err := NewOutbox("discord", &flakyNotifier{down: true}, filepath.Join(t.TempDir(), "missing", "discord.json"))
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+	if err := o.Send("Test", "Message", ColorRed, nil); err == nil || !strings.Contains(err.Error(), "webhook down") {
+		t.Errorf("Send() error = %v, want the send error when queueing fails", err)
+	}
+}
+
+func TestNewOutbox_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "discord.json")
+	os.WriteFile(path, []byte("{"), 0600)
+	if _, err := NewOutbox("discord", &flakyNotifier{}, path); err == nil {
+		t.Error("NewOutbox() accepted a corrupt queue")
+	}
+}
+
+func TestOutboxes(t *testing.T) {
+	dir := t.TempDir()
+	up, down := &flakyNotifier{}, &flakyNotifier{down: true}
+	a, _ := NewOutbox("a", up, filepath.Join(dir, "a.json"), WithOutboxBackoff(0, 0))
+	b, _ := NewOutbox("b", down, filepath.Join(dir, "b.json"), WithOutboxBackoff(0, 0))
+	up.down = true
+	a.Send("x", "", ColorRed, nil)
+	b.Send("y", "", ColorRed, nil)
+	up.down = false
+
+	boxes := Outboxes{"a": a, "b": b}
+	err := boxes.Flush(context.Background())
+	if err == nil || !strings.HasPrefix(err.Error(), "b: ") {
+		t.Errorf("Flush() error = %v, want b to fail", err)
+	}
+	stats := boxes.Stats()
+	if stats["a"].Late != 1 || stats["b"].Queued != 1 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+func TestOutbox_PermanentErrorDropped(t *testing.T) {
+	next := &flakyNotifier{err: Permanent(errors.New("webhook error: status 404"))}
+	path := filepath.Join(t.TempDir(), "webhook.json")
+	o, err := NewOutbox("webhook", next, path, WithOutboxBackoff(0, 0))
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+	if err := o.Send("Test", "Message", ColorRed, nil); !IsPermanent(err) {
+		t.Errorf("Send() error = %v, want the permanent error", err)
+	}
+	if s := o.Stats(); s.Queued != 0 || s.Dropped != 1 {
+		t.Errorf("Stats() = %+v, want 1 dropped and nothing queued", s)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("queue file written for a permanent failure: %v", err)
+	}
+}
+
+func TestOutbox_FlushDropsPermanentError(t *testing.T) {
+	next := &flakyNotifier{down: true}
+	o, err := NewOutbox("webhook", next, filepath.Join(t.TempDir(), "webhook.json"), WithOutboxBackoff(0, 0))
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+	o.Send("a", "", ColorRed, nil)
+	o.Send("b", "", ColorRed, nil)
+
+	// The webhook was deleted meanwhile; retrying cannot help
+	next.err = Permanent(errors.New("webhook error: status 404"))
+	if err := o.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v, want permanent failures dropped", err)
+	}
+	if s := o.Stats(); s.Queued != 0 || s.Dropped != 2 {
+		t.Errorf("Stats() = %+v, want both dropped", s)
+	}
+}
@@ -0,0 +1,213 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"sync"
+)
+
+// Severity classifies a notification by its color
+type Severity string
+
+const (
+	SeverityCritical Severity = "critical" // ColorRed
+	SeverityWarning  Severity = "warning"  // ColorYellow
+	SeverityRecovery Severity = "recovery" // ColorGreen
+	SeverityInfo     Severity = "info"     // ColorBlue
+)
+
+// Severities lists every severity from the most to the least urgent
+var Severities = []Severity{SeverityCritical, SeverityWarning, SeverityRecovery, SeverityInfo}
+
+// Severity returns the severity a color stands for
+func (c Color) Severity() Severity {
+	switch c {
+	case ColorRed:
+		return SeverityCritical
+	case ColorYellow:
+		return SeverityWarning
+	case ColorGreen:
+		return SeverityRecovery
+	default:
+		return SeverityInfo
+	}
+}
+
+// SourceSender is implemented by notifiers that use the component a
+// notification comes from, such as Router
+type SourceSender interface {
+	SendFrom(source, title, message string, color Color, fields []Field, files []Attachment) error
+}
+
+// sourced tags every notification with a source
+type sourced struct {
+	next   Notifier
+	source string
+}
+
+// From returns a notifier that sends through n as source (e.g. "nic").
+// The source is dropped when n does not implement SourceSender.
+func From(n Notifier, source string) Notifier {
+	return &sourced{next: n, source: source}
+}
+
+func (s *sourced) Send(title, message string, color Color, fields []Field) error {
+	return s.SendWithAttachments(title, message, color, fields, nil)
+}
+
+func (s *sourced) SendWithAttachments(title, message string, color Color, fields []Field, files []Attachment) error {
+	if ss, ok := s.next.(SourceSender); ok {
+		return ss.SendFrom(s.source, title, message, color, fields, files)
+	}
+	return SendWithAttachments(s.next, title, message, color, fields, files)
+}
+
+// SendFrom passes notifications from a source through n, keeping the
+// source when n uses it
+func SendFrom(n Notifier, source, title, message string, color Color, fields []Field, files []Attachment) error {
+	if source != "" {
+		n = From(n, source)
+	}
+	return SendWithAttachments(n, title, message, color, fields, files)
+}
+
+// Route sends the notifications it matches to named backends. Empty
+// criteria match everything.
+type Route struct {
+	Sources    []string
+	Severities []Severity
+	Title      *regexp.Regexp
+	To         []string // backend names
+}
+
+// Matches reports whether the route applies to a notification
+func (r Route) Matches(source, title string, color Color) bool {
+	if len(r.Sources) > 0 && !slices.Contains(r.Sources, source) {
+		return false
+	}
+	if len(r.Severities) > 0 && !slices.Contains(r.Severities, color.Severity()) {
+		return false
+	}
+	return r.Title == nil || r.Title.MatchString(title)
+}
+
+// Router fans notifications out to backends by rules. A notification goes
+// to the backends of every matching route, or to the fallback backends when
+// no route matches. Backends are sent to concurrently, so one slow or
+// failing backend never holds up delivery to the others.
+type Router struct {
+	backends map[string]Notifier
+	routes   []Route
+	fallback []string
+	onError  func(backend string, err error)
+}
+
+// RouterOption configures Router
+type RouterOption func(*Router)
+
+// WithRoute adds a route; routes are matched in the order they are added
+func WithRoute(r Route) RouterOption {
+	return func(rt *Router) {
+		rt.routes = append(rt.routes, r)
+	}
+}
+
+// WithFallback sets the backends of notifications no route matches
+func WithFallback(backends ...string) RouterOption {
+	return func(rt *Router) {
+		rt.fallback = backends
+	}
+}
+
+// WithRouteErrorHandler sets the function told about every backend that
+// failed while another delivered (default: log)
+func WithRouteErrorHandler(f func(backend string, err error)) RouterOption {
+	return func(rt *Router) {
+		rt.onError = f
+	}
+}
+
+// NewRouter creates a router over named backends. It fails when a route
+// or the fallback names an unknown backend.
+func NewRouter(backends map[string]Notifier, opts ...RouterOption) (*Router, error) {
+	r := &Router{
+		backends: backends,
+		onError: func(backend string, err error) {
+			log.Printf("notify %s: %v", backend, err)
+		},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	for _, route := range append([]Route{{To: r.fallback}}, r.routes...) {
+		for _, name := range route.To {
+			if _, ok := backends[name]; !ok {
+				return nil, fmt.Errorf("unknown notification backend %q", name)
+			}
+		}
+	}
+	return r, nil
+}
+
+// Send routes a notification without a source
+func (r *Router) Send(title, message string, color Color, fields []Field) error {
+	return r.SendFrom("", title, message, color, fields, nil)
+}
+
+// SendWithAttachments routes a notification without a source
+func (r *Router) SendWithAttachments(title, message string, color Color, fields []Field, files []Attachment) error {
+	return r.SendFrom("", title, message, color, fields, files)
+}
+
+// SendFrom delivers a notification to the backends its routes select.
+// It fails only when no backend delivered it; other failures are passed
+// to the error handler so that a retry does not repeat delivered alerts.
+func (r *Router) SendFrom(source, title, message string, color Color, fields []Field, files []Attachment) error {
+	targets := r.targets(source, title, color)
+	if len(targets) == 0 {
+		return nil
+	}
+
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, name := range targets {
+		wg.Go(func() {
+			errs[i] = SendFrom(r.backends[name], source, title, message, color, fields, files)
+		})
+	}
+	wg.Wait()
+
+	var failed []error
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, fmt.Errorf("%s: %w", targets[i], err))
+		}
+	}
+	if len(failed) == len(targets) {
+		return errors.Join(failed...)
+	}
+	for i, err := range errs {
+		if err != nil {
+			r.onError(targets[i], err)
+		}
+	}
+	return nil
+}
+
+// targets returns the backends a notification goes to, each once
+func (r *Router) targets(source, title string, color Color) []string {
+	var targets []string
+	for _, route := range r.routes {
+		if route.Matches(source, title, color) {
+			targets = append(targets, route.To...)
+		}
+	}
+	if len(targets) == 0 {
+		targets = slices.Clone(r.fallback)
+	}
+	slices.Sort(targets)
+	return slices.Compact(targets)
+}
@@ -0,0 +1,177 @@
+package notifier
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// recordingNotifier records the titles it is sent
+type recordingNotifier struct {
+	mu     sync.Mutex
+	titles []string
+	files  int
+	err    error
+}
+
+func (r *recordingNotifier) Send(title, message string, color Color, fields []Field) error {
+	return r.SendWithAttachments(title, message, color, fields, nil)
+}
+
+func (r *recordingNotifier) SendWithAttachments(title, message string, color Color, fields []Field, files []Attachment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.titles = append(r.titles, title)
+	r.files += len(files)
+	return nil
+}
+
+func (r *recordingNotifier) got() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.titles)
+}
+
+func TestColor_Severity(t *testing.T) {
+	tests := map[Color]Severity{
+		ColorRed:    SeverityCritical,
+		ColorYellow: SeverityWarning,
+		ColorGreen:  SeverityRecovery,
+		ColorBlue:   SeverityInfo,
+	}
+	for color, want := range tests {
+		if got := color.Severity(); got != want {
+			t.Errorf("Color(%d).Severity() = %s, want %s", color, got, want)
+		}
+	}
+}
+
+func TestRouter_SendFrom(t *testing.T) {
+	pager, quiet, finance, general := &recordingNotifier{}, &recordingNotifier{}, &recordingNotifier{}, &recordingNotifier{}
+	r, err := NewRouter(map[string]Notifier{
+		"pager":   pager,
+		"quiet":   quiet,
+		"finance": finance,
+		"general": general,
+	},
+		WithRoute(Route{Sources: []string{"nic", "sfp"}, Severities: []Severity{SeverityCritical}, To: []string{"pager", "general"}}),
+		WithRoute(Route{Sources: []string{"log"}, Severities: []Severity{SeverityWarning}, To: []string{"quiet"}}),
+		WithRoute(Route{Sources: []string{"cost"}, To: []string{"finance"}}),
+		WithRoute(Route{Title: regexp.MustCompile(`^🔥`), To: []string{"pager", "general"}}),
+		WithFallback("general"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sends := []struct {
+		source, title string
+		color         Color
+	}{
+		{"nic", "🔥 critical", ColorRed},
+		{"nic", "warning", ColorYellow},
+		{"log", "log warning", ColorYellow},
+		{"log", "log error", ColorRed},
+		{"cost", "budget", ColorYellow},
+		{"", "untagged", ColorBlue},
+	}
+	for _, s := range sends {
+		if err := r.SendFrom(s.source, s.title, "msg", s.color, nil, nil); err != nil {
+			t.Errorf("SendFrom(%s, %s) error = %v", s.source, s.title, err)
+		}
+	}
+
+	want := map[string]struct {
+		n      *recordingNotifier
+		titles []string
+	}{
+		"pager":   {pager, []string{"🔥 critical"}},
+		"quiet":   {quiet, []string{"log warning"}},
+		"finance": {finance, []string{"budget"}},
+		"general": {general, []string{"🔥 critical", "warning", "log error", "untagged"}},
+	}
+	for name, w := range want {
+		if got := w.n.got(); !slices.Equal(got, w.titles) {
+			t.Errorf("%s got %q, want %q", name, got, w.titles)
+		}
+	}
+}
+
+func TestRouter_IsolatesFailures(t *testing.T) {
+	good, bad := &recordingNotifier{}, &recordingNotifier{err: errors.New("webhook down")}
+	var failed []string
+	r, err := NewRouter(map[string]Notifier{"good": good, "bad": bad},
+		WithFallback("bad", "good"),
+		WithRouteErrorHandler(func(backend string, err error) {
+			failed = append(failed, backend)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.SendWithAttachments("alert", "msg", ColorRed, nil, []Attachment{{Name: "chart.png"}}); err != nil {
+		t.Errorf("SendWithAttachments() error = %v, want nil while one backend delivers", err)
+	}
+	if got := good.got(); len(got) != 1 || good.files != 1 {
+		t.Errorf("good backend got %q with %d files", got, good.files)
+	}
+	if !slices.Equal(failed, []string{"bad"}) {
+		t.Errorf("failed backends = %q, want [bad]", failed)
+	}
+
+	good.err = errors.New("timeout")
+	failed = nil
+	err = r.Send("alert", "msg", ColorRed, nil)
+	if err == nil {
+		t.Fatal("Send() error = nil, want an error when every backend fails")
+	}
+	if len(failed) != 0 {
+		t.Errorf("error handler called for %q; the error is returned instead", failed)
+	}
+}
+
+func TestNewRouter_UnknownBackend(t *testing.T) {
+	backends := map[string]Notifier{"discord": &recordingNotifier{}}
+	if _, err := NewRouter(backends, WithRoute(Route{To: []string{"slack"}})); err == nil {
+		t.Error("NewRouter() with an unknown route backend should fail")
+	}
+	if _, err := NewRouter(backends, WithFallback("pager")); err == nil {
+		t.Error("NewRouter() with an unknown fallback backend should fail")
+	}
+}
+
+// sourceRecorder records the source of every notification
+type sourceRecorder struct {
+	recordingNotifier
+	sources []string
+}
+
+func (s *sourceRecorder) SendFrom(source, title, message string, color Color, fields []Field, files []Attachment) error {
+	s.sources = append(s.sources, source)
+	return s.SendWithAttachments(title, message, color, fields, files)
+}
+
+func TestFrom(t *testing.T) {
+	rec := &sourceRecorder{}
+	if err := From(rec, "cost").Send("budget", "msg", ColorYellow, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(rec.sources, []string{"cost"}) {
+		t.Errorf("sources = %q, want [cost]", rec.sources)
+	}
+
+	// Notifiers that do not use the source still get the notification
+	plain := &recordingNotifier{}
+	if err := From(plain, "cost").Send("budget", "msg", ColorYellow, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := plain.got(); !slices.Equal(got, []string{"budget"}) {
+		t.Errorf("plain notifier got %q", got)
+	}
+}
@@ -0,0 +1,230 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Slack Block Kit limits
+const (
+	slackHeaderLen      = 150
+	slackTextLen        = 3000
+	slackFieldLen       = 2000
+	slackFieldsPerBlock = 10
+)
+
+const (
+	defaultSlackRetries  = 2
+	defaultSlackMaxDelay = 30 * time.Second
+)
+
+// SlackNotifier sends notifications via a Slack incoming webhook. Files
+// are not attached, since incoming webhooks cannot upload them.
+type SlackNotifier struct {
+	webhookURL string
+	client     httpClient
+	retries    int
+	maxDelay   time.Duration
+	sleep      func(time.Duration)
+	nowFunc    func() time.Time
+}
+
+// SlackOption configures SlackNotifier
+type SlackOption func(*SlackNotifier)
+
+// WithSlackHTTPClient sets a custom HTTP client
+func WithSlackHTTPClient(c httpClient) SlackOption {
+	return func(n *SlackNotifier) {
+		n.client = c
+	}
+}
+
+// WithSlackRetries sets how often a rate-limited message is retried and
+// the longest Retry-After waited for; a longer one fails at once
+func WithSlackRetries(retries int, maxDelay time.Duration) SlackOption {
+	return func(n *SlackNotifier) {
+		n.retries = retries
+		n.maxDelay = maxDelay
+	}
+}
+
+// WithSlackSleep sets the function that waits before a retry (for testing)
+func WithSlackSleep(f func(time.Duration)) SlackOption {
+	return func(n *SlackNotifier) {
+		n.sleep = f
+	}
+}
+
+// WithSlackNowFunc sets a custom time source (for testing)
+func WithSlackNowFunc(f func() time.Time) SlackOption {
+	return func(n *SlackNotifier) {
+		n.nowFunc = f
+	}
+}
+
+// NewSlackNotifier creates a new Slack notifier
+func NewSlackNotifier(webhookURL string, opts ...SlackOption) *SlackNotifier {
+	n := &SlackNotifier{
+		webhookURL: webhookURL,
+		client:     http.DefaultClient,
+		retries:    defaultSlackRetries,
+		maxDelay:   defaultSlackMaxDelay,
+		sleep:      time.Sleep,
+		nowFunc:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// slackPayload is the Slack incoming webhook JSON structure. The blocks go
+// in an attachment so the message gets a color bar.
+type slackPayload struct {
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+type slackAttachment struct {
+	Fallback string       `json:"fallback"` // shown in push notifications
+	Color    string       `json:"color"`
+	Blocks   []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"` // plain_text or mrkdwn
+	Text string `json:"text"`
+}
+
+// Send sends a notification to Slack, waiting out rate limits
+func (s *SlackNotifier) Send(title, message string, color Color, fields []Field) error {
+	body, err := json.Marshal(s.payload(title, message, color, fields))
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := s.post(body)
+		if retryAfter == 0 {
+			return err
+		}
+		if attempt >= s.retries || retryAfter > s.maxDelay {
+			return fmt.Errorf("%w (retry after %s)", err, retryAfter)
+		}
+		s.sleep(retryAfter)
+	}
+}
+
+// payload builds the message: a header, the message, the fields and the
+// time it was sent
+func (s *SlackNotifier) payload(title, message string, color Color, fields []Field) slackPayload {
+	blocks := []slackBlock{{
+		Type: "header",
+		Text: &slackText{Type: "plain_text", Text: truncate(title, slackHeaderLen)},
+	}}
+	if message != "" {
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: truncate(slackEscape(message), slackTextLen)},
+		})
+	}
+
+	// Inline fields share two-column sections; the others get a row each
+	var inline []slackText
+	flush := func() {
+		for chunk := range slices.Chunk(inline, slackFieldsPerBlock) {
+			blocks = append(blocks, slackBlock{Type: "section", Fields: chunk})
+		}
+		inline = nil
+	}
+	for _, f := range fields {
+		text := slackText{Type: "mrkdwn", Text: truncate("*"+slackEscape(f.Name)+"*\n"+slackEscape(f.Value), slackFieldLen)}
+		if f.Inline {
+			inline = append(inline, text)
+			continue
+		}
+		flush()
+		blocks = append(blocks, slackBlock{Type: "section", Text: &text})
+	}
+	flush()
+
+	now := s.nowFunc()
+	blocks = append(blocks, slackBlock{
+		Type: "context",
+		Elements: []slackText{{
+			Type: "mrkdwn",
+			Text: fmt.Sprintf("<!date^%d^{date_short_pretty} {time_secs}|%s>", now.Unix(), now.UTC().Format(time.RFC3339)),
+		}},
+	})
+
+	return slackPayload{
+		Attachments: []slackAttachment{{
+			Fallback: title,
+			Color:    fmt.Sprintf("#%06x", int(color)),
+			Blocks:   blocks,
+		}},
+	}
+}
+
+// post sends the payload once. On 429 it returns how long Slack asks to
+// wait before retrying.
+func (s *SlackNotifier) post(body []byte) (time.Duration, error) {
+	req, err := http.NewRequest(http.MethodPost, s.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, Permanent(fmt.Errorf("create request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+	reply, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return retryAfter(resp.Header.Get("Retry-After")), errors.New("slack API error: rate limited")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, statusError(resp.StatusCode, fmt.Errorf("slack API error: status %d: %s", resp.StatusCode, strings.TrimSpace(string(reply))))
+	}
+	return 0, nil
+}
+
+// retryAfter parses a Retry-After header in seconds; Slack always sends
+// one with 429, a missing one waits a second
+func retryAfter(h string) time.Duration {
+	secs, err := strconv.Atoi(strings.TrimSpace(h))
+	if err != nil || secs < 1 {
+		return time.Second
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// slackEscape escapes the characters Slack mrkdwn treats as control
+// sequences
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,137 @@
+package notifier
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlackNotifier_Send_PayloadFormat(t *testing.T) {
+	var got slackPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	n := NewSlackNotifier(srv.URL, WithSlackNowFunc(func() time.Time { return now }))
+	fields := []Field{
+		{Name: "Temperature", Value: "92°C", Inline: true},
+		{Name: "Threshold", Value: "90°C", Inline: true},
+		{Name: "Lines", Value: "a < b & c"},
+	}
+	if err := n.Send("NIC温度危険", "```\neth1 <critical>\n```", ColorRed, fields); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if len(got.Attachments) != 1 {
+		t.Fatalf("attachments = %+v", got.Attachments)
+	}
+	a := got.Attachments[0]
+	if a.Color != "#ed4245" || a.Fallback != "NIC温度危険" {
+		t.Errorf("color = %q, fallback = %q", a.Color, a.Fallback)
+	}
+	types := make([]string, len(a.Blocks))
+	for i, b := range a.Blocks {
+		types[i] = b.Type
+	}
+	if strings.Join(types, ",") != "header,section,section,section,context" {
+		t.Fatalf("blocks = %v", types)
+	}
+	if h := a.Blocks[0].Text; h.Type != "plain_text" || h.Text != "NIC温度危険" {
+		t.Errorf("header = %+v", h)
+	}
+	if msg := a.Blocks[1].Text.Text; msg != "```\neth1 &lt;critical&gt;\n```" {
+		t.Errorf("message = %q, want escaped", msg)
+	}
+	if f := a.Blocks[2].Fields; len(f) != 2 || f[0].Text != "*Temperature*\n92°C" {
+		t.Errorf("inline fields = %+v", f)
+	}
+	if f := a.Blocks[3].Text; f.Text != "*Lines*\na &lt; b &amp; c" {
+		t.Errorf("field = %+v", f)
+	}
+	if ts := a.Blocks[4].Elements[0].Text; !strings.Contains(ts, "<!date^1772366400^") {
+		t.Errorf("timestamp = %q", ts)
+	}
+}
+
+func TestSlackNotifier_Send_RateLimited(t *testing.T) {
+	tests := []struct {
+		name       string
+		limited    int // 429 responses before success
+		retryAfter string
+		wantErr    bool
+		wantSleeps []time.Duration
+	}{
+		{"retried", 2, "3", false, []time.Duration{3 * time.Second, 3 * time.Second}},
+		{"retries exhausted", 3, "1", true, []time.Duration{time.Second, time.Second}},
+		{"wait too long", 1, "120", true, nil},
+		{"missing header", 1, "", false, []time.Duration{time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls <= tt.limited {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.Write([]byte("ok"))
+			}))
+			defer srv.Close()
+
+			var sleeps []time.Duration
+			n := NewSlackNotifier(srv.URL, WithSlackSleep(func(d time.Duration) { sleeps = append(sleeps, d) }))
+			err := n.Send("Test", "Message", ColorYellow, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(sleeps) != len(tt.wantSleeps) {
+				t.Fatalf("slept %v, want %v", sleeps, tt.wantSleeps)
+			}
+			for i := range sleeps {
+				if sleeps[i] != tt.wantSleeps[i] {
+					t.Errorf("slept %v, want %v", sleeps, tt.wantSleeps)
+				}
+			}
+		})
+	}
+}
+
+func TestSlackNotifier_Send_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("invalid_blocks"))
+	}))
+	defer srv.Close()
+
+	err := NewSlackNotifier(srv.URL).Send("Test", "Message", ColorGreen, nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid_blocks") {
+		t.Errorf("Send() error = %v, want the Slack reason", err)
+	}
+}
+
+func TestSlackNotifier_Truncates(t *testing.T) {
+	p := NewSlackNotifier("").payload(strings.Repeat("あ", 200), strings.Repeat("x", 4000), ColorBlue, nil)
+	blocks := p.Attachments[0].Blocks
+	if n := len([]rune(blocks[0].Text.Text)); n != slackHeaderLen {
+		t.Errorf("header length = %d, want %d", n, slackHeaderLen)
+	}
+	if n := len([]rune(blocks[1].Text.Text)); n != slackTextLen {
+		t.Errorf("message length = %d, want %d", n, slackTextLen)
+	}
+}
@@ -0,0 +1,431 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"math/rand/v2"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+)
+
+// SMTPSecurity is how the connection to the mail server is protected
+type SMTPSecurity int
+
+const (
+	SMTPStartTLS    SMTPSecurity = iota // upgrade a plain connection (port 587)
+	SMTPImplicitTLS                     // TLS from the start (port 465)
+	SMTPPlain                           // no TLS, e.g. a local relay on port 25
+)
+
+// SMTPAuthMechanism is how the notifier logs in to the mail server
+type SMTPAuthMechanism int
+
+const (
+	SMTPAuthPlain SMTPAuthMechanism = iota
+	SMTPAuthLogin
+)
+
+const defaultSMTPTimeout = 30 * time.Second
+
+// SMTPNotifier sends notifications as mail with a plain-text and an HTML
+// body. Attachments such as charts are attached to the mail.
+type SMTPNotifier struct {
+	addr      string // host:port
+	from      string
+	to        []string
+	security  SMTPSecurity
+	auth      smtp.Auth
+	tlsConfig *tls.Config
+	timeout   time.Duration
+	hostname  string
+	nowFunc   func() time.Time
+}
+
+// SMTPOption configures SMTPNotifier
+type SMTPOption func(*SMTPNotifier)
+
+// WithSMTPSecurity sets how the connection is protected (default: STARTTLS)
+func WithSMTPSecurity(s SMTPSecurity) SMTPOption {
+	return func(n *SMTPNotifier) {
+		n.security = s
+	}
+}
+
+// WithSMTPAuth logs in with the given mechanism. Credentials are only sent
+// over TLS or to localhost.
+func WithSMTPAuth(mech SMTPAuthMechanism, username, password string) SMTPOption {
+	return func(n *SMTPNotifier) {
+		host, _, _ := net.SplitHostPort(n.addr)
+		switch mech {
+		case SMTPAuthLogin:
+			n.auth = &loginAuth{username: username, password: password, host: host}
+		default:
+			n.auth = smtp.PlainAuth("", username, password, host)
+		}
+	}
+}
+
+// WithSMTPTLSConfig sets the TLS configuration, e.g. to trust a private CA
+func WithSMTPTLSConfig(c *tls.Config) SMTPOption {
+	return func(n *SMTPNotifier) {
+		n.tlsConfig = c
+	}
+}
+
+// WithSMTPTimeout sets the deadline of one delivery, including the dial
+func WithSMTPTimeout(d time.Duration) SMTPOption {
+	return func(n *SMTPNotifier) {
+		n.timeout = d
+	}
+}
+
+// WithSMTPNowFunc sets a custom time source (for testing)
+func WithSMTPNowFunc(f func() time.Time) SMTPOption {
+	return func(n *SMTPNotifier) {
+		n.nowFunc = f
+	}
+}
+
+// NewSMTPNotifier creates a notifier that mails from to every address in
+// to through the server at addr (host:port)
+func NewSMTPNotifier(addr, from string, to []string, opts ...SMTPOption) *SMTPNotifier {
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "localhost"
+	}
+	n := &SMTPNotifier{
+		addr:     addr,
+		from:     from,
+		to:       to,
+		timeout:  defaultSMTPTimeout,
+		hostname: hostname,
+		nowFunc:  time.Now,
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// Send mails a notification
+func (s *SMTPNotifier) Send(title, message string, color Color, fields []Field) error {
+	return s.SendWithAttachments(title, message, color, fields, nil)
+}
+
+// SendWithAttachments mails a notification with files attached
+func (s *SMTPNotifier) SendWithAttachments(title, message string, color Color, fields []Field, files []Attachment) error {
+	msg, err := s.message(title, message, color, fields, files)
+	if err != nil {
+		return Permanent(fmt.Errorf("build message: %w", err))
+	}
+	if err := s.deliver(msg); err != nil {
+		return fmt.Errorf("smtp %s: %w", s.addr, err)
+	}
+	return nil
+}
+
+// deliver runs one SMTP session
+func (s *SMTPNotifier) deliver(msg []byte) error {
+	host, _, err := net.SplitHostPort(s.addr)
+	if err != nil {
+		return err
+	}
+	tlsConfig := s.tlsConfig.Clone()
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = host
+	}
+
+	dialer := &net.Dialer{Timeout: s.timeout}
+	var conn net.Conn
+	if s.security == SMTPImplicitTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", s.addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", s.addr)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(s.timeout))
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if err := c.Hello(s.hostname); err != nil {
+		return err
+	}
+	if s.security == SMTPStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return errors.New("server does not support STARTTLS")
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+	if s.auth != nil {
+		if err := c.Auth(s.auth); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+	if err := c.Mail(envelope(s.from)); err != nil {
+		return fmt.Errorf("mail from %s: %w", s.from, err)
+	}
+	for _, rcpt := range s.to {
+		if err := c.Rcpt(envelope(rcpt)); err != nil {
+			err = fmt.Errorf("rcpt to %s: %w", rcpt, err)
+			// 5xx rejects the recipient for good; 4xx is a temporary failure
+			var te *textproto.Error
+			if errors.As(err, &te) && te.Code >= 500 {
+				return Permanent(err)
+			}
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// message renders the mail: multipart/alternative with a plain-text and an
+// HTML body, inside multipart/mixed when there are files
+func (s *SMTPNotifier) message(title, message string, color Color, fields []Field, files []Attachment) ([]byte, error) {
+	var buf bytes.Buffer
+	now := s.nowFunc()
+	to := make([]string, len(s.to))
+	for i, rcpt := range s.to {
+		to[i] = addressHeader(rcpt)
+	}
+	header := []string{
+		"From: " + addressHeader(s.from),
+		"To: " + strings.Join(to, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", title),
+		"Date: " + now.Format(time.RFC1123Z),
+		fmt.Sprintf("Message-ID: <%d.%x@%s>", now.UnixNano(), rand.Uint64(), s.hostname),
+		"MIME-Version: 1.0",
+	}
+	for _, h := range header {
+		buf.WriteString(h + "\r\n")
+	}
+
+	var alt bytes.Buffer
+	altWriter := multipart.NewWriter(&alt)
+	if err := writeBodies(altWriter, title, message, color, fields); err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", altWriter.Boundary())
+		buf.Write(alt.Bytes())
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mixed.Boundary())
+	part, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + altWriter.Boundary()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(alt.Bytes()); err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if err := attach(mixed, f); err != nil {
+			return nil, err
+		}
+	}
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// envelope returns the bare address of "Name <addr>"
+func envelope(addr string) string {
+	if a, err := mail.ParseAddress(addr); err == nil {
+		return a.Address
+	}
+	return addr
+}
+
+// addressHeader formats an address for a header, encoding a non-ASCII name
+func addressHeader(addr string) string {
+	if a, err := mail.ParseAddress(addr); err == nil {
+		return a.String()
+	}
+	return addr
+}
+
+// writeBodies writes the plain-text and HTML bodies and closes w
+func writeBodies(w *multipart.Writer, title, message string, color Color, fields []Field) error {
+	var html bytes.Buffer
+	if err := mailHTML.Execute(&html, newMailData(title, message, color, fields)); err != nil {
+		return err
+	}
+	bodies := []struct {
+		contentType string
+		text        string
+	}{
+		{"text/plain; charset=utf-8", mailText(title, message, fields)},
+		{"text/html; charset=utf-8", html.String()},
+	}
+	for _, b := range bodies {
+		part, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {b.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return err
+		}
+		qp := quotedprintable.NewWriter(part)
+		if _, err := qp.Write([]byte(b.text)); err != nil {
+			return err
+		}
+		if err := qp.Close(); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// attach adds a file as a base64 attachment
+func attach(w *multipart.Writer, f Attachment) error {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {f.ContentType},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": f.Name})},
+	})
+	if err != nil {
+		return err
+	}
+	// RFC 2045 limits encoded lines to 76 characters
+	encoded := base64.StdEncoding.EncodeToString(f.Data)
+	for len(encoded) > 0 {
+		n := min(76, len(encoded))
+		if _, err := io.WriteString(part, encoded[:n]+"\r\n"); err != nil {
+			return err
+		}
+		encoded = encoded[n:]
+	}
+	return nil
+}
+
+// mailBlock is a paragraph or a code block of a message
+type mailBlock struct {
+	Text string
+	Code bool
+}
+
+type mailData struct {
+	Title  string
+	Color  string
+	Blocks []mailBlock
+	Fields []Field
+}
+
+// newMailData splits the message at its ``` fences for the HTML body
+func newMailData(title, message string, color Color, fields []Field) mailData {
+	d := mailData{Title: title, Color: fmt.Sprintf("#%06x", int(color)), Fields: fields}
+	for i, text := range strings.Split(message, "```") {
+		if text = strings.Trim(text, "\n"); text != "" {
+			d.Blocks = append(d.Blocks, mailBlock{Text: text, Code: i%2 == 1})
+		}
+	}
+	return d
+}
+
+var mailHTML = template.Must(template.New("mail").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif">
+<div style="border-left: 4px solid {{.Color}}; padding-left: 12px">
+<h2 style="margin: 0 0 8px">{{.Title}}</h2>
+{{- range .Blocks}}
+{{if .Code}}<pre style="background: #f4f4f4; padding: 8px">{{.Text}}</pre>{{else}}<p style="white-space: pre-wrap">{{.Text}}</p>{{end}}
+{{- end}}
+{{- if .Fields}}
+<table>
+{{- range .Fields}}
+<tr><th align="left" style="padding-right: 12px">{{.Name}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</div>
+</body>
+</html>
+`))
+
+// mailText renders the plain-text body, dropping the ``` fences
+func mailText(title, message string, fields []Field) string {
+	var body strings.Builder
+	for line := range strings.Lines(message) {
+		if strings.TrimSpace(line) != "```" {
+			body.WriteString(line)
+		}
+	}
+	var b strings.Builder
+	b.WriteString(title + "\n\n" + strings.TrimRight(body.String(), "\n") + "\n")
+	if len(fields) > 0 {
+		b.WriteString("\n")
+		for _, f := range fields {
+			fmt.Fprintf(&b, "%s: %s\n", f.Name, f.Value)
+		}
+	}
+	return b.String()
+}
+
+// loginAuth implements AUTH LOGIN, which net/smtp lacks. Like
+// smtp.PlainAuth it only sends credentials over TLS or to localhost.
+type loginAuth struct {
+	username, password, host string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && server.Name != "localhost" && server.Name != "127.0.0.1" && server.Name != "::1" {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch challenge := strings.ToLower(string(fromServer)); {
+	case strings.HasPrefix(challenge, "user"):
+		return []byte(a.username), nil
+	case strings.HasPrefix(challenge, "pass"):
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected challenge %q", fromServer)
+	}
+}
@@ -0,0 +1,363 @@
+package notifier
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTP is an in-process SMTP server that records what it receives
+type fakeSMTP struct {
+	addr        string
+	tlsConfig   *tls.Config // server side
+	implicitTLS bool
+	noStartTLS  bool
+	rejectRcpt  string
+
+	mu    sync.Mutex
+	mails []fakeMail
+	auths []string // "PLAIN user:pass", "LOGIN user:pass"
+}
+
+type fakeMail struct {
+	from   string
+	to     []string
+	data   string
+	secure bool
+}
+
+// newFakeSMTP starts a server; configure it before the first connection
+func newFakeSMTP(t *testing.T, configure func(*fakeSMTP)) (*fakeSMTP, *tls.Config) {
+	t.Helper()
+	cert, pool := testCertificate(t)
+	s := &fakeSMTP{tlsConfig: &tls.Config{Certificates: []tls.Certificate{cert}}}
+	if configure != nil {
+		configure(s)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.implicitTLS {
+		ln = tls.NewListener(ln, s.tlsConfig)
+	}
+	t.Cleanup(func() { ln.Close() })
+	s.addr = ln.Addr().String()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s, &tls.Config{RootCAs: pool}
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer func() { conn.Close() }()
+	tp := textproto.NewConn(conn)
+	secure := s.implicitTLS
+	var current fakeMail
+	tp.PrintfLine("220 fake ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(cmd) {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250-fake")
+			if !secure && !s.noStartTLS {
+				tp.PrintfLine("250-STARTTLS")
+			}
+			tp.PrintfLine("250 AUTH PLAIN LOGIN")
+		case "STARTTLS":
+			tp.PrintfLine("220 ready")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, tp, secure = tlsConn, textproto.NewConn(tlsConn), true
+		case "AUTH":
+			if !s.auth(tp, arg) {
+				tp.PrintfLine("535 authentication failed")
+				continue
+			}
+			tp.PrintfLine("235 ok")
+		case "MAIL":
+			current = fakeMail{from: strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>"), secure: secure}
+			tp.PrintfLine("250 ok")
+		case "RCPT":
+			rcpt := strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>")
+			if rcpt == s.rejectRcpt {
+				tp.PrintfLine("550 no such user")
+				continue
+			}
+			current.to = append(current.to, rcpt)
+			tp.PrintfLine("250 ok")
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			current.data = string(data)
+			s.mu.Lock()
+			s.mails = append(s.mails, current)
+			s.mu.Unlock()
+			tp.PrintfLine("250 queued")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+// auth runs AUTH PLAIN or LOGIN and accepts user/secret
+func (s *fakeSMTP) auth(tp *textproto.Conn, arg string) bool {
+	mech, initial, _ := strings.Cut(arg, " ")
+	read := func(challenge string) string {
+		tp.PrintfLine("334 %s", base64.StdEncoding.EncodeToString([]byte(challenge)))
+		line, _ := tp.ReadLine()
+		b, _ := base64.StdEncoding.DecodeString(line)
+		return string(b)
+	}
+	var user, pass string
+	switch mech {
+	case "PLAIN":
+		b, _ := base64.StdEncoding.DecodeString(initial)
+		parts := strings.Split(string(b), "\x00")
+		if len(parts) != 3 {
+			return false
+		}
+		user, pass = parts[1], parts[2]
+	case "LOGIN":
+		user, pass = read("Username:"), read("Password:")
+	default:
+		return false
+	}
+	s.mu.Lock()
+	s.auths = append(s.auths, mech+" "+user+":"+pass)
+	s.mu.Unlock()
+	return user == "user" && pass == "secret"
+}
+
+func (s *fakeSMTP) received() ([]fakeMail, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mails, s.auths
+}
+
+// testCertificate creates a self-signed certificate for 127.0.0.1
+func testCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+// mailParts parses a received mail and returns its headers and the
+// decoded parts of its multipart body, keyed by content type
+func mailParts(t *testing.T, data string) (mail.Header, map[string]string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("parse mail: %v", err)
+	}
+	parts := make(map[string]string)
+	var walk func(contentType string, body io.Reader)
+	walk = func(contentType string, body io.Reader) {
+		mediaType, params, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			t.Fatalf("Content-Type %q: %v", contentType, err)
+		}
+		if !strings.HasPrefix(mediaType, "multipart/") {
+			b, _ := io.ReadAll(body)
+			parts[mediaType] = string(b)
+			return
+		}
+		parts[mediaType] = ""
+		r := multipart.NewReader(body, params["boundary"])
+		for {
+			p, err := r.NextPart()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				t.Fatalf("read part: %v", err)
+			}
+			var pbody io.Reader = p
+			if p.Header.Get("Content-Transfer-Encoding") == "base64" {
+				pbody = base64.NewDecoder(base64.StdEncoding, p)
+			}
+			walk(p.Header.Get("Content-Type"), pbody)
+		}
+	}
+	walk(msg.Header.Get("Content-Type"), msg.Body)
+	return msg.Header, parts
+}
+
+func TestSMTPNotifier_StartTLS(t *testing.T) {
+	srv, clientTLS := newFakeSMTP(t, nil)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	n := NewSMTPNotifier(srv.addr, "Pervigil <pervigil@example.com>", []string{"ops@example.com", "oncall@example.com"},
+		WithSMTPTLSConfig(clientTLS),
+		WithSMTPAuth(SMTPAuthPlain, "user", "secret"),
+		WithSMTPNowFunc(func() time.Time { return now }),
+	)
+
+	fields := []Field{{Name: "Temperature", Value: "92°C", Inline: true}}
+	if err := n.Send("🔥 NIC温度危険 - router", "eth1 が危険温度です\n```\nload <high> & rising\n```", ColorRed, fields); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	mails, auths := srv.received()
+	if len(mails) != 1 {
+		t.Fatalf("received %d mails, want 1", len(mails))
+	}
+	m := mails[0]
+	if !m.secure || m.from != "pervigil@example.com" || strings.Join(m.to, ",") != "ops@example.com,oncall@example.com" {
+		t.Errorf("envelope = %+v", m)
+	}
+	if len(auths) != 1 || auths[0] != "PLAIN user:secret" {
+		t.Errorf("auths = %q", auths)
+	}
+
+	header, parts := mailParts(t, m.data)
+	subject, _ := new(mime.WordDecoder).DecodeHeader(header.Get("Subject"))
+	if subject != "🔥 NIC温度危険 - router" {
+		t.Errorf("Subject = %q", subject)
+	}
+	if header.Get("To") != "<ops@example.com>, <oncall@example.com>" || header.Get("Date") != "Sun, 01 Mar 2026 12:00:00 +0000" {
+		t.Errorf("header = %v", header)
+	}
+	if _, ok := parts["multipart/alternative"]; !ok {
+		t.Errorf("parts = %v, want multipart/alternative", parts)
+	}
+	plain := parts["text/plain"]
+	if !strings.Contains(plain, "load <high> & rising") || strings.Contains(plain, "```") || !strings.Contains(plain, "Temperature: 92°C") {
+		t.Errorf("plain body = %q", plain)
+	}
+	html := parts["text/html"]
+	for _, want := range []string{"#ed4245", "<pre", "load &lt;high&gt; &amp; rising", "<td>92°C</td>"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML body missing %q:\n%s", want, html)
+		}
+	}
+}
+
+func TestSMTPNotifier_ImplicitTLSLogin(t *testing.T) {
+	srv, clientTLS := newFakeSMTP(t, func(s *fakeSMTP) { s.implicitTLS = true })
+	n := NewSMTPNotifier(srv.addr, "pervigil@example.com", []string{"ops@example.com"},
+		WithSMTPSecurity(SMTPImplicitTLS),
+		WithSMTPTLSConfig(clientTLS),
+		WithSMTPAuth(SMTPAuthLogin, "user", "secret"),
+	)
+	chart := []byte("\x89PNG fake chart data")
+	err := n.SendWithAttachments("NIC温度", "msg", ColorYellow, nil, []Attachment{{Name: "nic-temp.png", ContentType: "image/png", Data: chart}})
+	if err != nil {
+		t.Fatalf("SendWithAttachments() error = %v", err)
+	}
+
+	mails, auths := srv.received()
+	if len(mails) != 1 || !mails[0].secure {
+		t.Fatalf("mails = %+v", mails)
+	}
+	if len(auths) != 1 || auths[0] != "LOGIN user:secret" {
+		t.Errorf("auths = %q", auths)
+	}
+	_, parts := mailParts(t, mails[0].data)
+	for _, want := range []string{"multipart/mixed", "multipart/alternative", "text/plain", "text/html"} {
+		if _, ok := parts[want]; !ok {
+			t.Errorf("missing %s part", want)
+		}
+	}
+	if parts["image/png"] != string(chart) {
+		t.Errorf("attachment = %q, want %q", parts["image/png"], chart)
+	}
+}
+
+func TestSMTPNotifier_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*fakeSMTP)
+		password  string
+		want      string
+		permanent bool
+	}{
+		{"no STARTTLS", func(s *fakeSMTP) { s.noStartTLS = true }, "secret", "STARTTLS", false},
+		{"wrong password", nil, "wrong", "auth", false},
+		{"rejected recipient", func(s *fakeSMTP) { s.rejectRcpt = "oncall@example.com" }, "secret", "oncall@example.com", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, clientTLS := newFakeSMTP(t, tt.configure)
+			n := NewSMTPNotifier(srv.addr, "pervigil@example.com", []string{"ops@example.com", "oncall@example.com"},
+				WithSMTPTLSConfig(clientTLS),
+				WithSMTPAuth(SMTPAuthPlain, "user", tt.password),
+			)
+			err := n.Send("Test", "Message", ColorGreen, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Send() error = %v, want one mentioning %q", err, tt.want)
+			}
+			if IsPermanent(err) != tt.permanent {
+				t.Errorf("IsPermanent(%v) = %v, want %v", err, !tt.permanent, tt.permanent)
+			}
+			if mails, _ := srv.received(); len(mails) != 0 {
+				t.Errorf("mail delivered despite the error: %+v", mails)
+			}
+		})
+	}
+}
+
+func TestSMTPNotifier_PlainRefusesRemoteAuth(t *testing.T) {
+	// Credentials never cross an unencrypted connection to another host
+	a := &loginAuth{username: "user", password: "secret", host: "mail.example.com"}
+	if _, _, err := a.Start(&smtp.ServerInfo{Name: "mail.example.com"}); err == nil {
+		t.Error("Start() over plain text to a remote host should fail")
+	}
+	if _, _, err := a.Start(&smtp.ServerInfo{Name: "mail.example.com", TLS: true}); err != nil {
+		t.Errorf("Start() over TLS error = %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultTelegramBaseURL = "https://api.telegram.org"
+	// telegramMessageLen caps the message before escaping, which at most
+	// doubles it, so the text stays under Telegram's 4096 characters
+	telegramMessageLen = 1500
+)
+
+// TelegramNotifier sends notifications through the Telegram Bot API
+type TelegramNotifier struct {
+	baseURL string
+	token   string
+	chatID  string
+	client  httpClient
+}
+
+// TelegramOption configures TelegramNotifier
+type TelegramOption func(*TelegramNotifier)
+
+// WithTelegramBaseURL sets the Bot API server, e.g. a local Bot API server
+func WithTelegramBaseURL(u string) TelegramOption {
+	return func(n *TelegramNotifier) {
+		n.baseURL = strings.TrimRight(u, "/")
+	}
+}
+
+// WithTelegramHTTPClient sets a custom HTTP client
+func WithTelegramHTTPClient(c httpClient) TelegramOption {
+	return func(n *TelegramNotifier) {
+		n.client = c
+	}
+}
+
+// NewTelegramNotifier creates a notifier that posts to chatID as the bot
+// with the given token
+func NewTelegramNotifier(token, chatID string, opts ...TelegramOption) *TelegramNotifier {
+	n := &TelegramNotifier{
+		baseURL: defaultTelegramBaseURL,
+		token:   token,
+		chatID:  chatID,
+		client:  http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+type telegramMessage struct {
+	ChatID             string `json:"chat_id"`
+	Text               string `json:"text"`
+	ParseMode          string `json:"parse_mode"`
+	DisableLinkPreview bool   `json:"disable_web_page_preview"`
+}
+
+type telegramReply struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+// Send sends a notification with sendMessage
+func (t *TelegramNotifier) Send(title, message string, color Color, fields []Field) error {
+	status, body, err := postJSON(t.client, t.baseURL+"/bot"+t.token+"/sendMessage", nil, telegramMessage{
+		ChatID:             t.chatID,
+		Text:               telegramText(title, message, fields),
+		ParseMode:          "MarkdownV2",
+		DisableLinkPreview: true,
+	})
+	if err != nil {
+		// The URL holds the token; keep it out of logs
+		return fmt.Errorf("telegram API error: %s", strings.ReplaceAll(err.Error(), t.token, "***"))
+	}
+	var reply telegramReply
+	if json.Unmarshal(body, &reply) != nil || !reply.OK {
+		return statusError(status, fmt.Errorf("telegram API error: status %d: %s", status, reply.Description))
+	}
+	return nil
+}
+
+// telegramText renders the notification in MarkdownV2: a bold title, the
+// message with its ``` blocks kept as code, and the fields
+func telegramText(title, message string, fields []Field) string {
+	var b strings.Builder
+	b.WriteString("*" + telegramEscape(title) + "*")
+	if message = truncate(message, telegramMessageLen); message != "" {
+		b.WriteString("\n\n")
+		for i, part := range strings.Split(message, "```") {
+			if i%2 == 1 {
+				b.WriteString("```\n" + telegramCodeEscape(strings.Trim(part, "\n")) + "\n```")
+			} else {
+				b.WriteString(telegramEscape(part))
+			}
+		}
+	}
+	if len(fields) > 0 {
+		b.WriteString("\n")
+		for _, f := range fields {
+			b.WriteString("\n*" + telegramEscape(f.Name) + ":* " + telegramEscape(f.Value))
+		}
+	}
+	return b.String()
+}
+
+var (
+	telegramEscaper     = strings.NewReplacer(escapePairs(`\_*[]()~` + "`" + `>#+-=|{}.!`)...)
+	telegramCodeEscaper = strings.NewReplacer(escapePairs(`\` + "`")...)
+)
+
+// telegramEscape escapes text outside code for MarkdownV2
+func telegramEscape(s string) string {
+	return telegramEscaper.Replace(s)
+}
+
+// telegramCodeEscape escapes text inside a code block for MarkdownV2
+func telegramCodeEscape(s string) string {
+	return telegramCodeEscaper.Replace(s)
+}
+
+// escapePairs returns replacer pairs that prefix each char with a backslash
+func escapePairs(chars string) []string {
+	var pairs []string
+	for _, c := range chars {
+		pairs = append(pairs, string(c), `\`+string(c))
+	}
+	return pairs
+}
@@ -0,0 +1,62 @@
+package notifier
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTelegramNotifier_Send(t *testing.T) {
+	var got telegramMessage
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		w.Write([]byte(`{"ok":true,"result":{}}`))
+	}))
+	defer srv.Close()
+
+	n := NewTelegramNotifier("123:ABC", "-10042", WithTelegramBaseURL(srv.URL+"/"))
+	fields := []Field{{Name: "Temp (eth1)", Value: "92.5°C"}}
+	if err := n.Send("🔥 NIC温度危険 - router-1", "load_avg > 1.5!\n```\nerr `x` C:\\tmp\n```", ColorRed, fields); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if path != "/bot123:ABC/sendMessage" {
+		t.Errorf("path = %q", path)
+	}
+	if got.ChatID != "-10042" || got.ParseMode != "MarkdownV2" {
+		t.Errorf("chat_id = %q, parse_mode = %q", got.ChatID, got.ParseMode)
+	}
+	want := "*🔥 NIC温度危険 \\- router\\-1*\n\n" +
+		"load\\_avg \\> 1\\.5\\!\n" +
+		"```\nerr \\`x\\` C:\\\\tmp\n```\n\n" +
+		"*Temp \\(eth1\\):* 92\\.5°C"
+	if got.Text != want {
+		t.Errorf("text =\n%s\nwant\n%s", got.Text, want)
+	}
+}
+
+func TestTelegramNotifier_Send_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: can't parse entities"}`))
+	}))
+	defer srv.Close()
+
+	err := NewTelegramNotifier("123:ABC", "1", WithTelegramBaseURL(srv.URL)).Send("t", "m", ColorBlue, nil)
+	if err == nil || !strings.Contains(err.Error(), "can't parse entities") {
+		t.Errorf("Send() error = %v, want Telegram's description", err)
+	}
+
+	// Transport errors must not leak the bot token
+	srv.Close()
+	err = NewTelegramNotifier("123:SECRET", "1", WithTelegramBaseURL(srv.URL)).Send("t", "m", ColorBlue, nil)
+	if err == nil || strings.Contains(err.Error(), "SECRET") {
+		t.Errorf("Send() error = %v, want an error without the token", err)
+	}
+}
@@ -0,0 +1,265 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const defaultSignatureHeader = "X-Pervigil-Signature"
+
+// WebhookNotifier sends notifications as HTTP requests rendered from
+// text/template templates, so any JSON or form API can be called without
+// a dedicated backend
+type WebhookNotifier struct {
+	url     *template.Template
+	method  *template.Template
+	headers map[string]*template.Template
+	body    *template.Template
+
+	secret          []byte
+	signatureHeader string
+	success         []string
+	client          httpClient
+	hostname        string
+	nowFunc         func() time.Time
+}
+
+// WebhookData is the value the webhook templates are executed with
+type WebhookData struct {
+	Title     string
+	Message   string
+	Text      string // the message followed by the fields as Markdown lines
+	Severity  Severity
+	Color     string // "#rrggbb"
+	Fields    []Field
+	Hostname  string
+	Timestamp time.Time
+}
+
+// webhookSpec holds the option values parsed by NewWebhookNotifier
+type webhookSpec struct {
+	method  string
+	headers map[string]string
+	n       *WebhookNotifier
+}
+
+// WebhookOption configures WebhookNotifier
+type WebhookOption func(*webhookSpec)
+
+// WithWebhookMethod sets the method template (default POST)
+func WithWebhookMethod(tmpl string) WebhookOption {
+	return func(s *webhookSpec) {
+		s.method = tmpl
+	}
+}
+
+// WithWebhookHeader adds a header template; a header that renders empty is
+// not sent
+func WithWebhookHeader(name, tmpl string) WebhookOption {
+	return func(s *webhookSpec) {
+		s.headers[name] = tmpl
+	}
+}
+
+// WithWebhookSecret signs every request body with HMAC-SHA256, sending
+// "sha256=<hex>" in header (default X-Pervigil-Signature)
+func WithWebhookSecret(secret, header string) WebhookOption {
+	return func(s *webhookSpec) {
+		s.n.secret = []byte(secret)
+		if header != "" {
+			s.n.signatureHeader = header
+		}
+	}
+}
+
+// WithWebhookSuccess sets the response statuses that count as delivered,
+// e.g. "202" or "2xx" (default 2xx)
+func WithWebhookSuccess(patterns ...string) WebhookOption {
+	return func(s *webhookSpec) {
+		s.n.success = patterns
+	}
+}
+
+// WithWebhookHTTPClient sets a custom HTTP client
+func WithWebhookHTTPClient(c httpClient) WebhookOption {
+	return func(s *webhookSpec) {
+		s.n.client = c
+	}
+}
+
+// WithWebhookNowFunc sets a custom time source (for testing)
+func WithWebhookNowFunc(f func() time.Time) WebhookOption {
+	return func(s *webhookSpec) {
+		s.n.nowFunc = f
+	}
+}
+
+// NewWebhookNotifier creates a notifier that sends the rendered body to the
+// rendered URL. It fails if a template or status pattern is invalid.
+func NewWebhookNotifier(url, body string, opts ...WebhookOption) (*WebhookNotifier, error) {
+	hostname, _ := os.Hostname()
+	n := &WebhookNotifier{
+		headers:         make(map[string]*template.Template),
+		signatureHeader: defaultSignatureHeader,
+		success:         []string{"2xx"},
+		client:          http.DefaultClient,
+		hostname:        hostname,
+		nowFunc:         time.Now,
+	}
+	spec := &webhookSpec{method: http.MethodPost, headers: make(map[string]string), n: n}
+	for _, opt := range opts {
+		opt(spec)
+	}
+
+	var err error
+	if n.url, err = ParseWebhookTemplate(url); err != nil {
+		return nil, fmt.Errorf("url: %w", err)
+	}
+	if n.method, err = ParseWebhookTemplate(spec.method); err != nil {
+		return nil, fmt.Errorf("method: %w", err)
+	}
+	if n.body, err = ParseWebhookTemplate(body); err != nil {
+		return nil, fmt.Errorf("body: %w", err)
+	}
+	for name, tmpl := range spec.headers {
+		if n.headers[name], err = ParseWebhookTemplate(tmpl); err != nil {
+			return nil, fmt.Errorf("header %s: %w", name, err)
+		}
+	}
+	for _, p := range n.success {
+		if !ValidStatusPattern(p) {
+			return nil, fmt.Errorf("invalid status pattern %q (want e.g. 200 or 2xx)", p)
+		}
+	}
+	return n, nil
+}
+
+// webhookFuncs are the functions available to webhook templates besides
+// the text/template builtins
+var webhookFuncs = template.FuncMap{
+	// json encodes a value as JSON, e.g. {"text": {{json .Text}}}
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"truncate": func(n int, s string) string {
+		return truncate(s, n)
+	},
+}
+
+// ParseWebhookTemplate parses a webhook template
+func ParseWebhookTemplate(text string) (*template.Template, error) {
+	return template.New("webhook").Funcs(webhookFuncs).Option("missingkey=error").Parse(text)
+}
+
+// ValidStatusPattern reports whether p is a status such as "204" or a
+// pattern such as "2xx" or "20x"
+func ValidStatusPattern(p string) bool {
+	if len(p) != 3 || p[0] < '1' || p[0] > '5' {
+		return false
+	}
+	for _, c := range p[1:] {
+		if (c < '0' || c > '9') && c != 'x' {
+			return false
+		}
+	}
+	return true
+}
+
+// statusMatches reports whether status matches the pattern p
+func statusMatches(p string, status int) bool {
+	s := fmt.Sprintf("%03d", status)
+	for i := range 3 {
+		if p[i] != 'x' && p[i] != s[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Send renders the request and sends it
+func (w *WebhookNotifier) Send(title, message string, color Color, fields []Field) error {
+	data := WebhookData{
+		Title:     title,
+		Message:   message,
+		Text:      markdownBody(message, fields),
+		Severity:  color.Severity(),
+		Color:     fmt.Sprintf("#%06x", int(color)),
+		Fields:    fields,
+		Hostname:  w.hostname,
+		Timestamp: w.nowFunc(),
+	}
+	req, err := w.request(data)
+	if err != nil {
+		return err
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+	reply, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	if !slices.ContainsFunc(w.success, func(p string) bool { return statusMatches(p, resp.StatusCode) }) {
+		return statusError(resp.StatusCode, fmt.Errorf("webhook error: status %d: %s", resp.StatusCode, strings.TrimSpace(string(reply))))
+	}
+	return nil
+}
+
+// request renders the templates into a request, signing the body if a
+// secret is set
+func (w *WebhookNotifier) request(data WebhookData) (*http.Request, error) {
+	render := func(t *template.Template) (string, error) {
+		var b strings.Builder
+		err := t.Execute(&b, data)
+		return b.String(), err
+	}
+
+	url, err := render(w.url)
+	if err != nil {
+		return nil, Permanent(fmt.Errorf("render url: %w", err))
+	}
+	method, err := render(w.method)
+	if err != nil {
+		return nil, Permanent(fmt.Errorf("render method: %w", err))
+	}
+	body, err := render(w.body)
+	if err != nil {
+		return nil, Permanent(fmt.Errorf("render body: %w", err))
+	}
+
+	req, err := http.NewRequest(strings.ToUpper(strings.TrimSpace(method)), strings.TrimSpace(url), bytes.NewReader([]byte(body)))
+	if err != nil {
+		return nil, Permanent(fmt.Errorf("create request: %w", err))
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+	for name, t := range w.headers {
+		v, err := render(t)
+		if err != nil {
+			return nil, Permanent(fmt.Errorf("render header %s: %w", name, err))
+		}
+		if v = strings.TrimSpace(v); v != "" {
+			req.Header.Set(name, v)
+		}
+	}
+	if len(w.secret) > 0 {
+		mac := hmac.New(sha256.New, w.secret)
+		mac.Write([]byte(body))
+		req.Header.Set(w.signatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	return req, nil
+}
@@ -0,0 +1,191 @@
+package notifier
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifier_Send(t *testing.T) {
+	var method, path, auth, contentType string
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		auth, contentType = r.Header.Get("Authorization"), r.Header.Get("Content-Type")
+		if _, ok := r.Header["X-Empty"]; ok {
+			t.Error("header rendered empty was sent")
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	body := `{"summary": {{json (printf "[%s] %s" .Severity .Title)}}, "text": {{json .Text}},` +
+		` "host": {{json .Hostname}}, "color": {{json .Color}}, "at": {{.Timestamp.Unix}},` +
+		` "first": {{with index .Fields 0}}{{json .Value}}{{end}}}`
+	n, err := NewWebhookNotifier(srv.URL+"/hooks/{{.Severity}}", body,
+		WithWebhookMethod("put"),
+		WithWebhookHeader("Authorization", "Token abc"),
+		WithWebhookHeader("X-Empty", `{{if eq .Severity "info"}}x{{end}}`),
+		WithWebhookNowFunc(func() time.Time { return now }),
+	)
+	if err != nil {
+		t.Fatalf("NewWebhookNotifier() error = %v", err)
+	}
+	fields := []Field{{Name: "Temperature", Value: `92"C`}}
+	if err := n.Send("NIC温度危険", "eth1", ColorRed, fields); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if method != http.MethodPut || path != "/hooks/critical" {
+		t.Errorf("request = %s %s", method, path)
+	}
+	if auth != "Token abc" || !strings.HasPrefix(contentType, "application/json") {
+		t.Errorf("Authorization = %q, Content-Type = %q", auth, contentType)
+	}
+	hostname, _ := os.Hostname()
+	want := map[string]any{
+		"summary": "[critical] NIC温度危険",
+		"text":    "eth1\n\n**Temperature:** 92\"C",
+		"host":    hostname,
+		"color":   "#ed4245",
+		"at":      float64(now.Unix()),
+		"first":   `92"C`,
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %v, want %v", k, got[k], v)
+		}
+	}
+}
+
+func TestWebhookNotifier_Signature(t *testing.T) {
+	var signature string
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-Hub-Signature-256")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	n, err := NewWebhookNotifier(srv.URL, `{"title": {{json .Title}}}`, WithWebhookSecret("s3cret", "X-Hub-Signature-256"))
+	if err != nil {
+		t.Fatalf("NewWebhookNotifier() error = %v", err)
+	}
+	if err := n.Send("Test", "", ColorBlue, nil); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); signature != want {
+		t.Errorf("signature = %q, want %q", signature, want)
+	}
+}
+
+func TestWebhookNotifier_Success(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		success   []string
+		wantErr   bool
+		permanent bool
+	}{
+		{"default 2xx", http.StatusNoContent, nil, false, false},
+		{"default rejects 3xx", http.StatusFound, nil, true, false},
+		{"exact", http.StatusAccepted, []string{"202"}, false, false},
+		{"exact mismatch", http.StatusOK, []string{"202"}, true, false},
+		{"any of", http.StatusConflict, []string{"2xx", "409"}, false, false},
+		{"partial wildcard", http.StatusCreated, []string{"20x"}, false, false},
+		{"not found", http.StatusNotFound, nil, true, true},
+		{"unauthorized", http.StatusUnauthorized, nil, true, true},
+		{"rate limited", http.StatusTooManyRequests, nil, true, false},
+		{"server error", http.StatusBadGateway, nil, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte("dedup key exists"))
+			}))
+			defer srv.Close()
+
+			var opts []WebhookOption
+			if tt.success != nil {
+				opts = append(opts, WithWebhookSuccess(tt.success...))
+			}
+			n, err := NewWebhookNotifier(srv.URL, "{}", opts...)
+			if err != nil {
+				t.Fatalf("NewWebhookNotifier() error = %v", err)
+			}
+			err = n.Send("Test", "Message", ColorGreen, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "dedup key exists") {
+				t.Errorf("Send() error = %v, want the reply", err)
+			}
+			if IsPermanent(err) != tt.permanent {
+				t.Errorf("IsPermanent(%v) = %v, want %v", err, !tt.permanent, tt.permanent)
+			}
+		})
+	}
+}
+
+func TestNewWebhookNotifier_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		body string
+		opts []WebhookOption
+		want string
+	}{
+		{"url", "https://example.com/{{.Title", "{}", nil, "url:"},
+		{"body", "https://example.com", "{{json}", nil, "body:"},
+		{"unknown function", "https://example.com", "{{yaml .Title}}", nil, "body:"},
+		{"header", "https://example.com", "{}", []WebhookOption{WithWebhookHeader("X-Key", "{{end}}")}, "header X-Key:"},
+		{"status", "https://example.com", "{}", []WebhookOption{WithWebhookSuccess("2XX")}, "status pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewWebhookNotifier(tt.url, tt.body, tt.opts...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewWebhookNotifier() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifier_RenderError(t *testing.T) {
+	n, err := NewWebhookNotifier("https://example.com", "{{.Missing}}")
+	if err != nil {
+		t.Fatalf("NewWebhookNotifier() error = %v", err)
+	}
+	if err := n.Send("Test", "Message", ColorRed, nil); err == nil || !strings.Contains(err.Error(), "render body") || !IsPermanent(err) {
+		t.Errorf("Send() error = %v, want a permanent render error", err)
+	}
+}
+
+func TestValidStatusPattern(t *testing.T) {
+	for p, want := range map[string]bool{
+		"200": true, "2xx": true, "20x": true, "4x9": true,
+		"": false, "2XX": false, "xxx": false, "600": false, "2000": false, "20": false,
+	} {
+		if got := ValidStatusPattern(p); got != want {
+			t.Errorf("ValidStatusPattern(%q) = %v, want %v", p, got, want)
+		}
+	}
+}
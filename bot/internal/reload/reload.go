@@ -0,0 +1,99 @@
+// Package reload announces configuration reloads of pervigil-monitor and
+// pervigil-bot (SIGHUP) on Discord.
+package reload
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/murata-lab/pervigil/bot/internal/config"
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+)
+
+// maxListLen keeps a change list well inside the Discord embed description
+const maxListLen = 1500
+
+// Reporter posts the outcome of every reload
+type Reporter struct {
+	notifier notifier.Notifier
+	process  string
+	hostname string
+}
+
+// ReporterOption configures Reporter
+type ReporterOption func(*Reporter)
+
+// WithHostname sets the hostname shown in titles (for testing)
+func WithHostname(h string) ReporterOption {
+	return func(r *Reporter) {
+		r.hostname = h
+	}
+}
+
+// NewReporter creates a reporter for the named process
+func NewReporter(n notifier.Notifier, process string, opts ...ReporterOption) *Reporter {
+	hostname, _ := os.Hostname()
+	r := &Reporter{notifier: n, process: process, hostname: hostname}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Applied announces the settings a reload applied and those that only take
+// effect after a restart
+func (r *Reporter) Applied(applied, pending []config.Change) error {
+	var b strings.Builder
+	switch {
+	case len(applied) == 0 && len(pending) == 0:
+		b.WriteString("設定に変更はありません。")
+	case len(applied) == 0:
+		b.WriteString("反映できる設定の変更はありません。")
+	default:
+		fmt.Fprintf(&b, "%d件の設定を反映しました。\n%s", len(applied), changeList(applied))
+	}
+	if len(pending) > 0 {
+		fmt.Fprintf(&b, "\n次の%d件は再起動後に反映されます。\n%s", len(pending), changeList(pending))
+	}
+
+	color := notifier.ColorGreen
+	if len(pending) > 0 {
+		color = notifier.ColorYellow
+	}
+	return r.notifier.Send(
+		fmt.Sprintf("🔄 設定を再読み込み (%s) - %s", r.process, r.hostname),
+		b.String(),
+		color,
+		[]notifier.Field{
+			{Name: "Applied", Value: fmt.Sprintf("%d", len(applied)), Inline: true},
+			{Name: "Restart Required", Value: fmt.Sprintf("%d", len(pending)), Inline: true},
+		},
+	)
+}
+
+// Rejected announces a configuration that was not applied
+func (r *Reporter) Rejected(err error) error {
+	return r.notifier.Send(
+		fmt.Sprintf("⚠️ 設定の再読み込みに失敗 (%s) - %s", r.process, r.hostname),
+		fmt.Sprintf("新しい設定に問題があるため、現在の設定のまま動作を続けます。\n```\n%s\n```", truncate(err.Error())),
+		notifier.ColorRed,
+		nil,
+	)
+}
+
+// changeList formats changes as a code block
+func changeList(changes []config.Change) string {
+	lines := make([]string, len(changes))
+	for i, c := range changes {
+		lines[i] = c.String()
+	}
+	return "```\n" + truncate(strings.Join(lines, "\n")) + "\n```"
+}
+
+func truncate(s string) string {
+	if len(s) <= maxListLen {
+		return s
+	}
+	return strings.ToValidUTF8(s[:maxListLen], "") + "..."
+}
@@ -0,0 +1,84 @@
+package reload
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/murata-lab/pervigil/bot/internal/config"
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+)
+
+type mockNotifier struct {
+	title, message string
+	color          notifier.Color
+	calls          int
+}
+
+func (m *mockNotifier) Send(title, message string, color notifier.Color, _ []notifier.Field) error {
+	m.title, m.message, m.color = title, message, color
+	m.calls++
+	return nil
+}
+
+func TestReporter_Applied(t *testing.T) {
+	n := &mockNotifier{}
+	r := NewReporter(n, "pervigil-monitor", WithHostname("vyos"))
+
+	err := r.Applied(
+		[]config.Change{{Path: "monitor.check_interval", Old: "1m0s", New: "30s"}},
+		[]config.Change{{Path: "monitor.metrics_listen", Old: `""`, New: ":9101"}},
+	)
+	if err != nil {
+		t.Fatalf("Applied() error = %v", err)
+	}
+	if n.title != "🔄 設定を再読み込み (pervigil-monitor) - vyos" {
+		t.Errorf("title = %q", n.title)
+	}
+	for _, want := range []string{
+		"1件の設定を反映しました",
+		"monitor.check_interval: 1m0s → 30s",
+		"1件は再起動後に反映されます",
+		`monitor.metrics_listen: "" → :9101`,
+	} {
+		if !strings.Contains(n.message, want) {
+			t.Errorf("message missing %q:\n%s", want, n.message)
+		}
+	}
+	if n.color != notifier.ColorYellow {
+		t.Errorf("color = %v, want yellow while a restart is pending", n.color)
+	}
+
+	if err := r.Applied(nil, nil); err != nil {
+		t.Fatalf("Applied() error = %v", err)
+	}
+	if !strings.Contains(n.message, "変更はありません") || n.color != notifier.ColorGreen {
+		t.Errorf("no-change reload: message = %q, color = %v", n.message, n.color)
+	}
+}
+
+func TestReporter_Rejected(t *testing.T) {
+	n := &mockNotifier{}
+	r := NewReporter(n, "pervigil-bot", WithHostname("vyos"))
+
+	if err := r.Rejected(errors.New("pervigil.yaml:3: unknown setting \"color\"")); err != nil {
+		t.Fatalf("Rejected() error = %v", err)
+	}
+	if !strings.Contains(n.title, "失敗 (pervigil-bot)") || !strings.Contains(n.message, `unknown setting "color"`) {
+		t.Errorf("title = %q, message = %q", n.title, n.message)
+	}
+	if n.color != notifier.ColorRed {
+		t.Errorf("color = %v, want red", n.color)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	long := strings.Repeat("設定", maxListLen)
+	got := truncate(long)
+	if !strings.HasSuffix(got, "...") || len(got) > maxListLen+3 {
+		t.Errorf("truncate() length = %d", len(got))
+	}
+	if !strings.HasPrefix(long, strings.TrimSuffix(got, "...")) {
+		t.Error("truncate() changed the kept prefix")
+	}
+}
@@ -0,0 +1,376 @@
+// Package sfp reads SFP/SFP+/QSFP digital optical monitoring (DOM)
+// diagnostics as reported by `ethtool -m`.
+package sfp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/murata-lab/pervigil/bot/internal/sysroot"
+)
+
+// ErrNoDiagnostics indicates the port has no module or the module lacks DOM.
+var ErrNoDiagnostics = errors.New("module diagnostics unavailable")
+
+// Metric identifies a DOM measurement.
+type Metric string
+
+const (
+	MetricTemperature Metric = "temperature"
+	MetricVoltage     Metric = "voltage"
+	MetricBias        Metric = "bias"
+	MetricTxPower     Metric = "tx_power"
+	MetricRxPower     Metric = "rx_power"
+)
+
+// metricOrder is the display order of metrics
+var metricOrder = []Metric{MetricTemperature, MetricVoltage, MetricBias, MetricTxPower, MetricRxPower}
+
+var metricNames = map[Metric]string{
+	MetricTemperature: "Temperature",
+	MetricVoltage:     "Voltage",
+	MetricBias:        "Bias",
+	MetricTxPower:     "Tx power",
+	MetricRxPower:     "Rx power",
+}
+
+var metricUnits = map[Metric]string{
+	MetricTemperature: "°C",
+	MetricVoltage:     "V",
+	MetricBias:        "mA",
+	MetricTxPower:     "dBm",
+	MetricRxPower:     "dBm",
+}
+
+// Level is the severity of a reading.
+type Level int
+
+const (
+	LevelOK Level = iota
+	LevelWarning
+	LevelAlarm
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelWarning:
+		return "warning"
+	case LevelAlarm:
+		return "alarm"
+	default:
+		return "ok"
+	}
+}
+
+// noLightDBm replaces -inf dBm (0 mW) so readings stay finite
+const noLightDBm = -40.0
+
+// Thresholds are the module-reported alarm and warning limits.
+type Thresholds struct {
+	HighAlarm   float64
+	HighWarning float64
+	LowWarning  float64
+	LowAlarm    float64
+	Known       bool // all four limits were reported
+}
+
+// Reading is a single DOM measurement.
+type Reading struct {
+	Metric     Metric
+	Lane       int // 0 for single-lane modules, 1.. for QSFP channels
+	Value      float64
+	Thresholds Thresholds
+	Flag       Level // alarm/warning flag asserted by the module itself
+}
+
+// Key uniquely identifies the reading within a module.
+func (r Reading) Key() string {
+	if r.Lane > 0 {
+		return fmt.Sprintf("%s/%d", r.Metric, r.Lane)
+	}
+	return string(r.Metric)
+}
+
+// Label is a human readable name such as "Rx power ch2".
+func (r Reading) Label() string {
+	if r.Lane > 0 {
+		return fmt.Sprintf("%s ch%d", metricNames[r.Metric], r.Lane)
+	}
+	return metricNames[r.Metric]
+}
+
+// Unit returns the unit of Value.
+func (r Reading) Unit() string {
+	return metricUnits[r.Metric]
+}
+
+// Level combines the module flag with the module-reported thresholds.
+func (r Reading) Level() Level {
+	level := r.Flag
+	if !r.Thresholds.Known {
+		return level
+	}
+	t := r.Thresholds
+	switch {
+	case r.Value > t.HighAlarm || r.Value < t.LowAlarm:
+		return LevelAlarm
+	case r.Value > t.HighWarning || r.Value < t.LowWarning:
+		return max(level, LevelWarning)
+	}
+	return level
+}
+
+// Diagnostics holds the DOM state of one plugged module.
+type Diagnostics struct {
+	Interface  string
+	Identifier string
+	Vendor     string
+	PartNumber string
+	Serial     string
+	Readings   []Reading
+}
+
+// Level returns the worst level among all readings.
+func (d *Diagnostics) Level() Level {
+	worst := LevelOK
+	for _, r := range d.Readings {
+		worst = max(worst, r.Level())
+	}
+	return worst
+}
+
+// Module describes the module vendor and part number.
+func (d *Diagnostics) Module() string {
+	return strings.TrimSpace(d.Vendor + " " + d.PartNumber)
+}
+
+// commandRunnable abstracts command execution
+type commandRunnable interface {
+	RunCommandContext(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// Get reads DOM diagnostics of the module plugged into iface.
+// ethtool is killed when ctx is done.
+func Get(ctx context.Context, iface string) (*Diagnostics, error) {
+	return GetWith(ctx, iface, sysroot.Default())
+}
+
+// GetWith reads DOM diagnostics using provided deps (for testing).
+func GetWith(ctx context.Context, iface string, d commandRunnable) (*Diagnostics, error) {
+	out, err := d.RunCommandContext(ctx, "ethtool", "-m", iface)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		// ethtool fails with EOPNOTSUPP for copper ports and empty cages
+		return nil, fmt.Errorf("ethtool -m %s: %w", iface, ErrNoDiagnostics)
+	}
+	return Parse(iface, string(out))
+}
+
+// metricPrefixes maps lower-cased `ethtool -m` field prefixes to metrics.
+// SFF-8472 (SFP) and SFF-8636 (QSFP) spell the same measurement differently.
+var metricPrefixes = map[string]Metric{
+	"module temperature":                    MetricTemperature,
+	"module voltage":                        MetricVoltage,
+	"laser bias current":                    MetricBias,
+	"laser tx bias current":                 MetricBias,
+	"laser tx bias":                         MetricBias,
+	"laser output power":                    MetricTxPower,
+	"laser tx power":                        MetricTxPower,
+	"transmit avg optical power":            MetricTxPower,
+	"receiver signal average optical power": MetricRxPower,
+	"rcvr signal avg optical power":         MetricRxPower,
+	"laser rx power":                        MetricRxPower,
+}
+
+var (
+	laneRe  = regexp.MustCompile(`\s*\((?:chan|channel)\s*(\d+)\)`)
+	limitRe = regexp.MustCompile(`^(.+) (high|low) (alarm|warning)$`)
+	dbmRe   = regexp.MustCompile(`(-?inf|-?[\d.]+)\s*dBm`)
+	numRe   = regexp.MustCompile(`-?[\d.]+`)
+)
+
+// Parse extracts module identity and DOM readings from `ethtool -m` output.
+func Parse(iface, out string) (*Diagnostics, error) {
+	diag := &Diagnostics{Interface: iface}
+	values := make(map[string]*Reading)
+	thresholds := make(map[Metric]*thresholdSet)
+	flags := make(map[string]Level)
+
+	for _, line := range strings.Split(out, "\n") {
+		rawKey, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		key := strings.ToLower(strings.TrimSpace(rawKey))
+
+		switch key {
+		case "identifier":
+			diag.Identifier = value
+			continue
+		case "vendor name":
+			diag.Vendor = value
+			continue
+		case "vendor pn":
+			diag.PartNumber = value
+			continue
+		case "vendor sn":
+			diag.Serial = value
+			continue
+		}
+
+		lane := 0
+		if m := laneRe.FindStringSubmatch(key); m != nil {
+			lane, _ = strconv.Atoi(m[1])
+			key = strings.TrimSpace(laneRe.ReplaceAllString(key, ""))
+		}
+
+		if base, ok := strings.CutSuffix(key, " threshold"); ok {
+			m := limitRe.FindStringSubmatch(base)
+			if m == nil {
+				continue
+			}
+			metric, ok := metricPrefixes[m[1]]
+			if !ok {
+				continue
+			}
+			v, ok := parseValue(metric, value)
+			if !ok {
+				continue
+			}
+			if thresholds[metric] == nil {
+				thresholds[metric] = &thresholdSet{}
+			}
+			thresholds[metric].set(m[2], m[3], v)
+			continue
+		}
+
+		if m := limitRe.FindStringSubmatch(key); m != nil {
+			metric, ok := metricPrefixes[m[1]]
+			if !ok || !strings.EqualFold(value, "on") {
+				continue
+			}
+			level := LevelWarning
+			if m[3] == "alarm" {
+				level = LevelAlarm
+			}
+			k := Reading{Metric: metric, Lane: lane}.Key()
+			flags[k] = max(flags[k], level)
+			continue
+		}
+
+		metric, ok := metricPrefixes[key]
+		if !ok {
+			continue
+		}
+		v, ok := parseValue(metric, value)
+		if !ok {
+			continue
+		}
+		r := Reading{Metric: metric, Lane: lane, Value: v}
+		values[r.Key()] = &r
+	}
+
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%s: %w", iface, ErrNoDiagnostics)
+	}
+
+	for _, metric := range metricOrder {
+		for lane := 0; lane <= maxLanes; lane++ {
+			key := Reading{Metric: metric, Lane: lane}.Key()
+			r, ok := values[key]
+			if !ok {
+				continue
+			}
+			if t := thresholds[metric]; t != nil {
+				r.Thresholds = t.thresholds()
+			}
+			// QSFP flags may be reported per module rather than per lane
+			r.Flag = max(flags[key], flags[string(metric)])
+			diag.Readings = append(diag.Readings, *r)
+		}
+	}
+	return diag, nil
+}
+
+// maxLanes bounds the QSFP channel numbers considered (QSFP-DD has 8)
+const maxLanes = 8
+
+// parseValue extracts the numeric part of an `ethtool -m` value.
+// Optical power is returned in dBm, converting from mW when needed.
+func parseValue(metric Metric, value string) (float64, bool) {
+	if metric == MetricTxPower || metric == MetricRxPower {
+		if m := dbmRe.FindStringSubmatch(value); m != nil {
+			if strings.HasSuffix(m[1], "inf") {
+				return noLightDBm, true
+			}
+			v, err := strconv.ParseFloat(m[1], 64)
+			return v, err == nil
+		}
+		mw, ok := firstNumber(value)
+		if !ok {
+			return 0, false
+		}
+		if mw <= 0 {
+			return noLightDBm, true
+		}
+		return max(10*math.Log10(mw), noLightDBm), true
+	}
+	return firstNumber(value)
+}
+
+func firstNumber(s string) (float64, bool) {
+	m := numRe.FindString(s)
+	if m == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(m, 64)
+	return v, err == nil
+}
+
+// thresholdSet collects the four limits of a metric as they are parsed
+type thresholdSet struct {
+	t    Thresholds
+	seen int
+}
+
+func (s *thresholdSet) set(side, kind string, v float64) {
+	switch side + " " + kind {
+	case "high alarm":
+		s.t.HighAlarm = v
+	case "high warning":
+		s.t.HighWarning = v
+	case "low warning":
+		s.t.LowWarning = v
+	case "low alarm":
+		s.t.LowAlarm = v
+	default:
+		return
+	}
+	s.seen++
+}
+
+func (s *thresholdSet) thresholds() Thresholds {
+	t := s.t
+	t.Known = s.seen >= 4
+	return t
+}
+
+// FormatValue formats a reading value with its unit.
+func FormatValue(r Reading) string {
+	switch r.Metric {
+	case MetricVoltage:
+		return fmt.Sprintf("%.3f %s", r.Value, r.Unit())
+	case MetricTemperature:
+		return fmt.Sprintf("%.1f%s", r.Value, r.Unit())
+	default:
+		return fmt.Sprintf("%.2f %s", r.Value, r.Unit())
+	}
+}
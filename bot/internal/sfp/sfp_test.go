@@ -0,0 +1,208 @@
+package sfp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+type mapRunner struct {
+	outputs map[string]string
+}
+
+func (r *mapRunner) RunCommandContext(_ context.Context, name string, args ...string) ([]byte, error) {
+	key := name
+	for _, a := range args {
+		key += " " + a
+	}
+	if out, ok := r.outputs[key]; ok {
+		return []byte(out), nil
+	}
+	return nil, fmt.Errorf("command failed: %s", key)
+}
+
+const sfpPlusOutput = `	Identifier                                : 0x03 (SFP)
+	Vendor name                               : FINISAR CORP.
+	Vendor PN                                 : FTLX8571D3BCL
+	Vendor SN                                 : ABC1234
+	Optical diagnostics support               : Yes
+	Laser bias current                        : 6.758 mA
+	Laser output power                        : 0.5882 mW / -2.30 dBm
+	Receiver signal average optical power     : 0.0234 mW / -16.31 dBm
+	Module temperature                        : 33.95 degrees C / 93.11 degrees F
+	Module voltage                            : 3.3266 V
+	Alarm/warning flags implemented           : Yes
+	Laser bias current high alarm             : Off
+	Laser bias current low alarm              : Off
+	Laser rx power high alarm                 : Off
+	Laser rx power low alarm                  : Off
+	Laser rx power low warning                : On
+	Laser bias current high alarm threshold   : 11.800 mA
+	Laser bias current low alarm threshold    : 4.000 mA
+	Laser bias current high warning threshold : 10.800 mA
+	Laser bias current low warning threshold  : 5.000 mA
+	Laser output power high alarm threshold   : 1.4962 mW / 1.75 dBm
+	Laser output power low alarm threshold    : 0.1122 mW / -9.50 dBm
+	Laser output power high warning threshold : 0.7943 mW / -1.00 dBm
+	Laser output power low warning threshold  : 0.1862 mW / -7.30 dBm
+	Module temperature high alarm threshold   : 78.00 degrees C / 172.40 degrees F
+	Module temperature low alarm threshold    : -13.00 degrees C / 8.60 degrees F
+	Module temperature high warning threshold : 73.00 degrees C / 163.40 degrees F
+	Module temperature low warning threshold  : -8.00 degrees C / 17.60 degrees F
+	Module voltage high alarm threshold       : 3.7000 V
+	Module voltage low alarm threshold        : 2.9000 V
+	Module voltage high warning threshold     : 3.6000 V
+	Module voltage low warning threshold      : 3.0000 V
+	Laser rx power high alarm threshold       : 1.0000 mW / 0.00 dBm
+	Laser rx power low alarm threshold        : 0.0100 mW / -20.00 dBm
+	Laser rx power high warning threshold     : 0.7943 mW / -1.00 dBm
+	Laser rx power low warning threshold      : 0.0158 mW / -18.01 dBm
+`
+
+const qsfpOutput = `	Identifier                                : 0x11 (QSFP28)
+	Vendor name                               : Mellanox
+	Vendor PN                                 : MMA1B00-C100D
+	Module temperature                        : 41.20 degrees C / 106.16 degrees F
+	Module voltage                            : 3.2900 V
+	Laser tx bias current (Channel 1)         : 7.000 mA
+	Laser tx bias current (Channel 2)         : 7.100 mA
+	Transmit avg optical power (Channel 1)    : 0.9000 mW / -0.46 dBm
+	Rcvr signal avg optical power(Channel 1)  : 0.8000 mW / -0.97 dBm
+	Rcvr signal avg optical power(Channel 2)  : 0.0000 mW / -inf dBm
+	Laser rx power low alarm   (Chan 2)       : On
+`
+
+func find(t *testing.T, d *Diagnostics, key string) Reading {
+	t.Helper()
+	for _, r := range d.Readings {
+		if r.Key() == key {
+			return r
+		}
+	}
+	t.Fatalf("reading %s not found in %+v", key, d.Readings)
+	return Reading{}
+}
+
+func TestParse_SFPPlus(t *testing.T) {
+	d, err := Parse("eth1", sfpPlusOutput)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if d.Module() != "FINISAR CORP. FTLX8571D3BCL" || d.Serial != "ABC1234" {
+		t.Errorf("module = %q, serial = %q", d.Module(), d.Serial)
+	}
+	if len(d.Readings) != 5 {
+		t.Fatalf("expected 5 readings, got %d", len(d.Readings))
+	}
+
+	bias := find(t, d, "bias")
+	if bias.Value != 6.758 || !bias.Thresholds.Known || bias.Thresholds.HighAlarm != 11.8 {
+		t.Errorf("bias = %+v", bias)
+	}
+	if bias.Level() != LevelOK {
+		t.Errorf("bias level = %v, want ok", bias.Level())
+	}
+
+	tx := find(t, d, "tx_power")
+	if tx.Value != -2.30 || tx.Thresholds.LowAlarm != -9.5 {
+		t.Errorf("tx power = %+v", tx)
+	}
+
+	rx := find(t, d, "rx_power")
+	if rx.Value != -16.31 {
+		t.Errorf("rx power = %v, want -16.31", rx.Value)
+	}
+	if rx.Flag != LevelWarning || rx.Level() != LevelWarning {
+		t.Errorf("rx level = %v (flag %v), want warning", rx.Level(), rx.Flag)
+	}
+
+	temp := find(t, d, "temperature")
+	if temp.Value != 33.95 || temp.Thresholds.LowAlarm != -13 {
+		t.Errorf("temperature = %+v", temp)
+	}
+	if d.Level() != LevelWarning {
+		t.Errorf("module level = %v, want warning", d.Level())
+	}
+}
+
+func TestParse_QSFPLanes(t *testing.T) {
+	d, err := Parse("eth2", qsfpOutput)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got := find(t, d, "bias/2"); got.Value != 7.1 || got.Label() != "Bias ch2" {
+		t.Errorf("bias ch2 = %+v", got)
+	}
+	if got := find(t, d, "tx_power/1"); got.Value != -0.46 {
+		t.Errorf("tx ch1 = %v", got.Value)
+	}
+	rx2 := find(t, d, "rx_power/2")
+	if rx2.Value != noLightDBm || math.IsInf(rx2.Value, 0) {
+		t.Errorf("rx ch2 = %v, want %v", rx2.Value, noLightDBm)
+	}
+	if rx2.Level() != LevelAlarm {
+		t.Errorf("rx ch2 level = %v, want alarm from module flag", rx2.Level())
+	}
+	if find(t, d, "rx_power/1").Level() != LevelOK {
+		t.Error("rx ch1 should be ok")
+	}
+}
+
+func TestReading_LevelFromThresholds(t *testing.T) {
+	th := Thresholds{HighAlarm: 1.75, HighWarning: -1, LowWarning: -7.3, LowAlarm: -9.5, Known: true}
+	tests := []struct {
+		value float64
+		want  Level
+	}{
+		{-2.3, LevelOK},
+		{-0.5, LevelWarning},
+		{-8.0, LevelWarning},
+		{-12.0, LevelAlarm},
+		{2.0, LevelAlarm},
+	}
+	for _, tt := range tests {
+		r := Reading{Metric: MetricTxPower, Value: tt.value, Thresholds: th}
+		if got := r.Level(); got != tt.want {
+			t.Errorf("Level(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	// Partial thresholds are ignored
+	r := Reading{Metric: MetricTxPower, Value: -30, Thresholds: Thresholds{LowAlarm: -9.5}}
+	if r.Level() != LevelOK {
+		t.Errorf("unknown thresholds should not alarm, got %v", r.Level())
+	}
+}
+
+func TestParseValue_MilliwattOnly(t *testing.T) {
+	v, ok := parseValue(MetricRxPower, "0.5000 mW")
+	if !ok || math.Abs(v-(-3.0103)) > 0.001 {
+		t.Errorf("parseValue() = %v, %v", v, ok)
+	}
+}
+
+func TestGetWith(t *testing.T) {
+	d, err := GetWith(t.Context(), "eth1", &mapRunner{outputs: map[string]string{"ethtool -m eth1": sfpPlusOutput}})
+	if err != nil {
+		t.Fatalf("GetWith() error = %v", err)
+	}
+	if d.Interface != "eth1" {
+		t.Errorf("Interface = %q", d.Interface)
+	}
+}
+
+func TestGetWith_NoModule(t *testing.T) {
+	_, err := GetWith(t.Context(), "eth0", &mapRunner{})
+	if !errors.Is(err, ErrNoDiagnostics) {
+		t.Errorf("expected ErrNoDiagnostics, got %v", err)
+	}
+
+	_, err = GetWith(t.Context(), "eth0", &mapRunner{outputs: map[string]string{"ethtool -m eth0": "\tIdentifier : 0x03 (SFP)\n"}})
+	if !errors.Is(err, ErrNoDiagnostics) {
+		t.Errorf("expected ErrNoDiagnostics without DOM, got %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+// Package statusapi is the local JSON API between pervigil-monitor and
+// pervigil-bot. The monitor serves its current state on a Unix socket and
+// the bot reads it instead of guessing what the monitor has done.
+package statusapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+)
+
+// StatusPath is the endpoint returning Status.
+const StatusPath = "/v1/status"
+
+// Status is the monitor state returned by StatusPath.
+type Status struct {
+	Hostname   string            `json:"hostname"`
+	StartedAt  time.Time         `json:"started_at"`
+	DryRun     bool              `json:"dry_run"`
+	Interfaces []InterfaceStatus `json:"interfaces"`
+	Errors     []CheckError      `json:"errors,omitempty"`
+	Alerts     []Alert           `json:"alerts,omitempty"` // newest first
+}
+
+// InterfaceStatus is the NIC state machine and last reading of one interface.
+type InterfaceStatus struct {
+	Name          string    `json:"name"`
+	State         string    `json:"state"` // normal, warning or critical
+	SpeedLimited  bool      `json:"speed_limited"`
+	ThrottleLevel int       `json:"throttle_level"`
+	Temperature   float64   `json:"temperature"`
+	Warning       float64   `json:"warning"`
+	Critical      float64   `json:"critical"`
+	ReadAt        time.Time `json:"read_at,omitzero"` // zero when never read
+}
+
+// CheckError is the last error of a monitor check.
+type CheckError struct {
+	Check      string    `json:"check"`
+	Message    string    `json:"message"`
+	Time       time.Time `json:"time"`
+	Failing    bool      `json:"failing"` // false once the check succeeded again
+	Errors     int       `json:"errors"`
+	Suppressed int       `json:"suppressed"`
+}
+
+// Alert is a notification sent by the monitor.
+type Alert struct {
+	Time    time.Time      `json:"time"`
+	Title   string         `json:"title"`
+	Message string         `json:"message"`
+	Color   notifier.Color `json:"color"`
+	Source  string         `json:"source,omitempty"` // component that sent it, e.g. "nic"
+}
+
+// isUnix reports whether addr is a socket path rather than host:port
+func isUnix(addr string) bool {
+	return strings.Contains(addr, "/")
+}
+
+// Listen listens on addr: a Unix socket path (created with mode 0660,
+// replacing a stale socket) or a TCP host:port.
+func Listen(addr string) (net.Listener, error) {
+	if !isUnix(addr) {
+		return net.Listen("tcp", addr)
+	}
+	if err := os.MkdirAll(filepath.Dir(addr), 0o755); err != nil {
+		return nil, err
+	}
+	if fi, err := os.Lstat(addr); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", addr)
+		}
+		os.Remove(addr)
+	}
+	l, err := net.Listen("unix", addr)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(addr, 0o660); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Client reads the monitor status.
+type Client struct {
+	base string
+	http *http.Client
+}
+
+// ClientOption configures Client
+type ClientOption func(*Client)
+
+// WithTimeout sets the request timeout
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.http.Timeout = d
+	}
+}
+
+// NewClient creates a client for the API at addr (socket path or host:port).
+func NewClient(addr string, opts ...ClientOption) *Client {
+	c := &Client{
+		base: "http://" + addr,
+		http: &http.Client{Timeout: 2 * time.Second},
+	}
+	if isUnix(addr) {
+		c.base = "http://monitor"
+		c.http.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", addr)
+			},
+		}
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Status returns the current monitor status.
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base+StatusPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status API error: status %d", resp.StatusCode)
+	}
+	var st Status
+	if err := json.NewDecoder(resp.Body).Decode(&st); err != nil {
+		return nil, fmt.Errorf("decode status: %w", err)
+	}
+	return &st, nil
+}
@@ -0,0 +1,109 @@
+package statusapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/murata-lab/pervigil/bot/internal/notifier"
+)
+
+// socketPath returns a short socket path; t.TempDir can exceed the
+// 108-byte sun_path limit.
+func socketPath(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "pv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "run", "monitor.sock")
+}
+
+func serve(t *testing.T, addr string, h http.Handler) {
+	t.Helper()
+	l, err := Listen(addr)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	srv := &http.Server{Handler: h}
+	go srv.Serve(l)
+	t.Cleanup(func() { srv.Close() })
+}
+
+func TestClient_StatusOverUnixSocket(t *testing.T) {
+	addr := socketPath(t)
+	want := Status{
+		Hostname:   "router",
+		Interfaces: []InterfaceStatus{{Name: "eth1", State: "warning", Temperature: 72.5, Warning: 70, Critical: 85}},
+		Alerts:     []Alert{{Title: "NIC温度警告", Color: notifier.ColorYellow}},
+	}
+	serve(t, addr, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != StatusPath {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(want)
+	}))
+
+	fi, err := os.Stat(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0o660 {
+		t.Errorf("socket mode = %o, want 660", perm)
+	}
+
+	st, err := NewClient(addr).Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if st.Hostname != "router" || len(st.Interfaces) != 1 || st.Interfaces[0].State != "warning" {
+		t.Errorf("Status() = %+v", st)
+	}
+	if len(st.Alerts) != 1 || st.Alerts[0].Color != notifier.ColorYellow {
+		t.Errorf("alerts = %+v", st.Alerts)
+	}
+}
+
+func TestClient_Unavailable(t *testing.T) {
+	c := NewClient(socketPath(t), WithTimeout(time.Second))
+	if _, err := c.Status(context.Background()); err == nil {
+		t.Error("Status() should fail without a server")
+	}
+
+	addr := socketPath(t)
+	serve(t, addr, http.NotFoundHandler())
+	if _, err := NewClient(addr).Status(context.Background()); err == nil {
+		t.Error("Status() should fail on 404")
+	}
+}
+
+func TestListen_StaleSocket(t *testing.T) {
+	addr := socketPath(t)
+	l, err := Listen(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A crashed monitor leaves its socket behind
+	l.(interface{ SetUnlinkOnClose(bool) }).SetUnlinkOnClose(false)
+	l.Close()
+
+	l, err = Listen(addr)
+	if err != nil {
+		t.Fatalf("Listen() over stale socket error = %v", err)
+	}
+	l.Close()
+
+	file := filepath.Join(filepath.Dir(addr), "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Listen(file); err == nil {
+		t.Error("Listen() should refuse to replace a regular file")
+	}
+}
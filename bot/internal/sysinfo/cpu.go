@@ -1,10 +1,11 @@
 package sysinfo
 
 import (
-	"os"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/murata-lab/pervigil/bot/internal/sysroot"
 )
 
 // fileReadable abstracts file reading (defined at usage site per Go idiom)
@@ -23,11 +24,11 @@ type cpuDeps interface {
 	sleeper
 }
 
-// osCpuDeps is the production implementation
+// osCpuDeps is the production implementation, reading below the default sysroot
 type osCpuDeps struct{}
 
 func (o *osCpuDeps) ReadFile(path string) ([]byte, error) {
-	return os.ReadFile(path)
+	return sysroot.Default().ReadFile(path)
 }
 
 func (o *osCpuDeps) Sleep(d time.Duration) {
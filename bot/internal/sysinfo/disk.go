@@ -2,6 +2,8 @@ package sysinfo
 
 import (
 	"syscall"
+
+	"github.com/murata-lab/pervigil/bot/internal/sysroot"
 )
 
 // DiskInfo contains disk usage information.
@@ -14,9 +16,10 @@ type DiskInfo struct {
 }
 
 // GetDiskInfo returns disk usage for the specified path.
+// Under a fake sysroot this is the filesystem holding the sysroot.
 func GetDiskInfo(path string) (*DiskInfo, error) {
 	var stat syscall.Statfs_t
-	if err := syscall.Statfs(path, &stat); err != nil {
+	if err := syscall.Statfs(sysroot.Default().Path(path), &stat); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,102 @@
+package sysinfo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/murata-lab/pervigil/bot/internal/sysroot"
+	"github.com/murata-lab/pervigil/bot/internal/temperature"
+)
+
+// useFixture replays the captured X540 router for the package-level readers.
+func useFixture(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := sysroot.Extract("../sysroot/testdata/router-x540.tar.gz", dir); err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	prev := sysroot.Default()
+	sysroot.SetDefault(sysroot.New(dir))
+	t.Cleanup(func() { sysroot.SetDefault(prev) })
+}
+
+func TestFixture_MemoryInfo(t *testing.T) {
+	useFixture(t)
+
+	info, err := GetMemoryInfo()
+	if err != nil {
+		t.Fatalf("GetMemoryInfo() error = %v", err)
+	}
+	if info.Total != 8038232*1024 || info.Available != 6430584*1024 {
+		t.Errorf("info = %+v", info)
+	}
+	if info.UsagePercent < 19.9 || info.UsagePercent > 20.1 {
+		t.Errorf("UsagePercent = %.2f, want ~20", info.UsagePercent)
+	}
+}
+
+func TestFixture_NICInfo(t *testing.T) {
+	useFixture(t)
+
+	eth1, err := GetNICInfo(t.Context(), "eth1")
+	if err != nil {
+		t.Fatalf("GetNICInfo(eth1) error = %v", err)
+	}
+	if eth1.State != "up" || eth1.Speed != "10000Mb/s" || eth1.RxBytes != 987654321000 {
+		t.Errorf("eth1 = %+v", eth1)
+	}
+	// Kernel queries are unavailable, so the NIC chain falls back to hwmon
+	if eth1.Temp != 62 || eth1.TempThresholds != temperature.DefaultThresholds(temperature.CategoryNIC) {
+		t.Errorf("eth1 temp = %v %+v", eth1.Temp, eth1.TempThresholds)
+	}
+
+	eth2, err := GetNICInfo(t.Context(), "eth2")
+	if err != nil {
+		t.Fatalf("GetNICInfo(eth2) error = %v", err)
+	}
+	if eth2.State != "down" || eth2.Speed != "" || eth2.Temp != 0 {
+		t.Errorf("eth2 = %+v", eth2)
+	}
+
+	if _, err := GetNICInfo(t.Context(), "eth9"); err == nil {
+		t.Error("GetNICInfo(eth9) expected error")
+	}
+}
+
+func TestFixture_UptimeAndLoad(t *testing.T) {
+	useFixture(t)
+
+	if got := GetUptime(); got != "4 days, 3 hours, 0 minutes" {
+		t.Errorf("GetUptime() = %q", got)
+	}
+	info, err := GetCPUInfoWith(&fixtureCpuDeps{})
+	if err != nil {
+		t.Fatalf("GetCPUInfoWith() error = %v", err)
+	}
+	if info.LoadAvg != [3]float64{0.42, 0.35, 0.30} {
+		t.Errorf("LoadAvg = %v", info.LoadAvg)
+	}
+}
+
+func TestFixture_Temperatures(t *testing.T) {
+	useFixture(t)
+
+	cpu, nics, board, zones := temperature.GetAllTemps(t.Context(), "eth1")
+	if len(cpu) != 3 || cpu[1].Label != "Core 0" || cpu[1].Value != 45 {
+		t.Errorf("cpu = %+v", cpu)
+	}
+	if len(nics) != 1 || nics[0].Source != temperature.SourceHwmon {
+		t.Errorf("nics = %+v", nics)
+	}
+	if len(board) != 2 {
+		t.Errorf("board = %+v, want nct6775 and ixgbe", board)
+	}
+	if len(zones) != 1 || zones[0].Label != "acpitz" || zones[0].Thresholds.Critical != 105 {
+		t.Errorf("zones = %+v", zones)
+	}
+}
+
+// fixtureCpuDeps reads the default sysroot without sleeping
+type fixtureCpuDeps struct{ osCpuDeps }
+
+func (d *fixtureCpuDeps) Sleep(time.Duration) {}
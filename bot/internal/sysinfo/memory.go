@@ -1,9 +1,10 @@
 package sysinfo
 
 import (
-	"os"
 	"strconv"
 	"strings"
+
+	"github.com/murata-lab/pervigil/bot/internal/sysroot"
 )
 
 // MemInfo contains memory usage information.
@@ -16,7 +17,12 @@ type MemInfo struct {
 
 // GetMemoryInfo returns memory usage information.
 func GetMemoryInfo() (*MemInfo, error) {
-	data, err := os.ReadFile("/proc/meminfo")
+	return GetMemoryInfoWith(sysroot.Default())
+}
+
+// GetMemoryInfoWith returns memory usage using the provided reader (for testing).
+func GetMemoryInfoWith(r fileReadable) (*MemInfo, error) {
+	data, err := r.ReadFile("/proc/meminfo")
 	if err != nil {
 		return nil, err
 	}
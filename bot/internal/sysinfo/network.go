@@ -1,55 +1,82 @@
 package sysinfo
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
+	"github.com/murata-lab/pervigil/bot/internal/sysroot"
 	"github.com/murata-lab/pervigil/bot/internal/temperature"
 )
 
 // NICInfo contains network interface information.
 type NICInfo struct {
-	Name      string
-	State     string  // up/down
-	Speed     string  // 10000Mb/s etc
-	Temp      float64 // Temperature (if available)
-	RxBytes   uint64
-	TxBytes   uint64
-	RxPackets uint64
-	TxPackets uint64
-	RxErrors  uint64
-	TxErrors  uint64
+	Name           string
+	State          string                 // up/down
+	Speed          string                 // 10000Mb/s etc
+	Temp           float64                // Temperature (if available)
+	TempThresholds temperature.Thresholds // Calibrated temperature thresholds
+	RxBytes        uint64
+	TxBytes        uint64
+	RxPackets      uint64
+	TxPackets      uint64
+	RxErrors       uint64
+	TxErrors       uint64
 }
 
-// GetMonitoredNICs returns the list of NICs to monitor.
-// Uses MONITOR_NICS env var (comma-separated) or defaults to eth0,eth1,eth2.
+// monitoredNICs is set from the config file by SetMonitoredNICs
+var monitoredNICs atomic.Pointer[[]string]
+
+// SetMonitoredNICs sets the NICs returned by GetMonitoredNICs.
+func SetMonitoredNICs(nics []string) {
+	monitoredNICs.Store(&nics)
+}
+
+// GetMonitoredNICs returns the list of NICs to monitor: those set by
+// SetMonitoredNICs, the MONITOR_NICS env var (comma-separated) or eth0,eth1,eth2.
 func GetMonitoredNICs() []string {
+	if nics := monitoredNICs.Load(); nics != nil && len(*nics) > 0 {
+		return *nics
+	}
 	if env := os.Getenv("MONITOR_NICS"); env != "" {
 		return strings.Split(env, ",")
 	}
 	return []string{"eth0", "eth1", "eth2"}
 }
 
-// GetNICInfo returns information for a single NIC.
-func GetNICInfo(iface string) (*NICInfo, error) {
+// nicDeps reads sysfs network interface attributes
+type nicDeps interface {
+	fileReadable
+	Stat(path string) (os.FileInfo, error)
+}
+
+// GetNICInfo returns information for a single NIC. The temperature is
+// not read once ctx is done.
+func GetNICInfo(ctx context.Context, iface string) (*NICInfo, error) {
+	return GetNICInfoWith(ctx, iface, sysroot.Default())
+}
+
+// GetNICInfoWith returns NIC information using the provided deps (for testing).
+func GetNICInfoWith(ctx context.Context, iface string, d nicDeps) (*NICInfo, error) {
 	basePath := filepath.Join("/sys/class/net", iface)
 
 	// Check if interface exists
-	if _, err := os.Stat(basePath); os.IsNotExist(err) {
+	if _, err := d.Stat(basePath); os.IsNotExist(err) {
 		return nil, err
 	}
 
 	info := &NICInfo{Name: iface}
 
 	// State (operstate)
-	if data, err := os.ReadFile(filepath.Join(basePath, "operstate")); err == nil {
+	if data, err := d.ReadFile(filepath.Join(basePath, "operstate")); err == nil {
 		info.State = strings.TrimSpace(string(data))
 	}
 
 	// Speed
-	if data, err := os.ReadFile(filepath.Join(basePath, "speed")); err == nil {
+	if data, err := d.ReadFile(filepath.Join(basePath, "speed")); err == nil {
 		speed := strings.TrimSpace(string(data))
 		if speed != "" && speed != "-1" {
 			info.Speed = speed + "Mb/s"
@@ -58,27 +85,28 @@ func GetNICInfo(iface string) (*NICInfo, error) {
 
 	// Statistics
 	statsPath := filepath.Join(basePath, "statistics")
-	info.RxBytes = readStatFile(filepath.Join(statsPath, "rx_bytes"))
-	info.TxBytes = readStatFile(filepath.Join(statsPath, "tx_bytes"))
-	info.RxPackets = readStatFile(filepath.Join(statsPath, "rx_packets"))
-	info.TxPackets = readStatFile(filepath.Join(statsPath, "tx_packets"))
-	info.RxErrors = readStatFile(filepath.Join(statsPath, "rx_errors"))
-	info.TxErrors = readStatFile(filepath.Join(statsPath, "tx_errors"))
+	info.RxBytes = readStatFile(d, filepath.Join(statsPath, "rx_bytes"))
+	info.TxBytes = readStatFile(d, filepath.Join(statsPath, "tx_bytes"))
+	info.RxPackets = readStatFile(d, filepath.Join(statsPath, "rx_packets"))
+	info.TxPackets = readStatFile(d, filepath.Join(statsPath, "tx_packets"))
+	info.RxErrors = readStatFile(d, filepath.Join(statsPath, "rx_errors"))
+	info.TxErrors = readStatFile(d, filepath.Join(statsPath, "tx_errors"))
 
 	// Temperature
-	if t, err := temperature.GetNICTemp(iface); err == nil {
+	if t, err := temperature.GetNICTemp(ctx, iface); err == nil {
 		info.Temp = t.Value
+		info.TempThresholds = t.Thresholds
 	}
 
 	return info, nil
 }
 
 // GetAllNICs returns information for all monitored NICs.
-func GetAllNICs() []NICInfo {
+func GetAllNICs(ctx context.Context) []NICInfo {
 	var nics []NICInfo
 
 	for _, iface := range GetMonitoredNICs() {
-		if info, err := GetNICInfo(iface); err == nil {
+		if info, err := GetNICInfo(ctx, iface); err == nil {
 			nics = append(nics, *info)
 		}
 	}
@@ -86,8 +114,8 @@ func GetAllNICs() []NICInfo {
 	return nics
 }
 
-func readStatFile(path string) uint64 {
-	data, err := os.ReadFile(path)
+func readStatFile(r fileReadable, path string) uint64 {
+	data, err := r.ReadFile(path)
 	if err != nil {
 		return 0
 	}
@@ -1,13 +1,14 @@
 package sysinfo
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
 	"strings"
 	"time"
 
+	"github.com/murata-lab/pervigil/bot/internal/sysroot"
 	"github.com/murata-lab/pervigil/bot/internal/temperature"
 )
 
@@ -21,10 +22,13 @@ type RouterInfo struct {
 	Disk       *DiskInfo
 	CPUTemps   []temperature.TempReading
 	BoardTemps []temperature.TempReading
+
+	ThermalZones []temperature.ThermalZone
 }
 
-// GetAllRouterInfo returns all router system information.
-func GetAllRouterInfo() *RouterInfo {
+// GetAllRouterInfo returns all router system information. Commands are
+// killed when ctx is done.
+func GetAllRouterInfo(ctx context.Context) *RouterInfo {
 	info := &RouterInfo{}
 
 	// Hostname
@@ -50,7 +54,7 @@ func GetAllRouterInfo() *RouterInfo {
 	}
 
 	// NIC info
-	info.NICs = GetAllNICs()
+	info.NICs = GetAllNICs(ctx)
 
 	// Disk info (root partition)
 	info.Disk, err = GetDiskInfo("/")
@@ -58,18 +62,29 @@ func GetAllRouterInfo() *RouterInfo {
 		log.Printf("[sysinfo] disk: %v", err)
 	}
 
-	// Temperatures — single hwmon scan via GetAllTemps (NIC results unused here)
-	info.CPUTemps, _, info.BoardTemps = temperature.GetAllTemps("")
+	// Temperatures via GetAllTemps (NIC results unused here)
+	info.CPUTemps, _, info.BoardTemps, info.ThermalZones = temperature.GetAllTemps(ctx, "")
 
 	return info
 }
 
+// uptimeDeps runs uptime(1) and reads /proc/uptime
+type uptimeDeps interface {
+	fileReadable
+	RunCommand(name string, args ...string) ([]byte, error)
+}
+
 // GetUptime returns the system uptime as a string.
 func GetUptime() string {
-	out, err := exec.Command("uptime", "-p").Output()
+	return GetUptimeWith(sysroot.Default())
+}
+
+// GetUptimeWith returns the uptime using the provided deps (for testing).
+func GetUptimeWith(d uptimeDeps) string {
+	out, err := d.RunCommand("uptime", "-p")
 	if err != nil {
 		// Fallback for systems without -p flag
-		data, err := os.ReadFile("/proc/uptime")
+		data, err := d.ReadFile("/proc/uptime")
 		if err != nil {
 			return "unknown"
 		}
@@ -0,0 +1,230 @@
+// Package sysroot resolves the host paths read by the sensor and system
+// readers (/sys, /proc) against a configurable root directory, so that a
+// router's filesystem captured into a fixture can be replayed in tests and
+// in the --sysroot debug mode.
+package sysroot
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// CommandsDir holds recorded command output inside a fake root. The file
+// name is the command line with "/" replaced by "_" (e.g. "sensors -u",
+// "ethtool -m eth1").
+const CommandsDir = "commands"
+
+// ErrNotRecorded is returned when a fake root has no output for a command.
+var ErrNotRecorded = errors.New("command output not recorded")
+
+// Root reads host paths below a directory. The zero root ("" or "/") is the
+// real filesystem.
+type Root struct {
+	dir string
+}
+
+// New returns a root at dir.
+func New(dir string) *Root {
+	if dir == "/" {
+		dir = ""
+	}
+	return &Root{dir: dir}
+}
+
+// Open returns a root for a directory or a fixture tarball (.tar, .tar.gz,
+// .tgz). A tarball is extracted into a new temporary directory.
+func Open(path string) (*Root, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return New(path), nil
+	}
+	dir, err := os.MkdirTemp("", "pervigil-sysroot-")
+	if err != nil {
+		return nil, err
+	}
+	if err := Extract(path, dir); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return New(dir), nil
+}
+
+// Dir returns the root directory, or "" for the real filesystem.
+func (r *Root) Dir() string {
+	return r.dir
+}
+
+// Fake reports whether the root is not the real filesystem.
+func (r *Root) Fake() bool {
+	return r.dir != ""
+}
+
+// Path returns the location of the host path p below the root.
+func (r *Root) Path(p string) string {
+	if r.dir == "" {
+		return p
+	}
+	return filepath.Join(r.dir, p)
+}
+
+// ReadFile reads the host path p.
+func (r *Root) ReadFile(p string) ([]byte, error) {
+	return os.ReadFile(r.Path(p))
+}
+
+// Stat stats the host path p.
+func (r *Root) Stat(p string) (os.FileInfo, error) {
+	return os.Stat(r.Path(p))
+}
+
+// Glob matches a host path pattern and returns host paths.
+func (r *Root) Glob(pattern string) ([]string, error) {
+	matches, err := filepath.Glob(r.Path(pattern))
+	if err != nil || r.dir == "" {
+		return matches, err
+	}
+	for i, m := range matches {
+		rel, err := filepath.Rel(r.dir, m)
+		if err != nil {
+			return nil, err
+		}
+		matches[i] = "/" + rel
+	}
+	return matches, nil
+}
+
+// RunCommand runs a command, or returns its recorded output in a fake root.
+func (r *Root) RunCommand(name string, args ...string) ([]byte, error) {
+	return r.RunCommandContext(context.Background(), name, args...)
+}
+
+// RunCommandContext is RunCommand with the command killed when ctx is done.
+func (r *Root) RunCommandContext(ctx context.Context, name string, args ...string) ([]byte, error) {
+	if r.dir == "" {
+		return exec.CommandContext(ctx, name, args...).Output()
+	}
+	cmdline := strings.Join(append([]string{name}, args...), " ")
+	out, err := os.ReadFile(filepath.Join(r.dir, CommandsDir, strings.ReplaceAll(cmdline, "/", "_")))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", cmdline, ErrNotRecorded)
+	}
+	return out, err
+}
+
+var current atomic.Pointer[Root]
+
+func init() {
+	current.Store(New(""))
+}
+
+// Default returns the root used by the package-level readers.
+func Default() *Root {
+	return current.Load()
+}
+
+// SetDefault replaces the root used by the package-level readers.
+func SetDefault(r *Root) {
+	current.Store(r)
+}
+
+// Extract unpacks a fixture tarball into dir. Entries and symlinks that
+// would escape dir are rejected.
+func Extract(tarball, dir string) error {
+	f, err := os.Open(tarball)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var src io.Reader = f
+	if strings.HasSuffix(tarball, ".gz") || strings.HasSuffix(tarball, ".tgz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", tarball, err)
+		}
+		defer gz.Close()
+		src = gz
+	}
+
+	tr := tar.NewReader(src)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", tarball, err)
+		}
+		if err := extractEntry(tr, hdr, dir); err != nil {
+			return fmt.Errorf("%s: %s: %w", tarball, hdr.Name, err)
+		}
+	}
+}
+
+func extractEntry(tr *tar.Reader, hdr *tar.Header, dir string) error {
+	name := filepath.Clean(strings.TrimPrefix(hdr.Name, "/"))
+	if name == "." {
+		return nil
+	}
+	if !filepath.IsLocal(name) {
+		return errors.New("path escapes root")
+	}
+	target := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	// Parents may be symlinks extracted earlier; resolve them before writing
+	parent, err := filepath.EvalSymlinks(filepath.Dir(target))
+	if err != nil {
+		return err
+	}
+	if !within(dir, parent) {
+		return errors.New("path escapes root")
+	}
+	target = filepath.Join(parent, filepath.Base(target))
+
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(target, 0o755)
+	case tar.TypeReg:
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, tr); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	case tar.TypeSymlink:
+		// sysfs links are relative (class/net/eth1 -> ../../devices/...)
+		if filepath.IsAbs(hdr.Linkname) || !within(dir, filepath.Join(parent, hdr.Linkname)) {
+			return fmt.Errorf("symlink %q escapes root", hdr.Linkname)
+		}
+		return os.Symlink(hdr.Linkname, target)
+	default:
+		return nil
+	}
+}
+
+// within reports whether path p is dir or below it.
+func within(dir, p string) bool {
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(root, p)
+	return err == nil && filepath.IsLocal(rel)
+}
@@ -0,0 +1,139 @@
+package sysroot
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fixture is a capture of a VyOS router with an Intel X540-T2 (eth1/eth2),
+// laid out as scripts/capture-sysroot.sh produces it.
+const fixture = "testdata/router-x540.tar.gz"
+
+func extractFixture(t *testing.T) *Root {
+	t.Helper()
+	dir := t.TempDir()
+	if err := Extract(fixture, dir); err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	return New(dir)
+}
+
+func TestRoot_ReadFixture(t *testing.T) {
+	r := extractFixture(t)
+
+	// class/net/eth1 and its device link are relative sysfs symlinks
+	data, err := r.ReadFile("/sys/class/net/eth1/device/hwmon/hwmon3/temp1_input")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(data) != "62000\n" {
+		t.Errorf("temp1_input = %q", data)
+	}
+
+	matches, err := r.Glob("/sys/class/net/eth*/operstate")
+	if err != nil {
+		t.Fatalf("Glob() error = %v", err)
+	}
+	want := []string{"/sys/class/net/eth0/operstate", "/sys/class/net/eth1/operstate", "/sys/class/net/eth2/operstate"}
+	if !reflect.DeepEqual(matches, want) {
+		t.Errorf("Glob() = %v, want host paths %v", matches, want)
+	}
+
+	if _, err := r.Stat("/sys/class/net/eth3"); !os.IsNotExist(err) {
+		t.Errorf("Stat(eth3) error = %v, want not exist", err)
+	}
+}
+
+func TestRoot_RunCommand(t *testing.T) {
+	r := extractFixture(t)
+
+	out, err := r.RunCommand("uptime", "-p")
+	if err != nil {
+		t.Fatalf("RunCommand() error = %v", err)
+	}
+	if string(out) != "up 4 days, 3 hours, 0 minutes\n" {
+		t.Errorf("uptime -p = %q", out)
+	}
+
+	if _, err := r.RunCommand("sensors", "-u"); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("unrecorded command error = %v, want ErrNotRecorded", err)
+	}
+}
+
+func TestRoot_RunCommandContext_Killed(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := New("").RunCommandContext(ctx, "sleep", "10"); err == nil {
+		t.Fatal("RunCommandContext() error = nil, want the command killed")
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("hung command ran for %s after the deadline", d)
+	}
+}
+
+func TestRoot_Real(t *testing.T) {
+	for _, dir := range []string{"", "/"} {
+		r := New(dir)
+		if r.Fake() || r.Path("/proc/meminfo") != "/proc/meminfo" {
+			t.Errorf("New(%q) should be the real filesystem", dir)
+		}
+	}
+}
+
+func TestOpen_Tarball(t *testing.T) {
+	r, err := Open(fixture)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer os.RemoveAll(r.Dir())
+
+	if !r.Fake() {
+		t.Fatal("tarball root should be fake")
+	}
+	if _, err := r.ReadFile("/proc/meminfo"); err != nil {
+		t.Errorf("ReadFile() error = %v", err)
+	}
+}
+
+func TestExtract_RejectsEscape(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tar.Header
+	}{
+		{"parent path", []tar.Header{{Name: "../evil", Typeflag: tar.TypeReg}}},
+		{"absolute symlink", []tar.Header{{Name: "sys/link", Typeflag: tar.TypeSymlink, Linkname: "/etc"}}},
+		{"relative symlink", []tar.Header{{Name: "sys/link", Typeflag: tar.TypeSymlink, Linkname: "../../etc"}}},
+		{"through symlink", []tar.Header{
+			{Name: "a", Typeflag: tar.TypeSymlink, Linkname: "."},
+			{Name: "a/b", Typeflag: tar.TypeSymlink, Linkname: "../etc"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tarball := filepath.Join(t.TempDir(), "evil.tar")
+			f, err := os.Create(tarball)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tw := tar.NewWriter(f)
+			for _, hdr := range tt.entries {
+				if err := tw.WriteHeader(&hdr); err != nil {
+					t.Fatal(err)
+				}
+			}
+			tw.Close()
+			f.Close()
+
+			if err := Extract(tarball, t.TempDir()); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package temperature
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Sensor categories used as calibration fallbacks
+const (
+	CategoryCPU    = "cpu"
+	CategoryNIC    = "nic"
+	CategoryBoard  = "board"
+	CategoryZone   = "zone"
+	CategoryMemory = "memory"
+	CategoryDisk   = "disk"
+)
+
+// Thresholds are the warning/critical/recovery levels of a sensor.
+// Zero values mean the level is not defined.
+type Thresholds struct {
+	Warning  float64
+	Critical float64
+	Recovery float64
+}
+
+// Known reports whether warning and critical levels are defined.
+func (t Thresholds) Known() bool {
+	return t.Critical > 0
+}
+
+// overlay returns t with the non-zero levels of o applied.
+func (t Thresholds) overlay(o Thresholds) Thresholds {
+	if o.Warning != 0 {
+		t.Warning = o.Warning
+	}
+	if o.Critical != 0 {
+		t.Critical = o.Critical
+	}
+	if o.Recovery != 0 {
+		t.Recovery = o.Recovery
+	}
+	return t
+}
+
+// defaultThresholds are the built-in levels per category.
+// NIC levels match the Intel X540 operating range.
+var defaultThresholds = map[string]Thresholds{
+	CategoryNIC:    {Warning: 70, Critical: 85, Recovery: 65},
+	CategoryMemory: {Warning: 70, Critical: 90},
+	CategoryDisk:   {Warning: 70, Critical: 90},
+}
+
+// DefaultThresholds returns the built-in thresholds of a category.
+func DefaultThresholds(category string) Thresholds {
+	return defaultThresholds[category]
+}
+
+// Calibration corrects a sensor's raw value (raw*Scale + Offset), renames
+// it and sets its thresholds.
+type Calibration struct {
+	Offset float64
+	Scale  float64 // 0 means 1
+	Alias  string
+	Thresholds
+}
+
+// SetCalibration configures a sensor by label ("eth1", "Core 0",
+// "pch_cannonlake", "acpitz") or a whole category ("nic", "cpu", "board",
+// "zone", "memory", "disk"). A sensor setting replaces the category setting.
+func (r *Registry) SetCalibration(key string, c Calibration) {
+	if r.calibrations == nil {
+		r.calibrations = make(map[string]Calibration)
+	}
+	r.calibrations[key] = c
+}
+
+// Calibrate applies a calibration spec such as
+//
+//	eth1:offset=-4,alias=uplink,critical=90;nic:warning=72;memory:critical=95
+//
+// Sensors are separated by ";" and settings by ",".
+func (r *Registry) Calibrate(spec string) error {
+	calibrations, err := ParseCalibrations(spec)
+	if err != nil {
+		return err
+	}
+	for key, c := range calibrations {
+		r.SetCalibration(key, c)
+	}
+	return nil
+}
+
+// ParseCalibrations parses a calibration spec (see Registry.Calibrate).
+func ParseCalibrations(spec string) (map[string]Calibration, error) {
+	result := make(map[string]Calibration)
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, settings, ok := strings.Cut(part, ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid calibration %q (want sensor:key=value,...)", part)
+		}
+
+		var c Calibration
+		for _, setting := range strings.Split(settings, ",") {
+			name, value, ok := strings.Cut(strings.TrimSpace(setting), "=")
+			if !ok {
+				return nil, fmt.Errorf("%s: invalid setting %q", key, setting)
+			}
+			name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+			if name == "alias" {
+				c.Alias = value
+				continue
+			}
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", key, name, err)
+			}
+			switch name {
+			case "offset":
+				c.Offset = f
+			case "scale":
+				c.Scale = f
+			case "warning":
+				c.Warning = f
+			case "critical":
+				c.Critical = f
+			case "recovery":
+				c.Recovery = f
+			default:
+				return nil, fmt.Errorf("%s: unknown setting %q", key, name)
+			}
+		}
+		if c.Warning != 0 && c.Critical != 0 && c.Warning > c.Critical {
+			return nil, fmt.Errorf("%s: warning %.1f above critical %.1f", key, c.Warning, c.Critical)
+		}
+		result[key] = c
+	}
+	return result, nil
+}
+
+// Thresholds returns the effective thresholds of a category, or of the
+// first configured sensor key.
+func (r *Registry) Thresholds(category string, keys ...string) Thresholds {
+	var t TempReading
+	r.calibrate(&t, category, keys...)
+	return t.Thresholds
+}
+
+// ThresholdsFor returns the effective thresholds of a category from the
+// default registry (e.g. "memory", "disk"), or of the first configured
+// sensor key.
+func ThresholdsFor(category string, keys ...string) Thresholds {
+	return defaultRegistry.Load().Thresholds(category, keys...)
+}
+
+// calibrate applies the calibration of the first configured sensor key or,
+// if there is none, the category calibration; a sensor calibration replaces
+// the category one rather than adding to it. Thresholds already set on t
+// (e.g. from trip points) replace the built-in category defaults.
+func (r *Registry) calibrate(t *TempReading, category string, keys ...string) {
+	if !t.Thresholds.Known() {
+		t.Thresholds = defaultThresholds[category]
+	}
+	for _, key := range keys {
+		if c, ok := r.calibrations[key]; ok && key != category {
+			c.apply(t)
+			return
+		}
+	}
+	if c, ok := r.calibrations[category]; ok {
+		c.Alias = "" // a category alias would rename every sensor
+		c.apply(t)
+	}
+}
+
+func (c Calibration) apply(t *TempReading) {
+	scale := c.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	t.Value = t.Value*scale + c.Offset
+	t.Thresholds = t.Thresholds.overlay(c.Thresholds)
+	if c.Alias != "" {
+		t.Label = c.Alias
+	}
+}
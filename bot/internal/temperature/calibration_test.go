@@ -0,0 +1,112 @@
+package temperature
+
+import "testing"
+
+func TestParseCalibrations(t *testing.T) {
+	got, err := ParseCalibrations(" eth1:offset=-4.5,alias=uplink,critical=90 ; memory:warning=80,critical=95;Core 0:scale=1.02")
+	if err != nil {
+		t.Fatalf("ParseCalibrations() error = %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 calibrations, got %d", len(got))
+	}
+	if c := got["eth1"]; c.Offset != -4.5 || c.Alias != "uplink" || c.Critical != 90 || c.Warning != 0 {
+		t.Errorf("eth1 = %+v", c)
+	}
+	if c := got["memory"]; c.Warning != 80 || c.Critical != 95 {
+		t.Errorf("memory = %+v", c)
+	}
+	if c := got["Core 0"]; c.Scale != 1.02 {
+		t.Errorf("Core 0 = %+v", c)
+	}
+}
+
+func TestParseCalibrations_Invalid(t *testing.T) {
+	tests := []string{
+		"eth1",
+		":offset=1",
+		"eth1:offset",
+		"eth1:offset=hot",
+		"eth1:bias=2",
+		"eth1:warning=90,critical=80",
+	}
+	for _, spec := range tests {
+		if _, err := ParseCalibrations(spec); err == nil {
+			t.Errorf("ParseCalibrations(%q) expected error", spec)
+		}
+	}
+}
+
+func TestRegistry_Calibrate_NIC(t *testing.T) {
+	deps := &mapSensorDeps{
+		cmdOutput: map[string]string{
+			"ethtool -m eth1": "Module temperature : 60.0",
+			"ethtool -m eth2": "Module temperature : 60.0",
+		},
+	}
+	r := NewRegistryWith(deps)
+	if err := r.Configure("nic=ethtool-m"); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	if err := r.Calibrate("nic:warning=72;eth1:offset=-5,scale=1.5,alias=uplink,critical=95"); err != nil {
+		t.Fatalf("Calibrate() error = %v", err)
+	}
+
+	eth1, err := r.NIC(t.Context(), "eth1")
+	if err != nil {
+		t.Fatalf("NIC(eth1) error = %v", err)
+	}
+	if eth1.Value != 85 || eth1.Label != "uplink" {
+		t.Errorf("eth1 = %+v, want 60*1.5-5 labelled uplink", eth1)
+	}
+	// The eth1 calibration replaces the nic one, so warning keeps its default
+	if want := (Thresholds{Warning: 70, Critical: 95, Recovery: 65}); eth1.Thresholds != want {
+		t.Errorf("eth1 thresholds = %+v, want %+v", eth1.Thresholds, want)
+	}
+
+	eth2, err := r.NIC(t.Context(), "eth2")
+	if err != nil {
+		t.Fatalf("NIC(eth2) error = %v", err)
+	}
+	if eth2.Value != 60 || eth2.Label != "eth2" {
+		t.Errorf("eth2 = %+v, want uncalibrated value", eth2)
+	}
+	if want := (Thresholds{Warning: 72, Critical: 85, Recovery: 65}); eth2.Thresholds != want {
+		t.Errorf("eth2 thresholds = %+v, want %+v", eth2.Thresholds, want)
+	}
+}
+
+func TestRegistry_Calibrate_ZoneKeepsTrips(t *testing.T) {
+	r := NewRegistryWith(fanlessZoneDeps())
+	if err := r.Calibrate("acpitz:warning=50,critical=60;thermal_zone0:offset=2"); err != nil {
+		t.Fatalf("Calibrate() error = %v", err)
+	}
+
+	zones, err := r.Zones()
+	if err != nil {
+		t.Fatalf("Zones() error = %v", err)
+	}
+	cpu, acpi := zones[0], zones[1]
+	if cpu.Value != 50.312 || cpu.Thresholds != (Thresholds{Warning: 80, Critical: 95}) {
+		t.Errorf("cpu-thermal = %+v, want offset value and trip thresholds", cpu)
+	}
+	if acpi.Thresholds != (Thresholds{Warning: 50, Critical: 60}) {
+		t.Errorf("acpitz thresholds = %+v", acpi.Thresholds)
+	}
+}
+
+func TestRegistry_Thresholds(t *testing.T) {
+	r := NewRegistryWith(&mapSensorDeps{})
+	if got := r.Thresholds(CategoryDisk); got != (Thresholds{Warning: 70, Critical: 90}) {
+		t.Errorf("disk defaults = %+v", got)
+	}
+	if err := r.Calibrate("disk:critical=95"); err != nil {
+		t.Fatalf("Calibrate() error = %v", err)
+	}
+	if got := r.Thresholds(CategoryDisk); got != (Thresholds{Warning: 70, Critical: 95}) {
+		t.Errorf("disk calibrated = %+v", got)
+	}
+	if r.Thresholds(CategoryCPU).Known() {
+		t.Error("cpu has no default thresholds")
+	}
+}
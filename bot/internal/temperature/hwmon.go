@@ -0,0 +1,190 @@
+package temperature
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SensorKind is the hwmon attribute class of a reading.
+type SensorKind string
+
+const (
+	KindTemperature SensorKind = "temp"
+	KindFan         SensorKind = "fan"
+	KindVoltage     SensorKind = "in"
+	KindPower       SensorKind = "power"
+	KindCurrent     SensorKind = "curr"
+)
+
+// sensorKinds lists the hwmon attribute classes in display order
+var sensorKinds = []SensorKind{KindTemperature, KindFan, KindVoltage, KindPower, KindCurrent}
+
+// hwmonScale converts raw sysfs values to display units
+// (Documentation/hwmon/sysfs-interface.rst)
+var hwmonScale = map[SensorKind]float64{
+	KindTemperature: 1000,    // m°C → °C
+	KindFan:         1,       // RPM
+	KindVoltage:     1000,    // mV → V
+	KindPower:       1000000, // µW → W
+	KindCurrent:     1000,    // mA → A
+}
+
+var sensorUnits = map[SensorKind]string{
+	KindTemperature: "°C",
+	KindFan:         "RPM",
+	KindVoltage:     "V",
+	KindPower:       "W",
+	KindCurrent:     "A",
+}
+
+// alarmSuffixes are the per-attribute alarm and fault flags
+var alarmSuffixes = []string{"_alarm", "_min_alarm", "_max_alarm", "_lcrit_alarm", "_crit_alarm", "_fault"}
+
+// SensorReading is a single hwmon attribute with its limits.
+// Limits are nil when the chip does not report them.
+type SensorReading struct {
+	Chip  string // hwmon name (e.g. nct6775)
+	Attr  string // attribute base name (e.g. fan2)
+	Kind  SensorKind
+	Label string // *_label, empty when absent
+	Value float64
+	Min   *float64
+	Max   *float64
+	Crit  *float64
+	Alarm bool // any *_alarm or *_fault flag set
+}
+
+// Key uniquely identifies the reading on this host.
+func (r SensorReading) Key() string {
+	return r.Chip + "/" + r.Attr
+}
+
+// Name returns the label, falling back to the attribute name.
+func (r SensorReading) Name() string {
+	if r.Label != "" {
+		return r.Label
+	}
+	return r.Attr
+}
+
+// Unit returns the unit of Value.
+func (r SensorReading) Unit() string {
+	return sensorUnits[r.Kind]
+}
+
+// Stalled reports a fan at 0 RPM that the chip expects to spin.
+func (r SensorReading) Stalled() bool {
+	return r.Kind == KindFan && r.Value == 0 && r.Min != nil && *r.Min > 0
+}
+
+// OutOfRange reports a value beyond its min/max/crit limits.
+func (r SensorReading) OutOfRange() bool {
+	switch {
+	case r.Min != nil && r.Value < *r.Min:
+		return true
+	case r.Max != nil && *r.Max > 0 && r.Value > *r.Max:
+		return true
+	case r.Crit != nil && *r.Crit > 0 && r.Value >= *r.Crit:
+		return true
+	}
+	return false
+}
+
+// FormatValue formats the value with its unit.
+func (r SensorReading) FormatValue() string {
+	switch r.Kind {
+	case KindFan:
+		return fmt.Sprintf("%.0f %s", r.Value, r.Unit())
+	case KindTemperature:
+		return fmt.Sprintf("%.1f%s", r.Value, r.Unit())
+	default:
+		return fmt.Sprintf("%.3f %s", r.Value, r.Unit())
+	}
+}
+
+// GetHwmonSensors returns every hwmon temperature, fan, voltage, power
+// and current reading.
+func GetHwmonSensors() ([]SensorReading, error) {
+	return GetHwmonSensorsWith(&osDeps{})
+}
+
+// GetHwmonSensorsWith returns hwmon readings using provided deps (for testing).
+func GetHwmonSensorsWith(d hwmonDeps) ([]SensorReading, error) {
+	var all []SensorReading
+	for _, kind := range sensorKinds {
+		readings, err := scanHwmon(d, kind)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, readings...)
+	}
+	return all, nil
+}
+
+// scanHwmon reads all <kind>*_input attributes under /sys/class/hwmon.
+// Chips without a name are skipped.
+func scanHwmon(d hwmonDeps, kind SensorKind) ([]SensorReading, error) {
+	paths, err := d.Glob("/sys/class/hwmon/hwmon*/" + string(kind) + "*_input")
+	if err != nil {
+		return nil, err
+	}
+
+	scale := hwmonScale[kind]
+	var readings []SensorReading
+	for _, path := range paths {
+		dir := filepath.Dir(path)
+		name, _ := d.ReadFile(filepath.Join(dir, "name"))
+		chip := strings.TrimSpace(string(name))
+		if chip == "" {
+			continue
+		}
+
+		val, err := readScaled(d, path, scale)
+		if err != nil {
+			continue
+		}
+
+		base := strings.TrimSuffix(path, "_input")
+		label, _ := d.ReadFile(base + "_label")
+		r := SensorReading{
+			Chip:  chip,
+			Attr:  filepath.Base(base),
+			Kind:  kind,
+			Label: strings.TrimSpace(string(label)),
+			Value: val,
+			Min:   readLimit(d, base+"_min", scale),
+			Max:   readLimit(d, base+"_max", scale),
+			Crit:  readLimit(d, base+"_crit", scale),
+		}
+		for _, suffix := range alarmSuffixes {
+			if flag, err := d.ReadFile(base + suffix); err == nil && strings.TrimSpace(string(flag)) == "1" {
+				r.Alarm = true
+				break
+			}
+		}
+		readings = append(readings, r)
+	}
+	return readings, nil
+}
+
+func readScaled(d fileReadable, path string, scale float64) (float64, error) {
+	data, err := d.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	val, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return val / scale, nil
+}
+
+func readLimit(d fileReadable, path string, scale float64) *float64 {
+	val, err := readScaled(d, path, scale)
+	if err != nil {
+		return nil
+	}
+	return &val
+}
@@ -0,0 +1,103 @@
+package temperature
+
+import "testing"
+
+func superIODeps() *mapSensorDeps {
+	return &mapSensorDeps{
+		globResults: map[string][]string{
+			"/sys/class/hwmon/hwmon*/temp*_input":  {"/sys/class/hwmon/hwmon1/temp1_input"},
+			"/sys/class/hwmon/hwmon*/fan*_input":   {"/sys/class/hwmon/hwmon1/fan1_input", "/sys/class/hwmon/hwmon1/fan2_input"},
+			"/sys/class/hwmon/hwmon*/in*_input":    {"/sys/class/hwmon/hwmon1/in0_input"},
+			"/sys/class/hwmon/hwmon*/power*_input": {"/sys/class/hwmon/hwmon2/power1_input"},
+			"/sys/class/hwmon/hwmon*/curr*_input":  {"/sys/class/hwmon/hwmon2/curr1_input"},
+		},
+		files: map[string]string{
+			"/sys/class/hwmon/hwmon1/name":         "nct6775\n",
+			"/sys/class/hwmon/hwmon1/temp1_input":  "41000\n",
+			"/sys/class/hwmon/hwmon1/temp1_max":    "80000\n",
+			"/sys/class/hwmon/hwmon1/temp1_crit":   "95000\n",
+			"/sys/class/hwmon/hwmon1/fan1_input":   "1250\n",
+			"/sys/class/hwmon/hwmon1/fan1_label":   "CPU Fan\n",
+			"/sys/class/hwmon/hwmon1/fan1_min":     "300\n",
+			"/sys/class/hwmon/hwmon1/fan1_alarm":   "0\n",
+			"/sys/class/hwmon/hwmon1/fan2_input":   "0\n",
+			"/sys/class/hwmon/hwmon1/fan2_min":     "500\n",
+			"/sys/class/hwmon/hwmon1/fan2_alarm":   "1\n",
+			"/sys/class/hwmon/hwmon1/in0_input":    "1104\n",
+			"/sys/class/hwmon/hwmon1/in0_label":    "Vcore\n",
+			"/sys/class/hwmon/hwmon1/in0_min":      "1000\n",
+			"/sys/class/hwmon/hwmon1/in0_max":      "1500\n",
+			"/sys/class/hwmon/hwmon2/name":         "ina219\n",
+			"/sys/class/hwmon/hwmon2/power1_input": "12500000\n",
+			"/sys/class/hwmon/hwmon2/curr1_input":  "1040\n",
+		},
+	}
+}
+
+func TestGetHwmonSensors(t *testing.T) {
+	readings, err := GetHwmonSensorsWith(superIODeps())
+	if err != nil {
+		t.Fatalf("GetHwmonSensorsWith() error = %v", err)
+	}
+	if len(readings) != 6 {
+		t.Fatalf("expected 6 readings, got %d", len(readings))
+	}
+
+	byKey := make(map[string]SensorReading)
+	for _, r := range readings {
+		byKey[r.Key()] = r
+	}
+
+	temp := byKey["nct6775/temp1"]
+	if temp.Kind != KindTemperature || temp.Value != 41 || temp.Crit == nil || *temp.Crit != 95 {
+		t.Errorf("temp1 = %+v", temp)
+	}
+
+	fan1 := byKey["nct6775/fan1"]
+	if fan1.Name() != "CPU Fan" || fan1.Value != 1250 || fan1.Alarm || fan1.Stalled() {
+		t.Errorf("fan1 = %+v", fan1)
+	}
+	if fan1.FormatValue() != "1250 RPM" {
+		t.Errorf("fan1 format = %q", fan1.FormatValue())
+	}
+
+	fan2 := byKey["nct6775/fan2"]
+	if !fan2.Alarm || !fan2.Stalled() || fan2.Name() != "fan2" {
+		t.Errorf("fan2 = %+v, want alarm and stalled", fan2)
+	}
+
+	vcore := byKey["nct6775/in0"]
+	if vcore.Value != 1.104 || *vcore.Min != 1 || *vcore.Max != 1.5 || vcore.OutOfRange() {
+		t.Errorf("in0 = %+v", vcore)
+	}
+
+	power := byKey["ina219/power1"]
+	if power.Value != 12.5 || power.Unit() != "W" || power.Max != nil {
+		t.Errorf("power1 = %+v", power)
+	}
+	if curr := byKey["ina219/curr1"]; curr.Value != 1.04 || curr.Unit() != "A" {
+		t.Errorf("curr1 = %+v", curr)
+	}
+}
+
+func TestSensorReading_OutOfRange(t *testing.T) {
+	lo, hi, zero := 1.0, 1.5, 0.0
+	tests := []struct {
+		name string
+		r    SensorReading
+		want bool
+	}{
+		{"in range", SensorReading{Value: 1.2, Min: &lo, Max: &hi}, false},
+		{"below min", SensorReading{Value: 0.9, Min: &lo, Max: &hi}, true},
+		{"above max", SensorReading{Value: 1.6, Min: &lo, Max: &hi}, true},
+		{"unset max ignored", SensorReading{Value: 5, Max: &zero}, false},
+		{"no limits", SensorReading{Value: 100}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.r.OutOfRange(); got != tt.want {
+				t.Errorf("OutOfRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,14 +1,17 @@
 package temperature
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
+	"maps"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+
+	"github.com/murata-lab/pervigil/bot/internal/ethtool"
+	"github.com/murata-lab/pervigil/bot/internal/sysroot"
 )
 
 // ErrSensorUnavailable indicates the hardware lacks a temperature sensor.
@@ -16,9 +19,10 @@ var ErrSensorUnavailable = errors.New("temperature sensor unavailable")
 
 // Single-responsibility interfaces (ISP)
 
-// commandRunnable abstracts command execution
+// commandRunnable abstracts command execution; commands are killed when
+// ctx is done
 type commandRunnable interface {
-	RunCommand(name string, args ...string) ([]byte, error)
+	RunCommand(ctx context.Context, name string, args ...string) ([]byte, error)
 }
 
 // fileReadable abstracts file reading
@@ -31,71 +35,82 @@ type globbable interface {
 	Glob(pattern string) ([]string, error)
 }
 
+// ethtoolQueryable abstracts kernel ethtool queries (SIOCETHTOOL)
+type ethtoolQueryable interface {
+	ModuleTemperature(iface string) (float64, error)
+	Stats(iface string) (map[string]uint64, error)
+}
+
 // sensorDeps combines interfaces needed for sensor operations
 type sensorDeps interface {
 	commandRunnable
 	fileReadable
 	globbable
+	ethtoolQueryable
 }
 
-// osDeps is the production implementation
+// osDeps is the production implementation. Files and commands go through
+// the default sysroot; kernel queries are unavailable under a fake root.
 type osDeps struct{}
 
-func (o *osDeps) RunCommand(name string, args ...string) ([]byte, error) {
-	return exec.Command(name, args...).Output()
+func (o *osDeps) RunCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return sysroot.Default().RunCommandContext(ctx, name, args...)
 }
 
 func (o *osDeps) ReadFile(path string) ([]byte, error) {
-	return os.ReadFile(path)
+	return sysroot.Default().ReadFile(path)
 }
 
 func (o *osDeps) Glob(pattern string) ([]string, error) {
-	return filepath.Glob(pattern)
+	return sysroot.Default().Glob(pattern)
+}
+
+func (o *osDeps) ModuleTemperature(iface string) (float64, error) {
+	if sysroot.Default().Fake() {
+		return 0, errFakeRoot
+	}
+	return ethtool.New().ModuleTemperature(iface)
 }
 
+func (o *osDeps) Stats(iface string) (map[string]uint64, error) {
+	if sysroot.Default().Fake() {
+		return nil, errFakeRoot
+	}
+	return ethtool.New().Stats(iface)
+}
+
+var errFakeRoot = errors.New("kernel queries unavailable under a fake sysroot")
+
+// TempReading is a temperature in °C and the source that produced it.
 type TempReading struct {
-	Label string
-	Value float64
+	Label      string
+	Value      float64
+	Source     string
+	Thresholds Thresholds // calibrated levels; zero when unknown
 }
 
 // GetCPUTemps returns CPU core temperatures
-func GetCPUTemps() ([]TempReading, error) {
-	return GetCPUTempsWith(&osDeps{})
+func GetCPUTemps(ctx context.Context) ([]TempReading, error) {
+	return defaultRegistry.Load().CPU(ctx)
 }
 
 // GetCPUTempsWith returns CPU core temperatures using provided deps (for testing)
-func GetCPUTempsWith(d sensorDeps) ([]TempReading, error) {
-	// Try lm-sensors first
-	if temps, err := getCPUFromSensors(d); err == nil && len(temps) > 0 {
-		return temps, nil
-	}
-
-	// Fallback to hwmon
-	return getCPUFromHwmon(d)
+func GetCPUTempsWith(ctx context.Context, d sensorDeps) ([]TempReading, error) {
+	return NewRegistryWith(d).CPU(ctx)
 }
 
 // GetNICTemp returns NIC temperature
-func GetNICTemp(iface string) (*TempReading, error) {
-	return GetNICTempWith(iface, &osDeps{})
+func GetNICTemp(ctx context.Context, iface string) (*TempReading, error) {
+	return defaultRegistry.Load().NIC(ctx, iface)
 }
 
 // GetNICTempWith returns NIC temperature using provided deps (for testing)
-func GetNICTempWith(iface string, d sensorDeps) (*TempReading, error) {
-	if iface == "" {
-		iface = "eth1"
-	}
-
-	// Try ethtool first (most reliable for Intel NICs)
-	if temp, err := getNICFromEthtool(iface, d); err == nil {
-		return temp, nil
-	}
-
-	// Fallback to hwmon
-	return getNICFromHwmon(iface, d)
+func GetNICTempWith(ctx context.Context, iface string, d sensorDeps) (*TempReading, error) {
+	return NewRegistryWith(d).NIC(ctx, iface)
 }
 
-func getCPUFromSensors(d commandRunnable) ([]TempReading, error) {
-	out, err := d.RunCommand("sensors", "-u")
+func getCPUFromSensors(ctx context.Context, d commandRunnable) ([]TempReading, error) {
+	out, err := d.RunCommand(ctx, "sensors", "-u")
 	if err != nil {
 		return nil, err
 	}
@@ -126,36 +141,20 @@ func isCPUHwmon(name string) bool {
 // scanHwmonTemps scans /sys/class/hwmon and splits results into CPU and board temps.
 // This avoids duplicate glob I/O when both are needed.
 func scanHwmonTemps(d hwmonDeps) (cpu, board []TempReading, err error) {
-	hwmonDirs, err := d.Glob("/sys/class/hwmon/hwmon*/temp*_input")
+	readings, err := scanHwmon(d, KindTemperature)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	for _, path := range hwmonDirs {
-		dir := filepath.Dir(path)
-		name, _ := d.ReadFile(filepath.Join(dir, "name"))
-		nameStr := strings.TrimSpace(string(name))
-
-		if nameStr == "" {
-			continue
-		}
-
-		data, err := d.ReadFile(path)
-		if err != nil {
-			continue
-		}
-		val, _ := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
-
-		if isCPUHwmon(nameStr) {
-			labelPath := strings.Replace(path, "_input", "_label", 1)
-			label, _ := d.ReadFile(labelPath)
-			labelStr := strings.TrimSpace(string(label))
-			if labelStr == "" {
-				labelStr = nameStr
+	for _, r := range readings {
+		if isCPUHwmon(r.Chip) {
+			label := r.Label
+			if label == "" {
+				label = r.Chip
 			}
-			cpu = append(cpu, TempReading{Label: labelStr, Value: val / 1000})
+			cpu = append(cpu, TempReading{Label: label, Value: r.Value})
 		} else {
-			board = append(board, TempReading{Label: nameStr, Value: val / 1000})
+			board = append(board, TempReading{Label: r.Chip, Value: r.Value})
 		}
 	}
 
@@ -167,32 +166,48 @@ func getCPUFromHwmon(d hwmonDeps) ([]TempReading, error) {
 	return cpu, err
 }
 
-// ethtoolDeps is the minimal interface for ethtool operations
-type ethtoolDeps interface {
-	commandRunnable
-	fileReadable
-	globbable
+// getNICFromKernel reads the module DOM temperature, then the driver
+// "temp" statistic (ixgbe), via SIOCETHTOOL without the ethtool binary.
+func getNICFromKernel(iface string, d ethtoolQueryable) (*TempReading, error) {
+	if val, err := d.ModuleTemperature(iface); err == nil {
+		return &TempReading{Label: iface, Value: val}, nil
+	}
+
+	stats, err := d.Stats(iface)
+	if err != nil {
+		return nil, err
+	}
+	if val, ok := stats["temp"]; ok {
+		return &TempReading{Label: iface, Value: float64(val)}, nil
+	}
+	// Drivers with several sensors (e.g. "phy_temp", "sensor_temp") report
+	// the first by name, so the same sensor is read on every run
+	for _, name := range slices.Sorted(maps.Keys(stats)) {
+		if strings.HasSuffix(name, "_temp") {
+			return &TempReading{Label: iface, Value: float64(stats[name])}, nil
+		}
+	}
+	return nil, fmt.Errorf("temperature not found in kernel stats")
 }
 
-func getNICFromEthtool(iface string, d ethtoolDeps) (*TempReading, error) {
-	out, err := d.RunCommand("ethtool", "-m", iface)
+func getNICFromEthtool(ctx context.Context, iface string, d commandRunnable) (*TempReading, error) {
+	out, err := d.RunCommand(ctx, "ethtool", "-m", iface)
 	if err != nil {
-		// Try alternative: ethtool -S for ixgbe driver
-		return getNICFromEthtoolStats(iface, d)
+		return nil, err
 	}
 
 	re := regexp.MustCompile(`Module temperature\s*:\s*([\d.]+)`)
 	match := re.FindStringSubmatch(string(out))
 	if match == nil {
-		return getNICFromEthtoolStats(iface, d)
+		return nil, fmt.Errorf("module temperature not found in ethtool -m output")
 	}
 
 	val, _ := strconv.ParseFloat(match[1], 64)
 	return &TempReading{Label: iface, Value: val}, nil
 }
 
-func getNICFromEthtoolStats(iface string, d commandRunnable) (*TempReading, error) {
-	out, err := d.RunCommand("ethtool", "-S", iface)
+func getNICFromEthtoolStats(ctx context.Context, iface string, d commandRunnable) (*TempReading, error) {
+	out, err := d.RunCommand(ctx, "ethtool", "-S", iface)
 	if err != nil {
 		return nil, err
 	}
@@ -229,40 +244,23 @@ func getNICFromHwmon(iface string, d hwmonDeps) (*TempReading, error) {
 
 // GetBoardTemps returns non-CPU hwmon temperatures (e.g. PCH chipset).
 func GetBoardTemps() ([]TempReading, error) {
-	return GetBoardTempsWith(&osDeps{})
+	return defaultRegistry.Load().Board()
 }
 
 // GetBoardTempsWith returns non-CPU hwmon temperatures using provided deps.
-func GetBoardTempsWith(d hwmonDeps) ([]TempReading, error) {
-	_, board, err := scanHwmonTemps(d)
-	return board, err
+func GetBoardTempsWith(d sensorDeps) ([]TempReading, error) {
+	return NewRegistryWith(d).Board()
 }
 
-// GetAllTemps returns all available temperature readings (supports comma-separated NICs)
-func GetAllTemps(nicIfaces string) (cpu, nics, board []TempReading) {
-	return GetAllTempsWith(nicIfaces, &osDeps{})
+// GetAllTemps returns all available temperature readings (supports comma-separated NICs).
+// zones are /sys/class/thermal zones, whose trip points provide default thresholds.
+func GetAllTemps(ctx context.Context, nicIfaces string) (cpu, nics, board []TempReading, zones []ThermalZone) {
+	return defaultRegistry.Load().All(ctx, nicIfaces)
 }
 
 // GetAllTempsWith returns all temperatures using provided deps (for testing).
-func GetAllTempsWith(nicIfaces string, d sensorDeps) (cpu, nics, board []TempReading) {
-	// Try lm-sensors first for CPU
-	if temps, err := getCPUFromSensors(d); err == nil && len(temps) > 0 {
-		cpu = temps
-	}
-
-	// Single hwmon scan for both CPU fallback and board temps
-	hwCPU, hwBoard, _ := scanHwmonTemps(d)
-	if len(cpu) == 0 {
-		cpu = hwCPU
-	}
-	board = hwBoard
-
-	for _, iface := range splitInterfaces(nicIfaces) {
-		if t, err := GetNICTempWith(iface, d); err == nil {
-			nics = append(nics, *t)
-		}
-	}
-	return
+func GetAllTempsWith(ctx context.Context, nicIfaces string, d sensorDeps) (cpu, nics, board []TempReading, zones []ThermalZone) {
+	return NewRegistryWith(d).All(ctx, nicIfaces)
 }
 
 // splitInterfaces splits comma-separated interface names
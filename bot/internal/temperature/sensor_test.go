@@ -1,6 +1,7 @@
 package temperature
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
@@ -12,13 +13,20 @@ type mapSensorDeps struct {
 	cmdErr      map[string]error
 	files       map[string]string
 	globResults map[string][]string // pattern → results
+	moduleTemps map[string]float64  // iface → kernel module temperature
+	stats       map[string]map[string]uint64
+	hang        map[string]bool // commands that run until ctx is done
 }
 
-func (d *mapSensorDeps) RunCommand(name string, args ...string) ([]byte, error) {
+func (d *mapSensorDeps) RunCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
 	key := name
 	for _, a := range args {
 		key += " " + a
 	}
+	if d.hang[key] {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
 	if err, ok := d.cmdErr[key]; ok && err != nil {
 		return nil, err
 	}
@@ -42,6 +50,20 @@ func (d *mapSensorDeps) Glob(pattern string) ([]string, error) {
 	return nil, nil
 }
 
+func (d *mapSensorDeps) ModuleTemperature(iface string) (float64, error) {
+	if v, ok := d.moduleTemps[iface]; ok {
+		return v, nil
+	}
+	return 0, fmt.Errorf("module temperature not supported: %s", iface)
+}
+
+func (d *mapSensorDeps) Stats(iface string) (map[string]uint64, error) {
+	if s, ok := d.stats[iface]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("stats not supported: %s", iface)
+}
+
 type testError struct {
 	msg string
 }
@@ -62,7 +84,7 @@ Core 1:
 		},
 	}
 
-	temps, err := GetCPUTempsWith(deps)
+	temps, err := GetCPUTempsWith(t.Context(), deps)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -83,7 +105,7 @@ func TestGetNICTemp_FromEthtool(t *testing.T) {
 		},
 	}
 
-	temp, err := GetNICTempWith("eth1", deps)
+	temp, err := GetNICTempWith(t.Context(), "eth1", deps)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -103,7 +125,7 @@ func TestGetNICTemp_DefaultInterface(t *testing.T) {
 		},
 	}
 
-	temp, err := GetNICTempWith("", deps)
+	temp, err := GetNICTempWith(t.Context(), "", deps)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -131,7 +153,7 @@ func TestGetCPUTemps_FallbackToHwmon(t *testing.T) {
 		},
 	}
 
-	temps, err := GetCPUTempsWith(deps)
+	temps, err := GetCPUTempsWith(t.Context(), deps)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -156,7 +178,7 @@ func TestGetNICTemp_FallbackToEthtoolStats(t *testing.T) {
 		},
 	}
 
-	temp, err := GetNICTempWith("eth1", deps)
+	temp, err := GetNICTempWith(t.Context(), "eth1", deps)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -181,7 +203,7 @@ func TestGetNICTemp_FallbackToHwmon(t *testing.T) {
 		},
 	}
 
-	temp, err := GetNICTempWith("eth1", deps)
+	temp, err := GetNICTempWith(t.Context(), "eth1", deps)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -201,7 +223,7 @@ func TestGetNICTemp_AllFailed_SentinelError(t *testing.T) {
 		// globResults に NIC hwmon パスなし → 空結果
 	}
 
-	_, err := GetNICTempWith("eth1", deps)
+	_, err := GetNICTempWith(t.Context(), "eth1", deps)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -293,7 +315,7 @@ Core 0:
 		},
 	}
 
-	cpu, nics, board := GetAllTempsWith("eth1", deps)
+	cpu, nics, board, zones := GetAllTempsWith(t.Context(), "eth1", deps)
 
 	if len(cpu) != 1 {
 		t.Fatalf("expected 1 CPU temp, got %d", len(cpu))
@@ -313,6 +335,9 @@ Core 0:
 	if board[0].Value != 52.0 {
 		t.Errorf("expected board temp 52.0, got %f", board[0].Value)
 	}
+	if len(zones) != 0 {
+		t.Errorf("expected no thermal zones, got %d", len(zones))
+	}
 }
 
 func TestGetBoardTemps_SkipsEmptyName(t *testing.T) {
@@ -339,3 +364,73 @@ func TestGetBoardTemps_SkipsEmptyName(t *testing.T) {
 		t.Fatalf("expected 1 board temp (skipping empty name), got %d", len(temps))
 	}
 }
+
+func TestGetNICTemp_FromKernelModule(t *testing.T) {
+	deps := &mapSensorDeps{
+		moduleTemps: map[string]float64{"eth1": 41.5},
+		cmdOutput: map[string]string{
+			"ethtool -m eth1": `Module temperature : 55.5`,
+		},
+	}
+
+	temp, err := GetNICTempWith(t.Context(), "eth1", deps)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if temp.Value != 41.5 {
+		t.Errorf("expected kernel module temp 41.5, got %f", temp.Value)
+	}
+}
+
+func TestGetNICTemp_FromKernelStats(t *testing.T) {
+	deps := &mapSensorDeps{
+		stats: map[string]map[string]uint64{
+			"eth1": {"rx_packets": 100, "temp": 52},
+		},
+	}
+
+	temp, err := GetNICTempWith(t.Context(), "eth1", deps)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if temp.Value != 52 {
+		t.Errorf("expected kernel stats temp 52, got %f", temp.Value)
+	}
+}
+
+func TestGetNICTemp_FromKernelStats_SeveralSensors(t *testing.T) {
+	deps := &mapSensorDeps{
+		stats: map[string]map[string]uint64{
+			"eth1": {"sensor_temp": 61, "phy_temp": 48, "board_temp": 44, "rx_packets": 100},
+		},
+	}
+
+	for range 20 {
+		temp, err := GetNICTempWith(t.Context(), "eth1", deps)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if temp.Value != 44 {
+			t.Fatalf("expected the first sensor by name (board_temp 44), got %f", temp.Value)
+		}
+	}
+}
+
+func TestGetNICTemp_KernelStatsWithoutTemp_FallsBackToBinary(t *testing.T) {
+	deps := &mapSensorDeps{
+		stats: map[string]map[string]uint64{
+			"eth1": {"rx_packets": 100},
+		},
+		cmdOutput: map[string]string{
+			"ethtool -m eth1": `Module temperature : 55.5`,
+		},
+	}
+
+	temp, err := GetNICTempWith(t.Context(), "eth1", deps)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if temp.Value != 55.5 {
+		t.Errorf("expected ethtool binary temp 55.5, got %f", temp.Value)
+	}
+}
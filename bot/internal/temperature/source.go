@@ -0,0 +1,474 @@
+package temperature
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Source names accepted in a source chain
+const (
+	SourceLMSensors    = "lm-sensors"
+	SourceHwmon        = "hwmon"
+	SourceThermalZone  = "thermal_zone"
+	SourceEthtoolIoctl = "ethtool-ioctl"
+	SourceEthtoolM     = "ethtool-m"
+	SourceEthtoolS     = "ethtool-S"
+	SourceIxgbeProcfs  = "ixgbe-procfs"
+	SourceCommand      = "command"
+)
+
+// Device chain keys; any other key is a NIC interface name
+const (
+	DeviceCPU = "cpu"
+	DeviceNIC = "nic"
+)
+
+// Source reads temperatures for a device ("cpu" or a NIC interface name).
+// Commands a source runs are killed when ctx is done.
+type Source interface {
+	Read(ctx context.Context, device string) ([]TempReading, error)
+}
+
+// SourceConfig selects a source and its per-device argument.
+// Arg is a path for hwmon/thermal_zone/ixgbe-procfs and a command line
+// for command; "{device}" in Arg is replaced with the device name.
+type SourceConfig struct {
+	Name string
+	Arg  string
+}
+
+func (c SourceConfig) String() string {
+	if c.Arg == "" {
+		return c.Name
+	}
+	return c.Name + ":" + c.Arg
+}
+
+type sourceFactory func(arg string, d sensorDeps) Source
+
+// sourceFactories is the registry of known sources
+var sourceFactories = map[string]sourceFactory{
+	SourceLMSensors:    func(_ string, d sensorDeps) Source { return &lmSensorsSource{d: d} },
+	SourceHwmon:        func(arg string, d sensorDeps) Source { return &hwmonSource{path: arg, d: d} },
+	SourceThermalZone:  func(arg string, d sensorDeps) Source { return &thermalZoneSource{zone: arg, d: d} },
+	SourceEthtoolIoctl: func(_ string, d sensorDeps) Source { return &ethtoolIoctlSource{d: d} },
+	SourceEthtoolM:     func(_ string, d sensorDeps) Source { return &ethtoolModuleSource{d: d} },
+	SourceEthtoolS:     func(_ string, d sensorDeps) Source { return &ethtoolStatsSource{d: d} },
+	SourceIxgbeProcfs:  func(arg string, d sensorDeps) Source { return &ixgbeProcfsSource{path: arg, d: d} },
+	SourceCommand:      func(arg string, d sensorDeps) Source { return &commandSource{cmdline: arg, d: d} },
+}
+
+// SourceNames returns the names of all registered sources.
+func SourceNames() []string {
+	names := make([]string, 0, len(sourceFactories))
+	for name := range sourceFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// defaultChains reproduces the historical fallback order
+func defaultChains() map[string][]SourceConfig {
+	return map[string][]SourceConfig{
+		DeviceCPU: {{Name: SourceLMSensors}, {Name: SourceHwmon}},
+		DeviceNIC: {{Name: SourceEthtoolIoctl}, {Name: SourceEthtoolM}, {Name: SourceEthtoolS}, {Name: SourceHwmon}},
+	}
+}
+
+// Registry holds the ordered source chain of each device and the
+// per-sensor calibrations applied to its readings.
+type Registry struct {
+	deps         sensorDeps
+	chains       map[string][]SourceConfig
+	calibrations map[string]Calibration
+}
+
+// NewRegistry creates a registry with the default chains.
+func NewRegistry() *Registry {
+	return NewRegistryWith(&osDeps{})
+}
+
+// NewRegistryWith creates a registry using provided deps (for testing).
+func NewRegistryWith(d sensorDeps) *Registry {
+	return &Registry{deps: d, chains: defaultChains()}
+}
+
+// SetChain sets the ordered sources for a device: "cpu", "nic" (all NICs)
+// or an interface name (overrides "nic" for that NIC).
+func (r *Registry) SetChain(device string, chain []SourceConfig) error {
+	if device == "" {
+		return fmt.Errorf("empty device name")
+	}
+	if len(chain) == 0 {
+		return fmt.Errorf("%s: empty source chain", device)
+	}
+	for _, c := range chain {
+		if _, ok := sourceFactories[c.Name]; !ok {
+			return fmt.Errorf("%s: unknown source %q (available: %s)", device, c.Name, strings.Join(SourceNames(), ", "))
+		}
+		if c.Name == SourceCommand && c.Arg == "" {
+			return fmt.Errorf("%s: command source requires a command line", device)
+		}
+	}
+	r.chains[device] = slices.Clone(chain)
+	return nil
+}
+
+// Chain returns the effective source chain of a device.
+func (r *Registry) Chain(device string) []SourceConfig {
+	if chain, ok := r.chains[device]; ok {
+		return chain
+	}
+	if device != DeviceCPU {
+		return r.chains[DeviceNIC]
+	}
+	return nil
+}
+
+// Configure applies a chain spec such as
+//
+//	cpu=thermal_zone:x86_pkg_temp,hwmon;nic=ethtool-m,hwmon;eth2=command:/usr/local/bin/nic-temp {device}
+//
+// Devices are separated by ";" and sources by ",".
+func (r *Registry) Configure(spec string) error {
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		device, list, ok := strings.Cut(part, "=")
+		if !ok {
+			return fmt.Errorf("invalid source spec %q (want device=source,...)", part)
+		}
+		chain, err := ParseSourceChain(list)
+		if err != nil {
+			return fmt.Errorf("%s: %w", strings.TrimSpace(device), err)
+		}
+		if err := r.SetChain(strings.TrimSpace(device), chain); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseSourceChain parses "name[:arg],name[:arg],...".
+func ParseSourceChain(s string) ([]SourceConfig, error) {
+	var chain []SourceConfig
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, arg, _ := strings.Cut(item, ":")
+		chain = append(chain, SourceConfig{Name: strings.TrimSpace(name), Arg: strings.TrimSpace(arg)})
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("empty source chain")
+	}
+	return chain, nil
+}
+
+// read tries each source of the device chain in order and returns the
+// first non-empty result, tagged with the source that produced it and
+// calibrated. NIC readings are calibrated by interface name. Once ctx is
+// done the remaining sources are not tried.
+func (r *Registry) read(ctx context.Context, device string) ([]TempReading, error) {
+	chain := r.Chain(device)
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("%s: no temperature sources: %w", device, ErrSensorUnavailable)
+	}
+
+	var lastErr error
+	for _, c := range chain {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		src := sourceFactories[c.Name](strings.ReplaceAll(c.Arg, "{device}", device), r.deps)
+		temps, err := src.Read(ctx, device)
+		if err == nil && len(temps) == 0 {
+			err = fmt.Errorf("%s: no readings from %s", device, c.Name)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for i := range temps {
+			temps[i].Source = c.Name
+			if device == DeviceCPU {
+				r.calibrate(&temps[i], CategoryCPU, temps[i].Label)
+			} else {
+				r.calibrate(&temps[i], CategoryNIC, device, temps[i].Label)
+			}
+		}
+		return temps, nil
+	}
+	return nil, lastErr
+}
+
+// CPU returns CPU temperatures from the first working CPU source.
+func (r *Registry) CPU(ctx context.Context) ([]TempReading, error) {
+	return r.read(ctx, DeviceCPU)
+}
+
+// NIC returns the temperature of iface from the first working source.
+func (r *Registry) NIC(ctx context.Context, iface string) (*TempReading, error) {
+	if iface == "" {
+		iface = "eth1"
+	}
+	temps, err := r.read(ctx, iface)
+	if err != nil {
+		return nil, err
+	}
+	return &temps[0], nil
+}
+
+// Zones returns the calibrated thermal zones. A zone is configured by
+// directory name (thermal_zone0) or type (acpitz).
+func (r *Registry) Zones() ([]ThermalZone, error) {
+	zones, err := scanThermalZones(r.deps)
+	if err != nil {
+		return nil, err
+	}
+	for i := range zones {
+		r.calibrate(&zones[i].TempReading, CategoryZone, zones[i].Zone, zones[i].Label)
+	}
+	return zones, nil
+}
+
+// Board returns the calibrated non-CPU hwmon temperatures.
+func (r *Registry) Board() ([]TempReading, error) {
+	_, board, err := scanHwmonTemps(r.deps)
+	if err != nil {
+		return nil, err
+	}
+	for i := range board {
+		board[i].Source = SourceHwmon
+		r.calibrate(&board[i], CategoryBoard, board[i].Label)
+	}
+	return board, nil
+}
+
+// All returns CPU, NIC (comma-separated), board and thermal zone temperatures.
+func (r *Registry) All(ctx context.Context, nicIfaces string) (cpu, nics, board []TempReading, zones []ThermalZone) {
+	cpu, _ = r.CPU(ctx)
+	zones, _ = r.Zones()
+
+	board, _ = r.Board()
+
+	for _, iface := range splitInterfaces(nicIfaces) {
+		if t, err := r.NIC(ctx, iface); err == nil {
+			nics = append(nics, *t)
+		}
+	}
+	return
+}
+
+var defaultRegistry atomic.Pointer[Registry]
+
+func init() {
+	defaultRegistry.Store(NewRegistry())
+}
+
+// SetDefaultRegistry replaces the registry used by the package-level Get functions.
+func SetDefaultRegistry(r *Registry) {
+	defaultRegistry.Store(r)
+}
+
+// ConfigureDefault installs a default registry configured from a source
+// spec (see Registry.Configure) and calibrations keyed by sensor or
+// category (see Registry.SetCalibration). The current registry is kept on
+// error.
+func ConfigureDefault(sources string, calibrations map[string]Calibration) error {
+	r := NewRegistry()
+	if err := r.Configure(sources); err != nil {
+		return fmt.Errorf("sources: %w", err)
+	}
+	for key, c := range calibrations {
+		r.SetCalibration(key, c)
+	}
+	SetDefaultRegistry(r)
+	return nil
+}
+
+// Sources
+
+type lmSensorsSource struct{ d sensorDeps }
+
+func (s *lmSensorsSource) Read(ctx context.Context, device string) ([]TempReading, error) {
+	if device != DeviceCPU {
+		return nil, fmt.Errorf("%s: lm-sensors only provides CPU temperatures", device)
+	}
+	return getCPUFromSensors(ctx, s.d)
+}
+
+// hwmonSource scans hwmon, or reads a single temp*_input file when path is set
+type hwmonSource struct {
+	path string
+	d    sensorDeps
+}
+
+func (s *hwmonSource) Read(_ context.Context, device string) ([]TempReading, error) {
+	if s.path != "" {
+		val, err := readMilliCelsius(s.d, s.path)
+		if err != nil {
+			return nil, err
+		}
+		return []TempReading{{Label: device, Value: val}}, nil
+	}
+	if device == DeviceCPU {
+		return getCPUFromHwmon(s.d)
+	}
+	t, err := getNICFromHwmon(device, s.d)
+	if err != nil {
+		return nil, err
+	}
+	return []TempReading{*t}, nil
+}
+
+// cpuZoneTypes are thermal zone types that report the CPU package
+var cpuZoneTypes = []string{"x86_pkg_temp", "cpu-thermal", "cpu_thermal", "soc_thermal", "acpitz"}
+
+// thermalZoneSource reads /sys/class/thermal. zone selects a zone by
+// directory name (thermal_zone2) or type (x86_pkg_temp).
+type thermalZoneSource struct {
+	zone string
+	d    sensorDeps
+}
+
+func (s *thermalZoneSource) Read(_ context.Context, device string) ([]TempReading, error) {
+	if s.zone == "" && device != DeviceCPU {
+		return nil, fmt.Errorf("%s: thermal_zone source requires a zone for NICs", device)
+	}
+	zones, err := scanThermalZones(s.d)
+	if err != nil {
+		return nil, err
+	}
+	return zoneReadings(device, findZones(zones, func(z ThermalZone) bool {
+		if s.zone != "" {
+			return s.zone == z.Label || s.zone == z.Zone
+		}
+		return slices.Contains(cpuZoneTypes, z.Label)
+	}))
+}
+
+type ethtoolIoctlSource struct{ d sensorDeps }
+
+func (s *ethtoolIoctlSource) Read(_ context.Context, device string) ([]TempReading, error) {
+	return nicOnly(device, func() (*TempReading, error) { return getNICFromKernel(device, s.d) })
+}
+
+type ethtoolModuleSource struct{ d sensorDeps }
+
+func (s *ethtoolModuleSource) Read(ctx context.Context, device string) ([]TempReading, error) {
+	return nicOnly(device, func() (*TempReading, error) { return getNICFromEthtool(ctx, device, s.d) })
+}
+
+type ethtoolStatsSource struct{ d sensorDeps }
+
+func (s *ethtoolStatsSource) Read(ctx context.Context, device string) ([]TempReading, error) {
+	return nicOnly(device, func() (*TempReading, error) { return getNICFromEthtoolStats(ctx, device, s.d) })
+}
+
+// ixgbeSensorPattern is where Intel's out-of-tree ixgbe driver exposes its
+// thermal sensors (ixgbe_procfs.c); {pci} is the PCI slot of the NIC
+const ixgbeSensorPattern = "/proc/driver/ixgbe/{pci}/info/sensor_*/temp"
+
+// ixgbeProcfsSource reads the ixgbe driver thermal sensor (°C)
+type ixgbeProcfsSource struct {
+	path string
+	d    sensorDeps
+}
+
+func (s *ixgbeProcfsSource) Read(_ context.Context, device string) ([]TempReading, error) {
+	if device == DeviceCPU {
+		return nil, fmt.Errorf("ixgbe-procfs only provides NIC temperatures")
+	}
+	slot, err := pciSlot(device, s.d)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := ixgbeSensorPattern
+	if s.path != "" {
+		pattern = s.path
+	}
+	matches, err := s.d.Glob(strings.ReplaceAll(pattern, "{pci}", slot))
+	if err == nil && len(matches) > 0 {
+		if data, err := s.d.ReadFile(matches[0]); err == nil {
+			if val, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64); err == nil {
+				return []TempReading{{Label: device, Value: val}}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%s: no ixgbe thermal sensor for %s: %w", device, slot, ErrSensorUnavailable)
+}
+
+// pciSlot returns the PCI address of a NIC from its uevent
+func pciSlot(iface string, d fileReadable) (string, error) {
+	data, err := d.ReadFile("/sys/class/net/" + iface + "/device/uevent")
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if slot, ok := strings.CutPrefix(line, "PCI_SLOT_NAME="); ok {
+			return strings.TrimSpace(slot), nil
+		}
+	}
+	return "", fmt.Errorf("%s: PCI slot not found", iface)
+}
+
+var commandTempRe = regexp.MustCompile(`-?\d+(?:\.\d+)?`)
+
+// commandSource runs a custom command whose output starts with a
+// temperature in °C
+type commandSource struct {
+	cmdline string
+	d       sensorDeps
+}
+
+func (s *commandSource) Read(ctx context.Context, device string) ([]TempReading, error) {
+	args := strings.Fields(s.cmdline)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("%s: empty command", device)
+	}
+	out, err := s.d.RunCommand(ctx, args[0], args[1:]...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", device, args[0], err)
+	}
+	m := commandTempRe.FindString(string(out))
+	if m == "" {
+		return nil, fmt.Errorf("%s: no temperature in output of %s", device, args[0])
+	}
+	val, _ := strconv.ParseFloat(m, 64)
+	return []TempReading{{Label: device, Value: val}}, nil
+}
+
+func nicOnly(device string, read func() (*TempReading, error)) ([]TempReading, error) {
+	if device == DeviceCPU {
+		return nil, fmt.Errorf("source only provides NIC temperatures")
+	}
+	t, err := read()
+	if err != nil {
+		return nil, err
+	}
+	return []TempReading{*t}, nil
+}
+
+func readMilliCelsius(d fileReadable, path string) (float64, error) {
+	data, err := d.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	val, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return val / 1000, nil
+}
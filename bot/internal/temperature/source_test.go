@@ -0,0 +1,186 @@
+package temperature
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegistry_RecordsSource(t *testing.T) {
+	deps := &mapSensorDeps{
+		cmdErr: map[string]error{
+			"ethtool -m eth1": &testError{msg: "no EEPROM"},
+		},
+		cmdOutput: map[string]string{
+			"ethtool -S eth1": "     temp: 61\n",
+		},
+	}
+
+	temp, err := NewRegistryWith(deps).NIC(t.Context(), "eth1")
+	if err != nil {
+		t.Fatalf("NIC() error = %v", err)
+	}
+	if temp.Source != SourceEthtoolS {
+		t.Errorf("Source = %q, want %q", temp.Source, SourceEthtoolS)
+	}
+}
+
+func TestRegistry_Configure_PerDeviceOverride(t *testing.T) {
+	deps := &mapSensorDeps{
+		cmdOutput: map[string]string{
+			"ethtool -m eth1":              "Module temperature : 55.5",
+			"ethtool -m eth2":              "Module temperature : 55.5",
+			"/usr/local/bin/nic-temp eth2": "48.25 C\n",
+		},
+	}
+	r := NewRegistryWith(deps)
+	if err := r.Configure("nic=ethtool-m; eth2=command:/usr/local/bin/nic-temp {device}"); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	eth1, err := r.NIC(t.Context(), "eth1")
+	if err != nil || eth1.Source != SourceEthtoolM {
+		t.Errorf("eth1 = %+v, %v; want ethtool-m", eth1, err)
+	}
+	eth2, err := r.NIC(t.Context(), "eth2")
+	if err != nil {
+		t.Fatalf("NIC(eth2) error = %v", err)
+	}
+	if eth2.Value != 48.25 || eth2.Source != SourceCommand || eth2.Label != "eth2" {
+		t.Errorf("eth2 = %+v", eth2)
+	}
+}
+
+func TestRegistry_Configure_Invalid(t *testing.T) {
+	tests := []string{
+		"nic=bogus",
+		"nic",
+		"eth1=command",
+		"cpu=",
+	}
+	for _, spec := range tests {
+		if err := NewRegistryWith(&mapSensorDeps{}).Configure(spec); err == nil {
+			t.Errorf("Configure(%q) expected error", spec)
+		}
+	}
+
+	err := NewRegistryWith(&mapSensorDeps{}).Configure("nic=bogus")
+	if !strings.Contains(err.Error(), SourceIxgbeProcfs) {
+		t.Errorf("error should list available sources: %v", err)
+	}
+}
+
+func TestRegistry_HwmonPath(t *testing.T) {
+	deps := &mapSensorDeps{
+		files: map[string]string{"/sys/class/hwmon/hwmon3/temp2_input": "66000\n"},
+	}
+	r := NewRegistryWith(deps)
+	if err := r.Configure("eth1=hwmon:/sys/class/hwmon/hwmon3/temp2_input"); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	temp, err := r.NIC(t.Context(), "eth1")
+	if err != nil {
+		t.Fatalf("NIC() error = %v", err)
+	}
+	if temp.Value != 66 || temp.Source != SourceHwmon {
+		t.Errorf("temp = %+v", temp)
+	}
+}
+
+func TestRegistry_ThermalZoneCPU(t *testing.T) {
+	deps := &mapSensorDeps{
+		globResults: map[string][]string{
+			"/sys/class/thermal/thermal_zone*": {
+				"/sys/class/thermal/thermal_zone0",
+				"/sys/class/thermal/thermal_zone1",
+			},
+		},
+		files: map[string]string{
+			"/sys/class/thermal/thermal_zone0/type": "acpitz\n",
+			"/sys/class/thermal/thermal_zone0/temp": "27800\n",
+			"/sys/class/thermal/thermal_zone1/type": "x86_pkg_temp\n",
+			"/sys/class/thermal/thermal_zone1/temp": "51000\n",
+		},
+	}
+	r := NewRegistryWith(deps)
+	if err := r.Configure("cpu=thermal_zone:x86_pkg_temp"); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	temps, err := r.CPU(t.Context())
+	if err != nil {
+		t.Fatalf("CPU() error = %v", err)
+	}
+	if len(temps) != 1 || temps[0].Label != "x86_pkg_temp" || temps[0].Value != 51 || temps[0].Source != SourceThermalZone {
+		t.Errorf("CPU() = %+v", temps)
+	}
+}
+
+func TestRegistry_IxgbeProcfs(t *testing.T) {
+	deps := &mapSensorDeps{
+		files: map[string]string{
+			"/sys/class/net/eth1/device/uevent":                  "DRIVER=ixgbe\nPCI_SLOT_NAME=0000:03:00.0\n",
+			"/proc/driver/ixgbe/0000:03:00.0/info/sensor_0/temp": "57\n",
+		},
+		globResults: map[string][]string{
+			"/proc/driver/ixgbe/0000:03:00.0/info/sensor_*/temp": {"/proc/driver/ixgbe/0000:03:00.0/info/sensor_0/temp"},
+		},
+	}
+	r := NewRegistryWith(deps)
+	if err := r.Configure("nic=ixgbe-procfs"); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	temp, err := r.NIC(t.Context(), "eth1")
+	if err != nil {
+		t.Fatalf("NIC() error = %v", err)
+	}
+	if temp.Value != 57 || temp.Source != SourceIxgbeProcfs {
+		t.Errorf("temp = %+v", temp)
+	}
+}
+
+func TestRegistry_AllSourcesFail_KeepsLastError(t *testing.T) {
+	r := NewRegistryWith(&mapSensorDeps{})
+	if err := r.Configure("nic=ethtool-m,hwmon"); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	_, err := r.NIC(t.Context(), "eth1")
+	if !errors.Is(err, ErrSensorUnavailable) {
+		t.Errorf("expected ErrSensorUnavailable from hwmon, got %v", err)
+	}
+}
+
+func TestRegistry_HungCommandCanceled(t *testing.T) {
+	deps := &mapSensorDeps{
+		hang:      map[string]bool{"ethtool -m eth1": true},
+		cmdOutput: map[string]string{"ethtool -S eth1": "     temp: 61\n"},
+	}
+	r := NewRegistryWith(deps)
+	if err := r.Configure("nic=ethtool-m,ethtool-S"); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := r.NIC(ctx, "eth1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("NIC() error = %v, want the deadline without trying ethtool-S", err)
+	}
+}
+
+func TestRegistry_CPUOnlySourceRejectsNIC(t *testing.T) {
+	deps := &mapSensorDeps{
+		cmdOutput: map[string]string{"sensors -u": "Core 0:\n  temp2_input: 45.000\n"},
+	}
+	r := NewRegistryWith(deps)
+	if err := r.Configure("nic=lm-sensors"); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	if _, err := r.NIC(t.Context(), "eth1"); err == nil {
+		t.Error("lm-sensors should not provide NIC temperatures")
+	}
+}
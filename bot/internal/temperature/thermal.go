@@ -0,0 +1,173 @@
+package temperature
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Trip point types (Documentation/driver-api/thermal/sysfs-api.rst)
+const (
+	TripActive   = "active"
+	TripPassive  = "passive"
+	TripHot      = "hot"
+	TripCritical = "critical"
+)
+
+// zoneWarningMargin derives a warning threshold below the critical trip
+// when a zone reports no passive or hot trip point
+const zoneWarningMargin = 10.0
+
+// TripPoint is a thermal zone trip point in °C.
+type TripPoint struct {
+	Type string
+	Temp float64
+}
+
+// ThermalZone is a /sys/class/thermal zone reading with its trip points.
+// Label is the zone type (e.g. acpitz, x86_pkg_temp, cpu-thermal).
+type ThermalZone struct {
+	TempReading
+	Zone  string // thermal_zoneN
+	Trips []TripPoint
+}
+
+// TripThresholds derives warning/critical thresholds from the trip points:
+// critical is the lowest critical (or hot) trip, warning the lowest
+// passive (or hot) trip below it. Active trips only drive fans and are ignored.
+func (z ThermalZone) TripThresholds() (warning, critical float64, ok bool) {
+	critical, ok = z.lowestTrip(TripCritical)
+	if !ok {
+		if critical, ok = z.lowestTrip(TripHot); !ok {
+			return 0, 0, false
+		}
+	}
+
+	warning = critical - zoneWarningMargin
+	for _, typ := range []string{TripPassive, TripHot} {
+		if t, found := z.lowestTrip(typ); found && t < critical {
+			warning = t
+			break
+		}
+	}
+	return warning, critical, true
+}
+
+func (z ThermalZone) lowestTrip(typ string) (float64, bool) {
+	found := false
+	var lowest float64
+	for _, t := range z.Trips {
+		if t.Type != typ || t.Temp <= 0 {
+			continue
+		}
+		if !found || t.Temp < lowest {
+			lowest = t.Temp
+			found = true
+		}
+	}
+	return lowest, found
+}
+
+// GetThermalZones returns all enabled thermal zones with their trip points.
+func GetThermalZones() ([]ThermalZone, error) {
+	return defaultRegistry.Load().Zones()
+}
+
+// GetThermalZonesWith returns thermal zones using provided deps (for testing).
+func GetThermalZonesWith(d sensorDeps) ([]ThermalZone, error) {
+	return NewRegistryWith(d).Zones()
+}
+
+// scanThermalZones walks /sys/class/thermal/thermal_zone*
+func scanThermalZones(d hwmonDeps) ([]ThermalZone, error) {
+	dirs, err := d.Glob("/sys/class/thermal/thermal_zone*")
+	if err != nil {
+		return nil, err
+	}
+
+	var zones []ThermalZone
+	for _, dir := range dirs {
+		if mode, err := d.ReadFile(filepath.Join(dir, "mode")); err == nil &&
+			strings.TrimSpace(string(mode)) == "disabled" {
+			continue
+		}
+		val, err := readMilliCelsius(d, filepath.Join(dir, "temp"))
+		if err != nil {
+			continue
+		}
+
+		zone := filepath.Base(dir)
+		typ, _ := d.ReadFile(filepath.Join(dir, "type"))
+		label := strings.TrimSpace(string(typ))
+		if label == "" {
+			label = zone
+		}
+
+		z := ThermalZone{
+			TempReading: TempReading{Label: label, Value: val, Source: SourceThermalZone},
+			Zone:        zone,
+			Trips:       readTripPoints(d, dir),
+		}
+		if warning, critical, ok := z.TripThresholds(); ok {
+			z.Thresholds = Thresholds{Warning: warning, Critical: critical}
+		}
+		zones = append(zones, z)
+	}
+	return zones, nil
+}
+
+// readTripPoints reads trip_point_N_{type,temp} in index order
+func readTripPoints(d hwmonDeps, dir string) []TripPoint {
+	paths, err := d.Glob(filepath.Join(dir, "trip_point_*_temp"))
+	if err != nil {
+		return nil
+	}
+	slices.SortFunc(paths, func(a, b string) int {
+		return tripIndex(a) - tripIndex(b)
+	})
+
+	var trips []TripPoint
+	for _, path := range paths {
+		val, err := readMilliCelsius(d, path)
+		if err != nil {
+			continue
+		}
+		typ, _ := d.ReadFile(strings.TrimSuffix(path, "_temp") + "_type")
+		trips = append(trips, TripPoint{Type: strings.TrimSpace(string(typ)), Temp: val})
+	}
+	return trips
+}
+
+func tripIndex(path string) int {
+	name := strings.TrimSuffix(filepath.Base(path), "_temp")
+	i, _ := strconv.Atoi(strings.TrimPrefix(name, "trip_point_"))
+	return i
+}
+
+// findZones selects zones by directory name or type
+func findZones(zones []ThermalZone, match func(ThermalZone) bool) []ThermalZone {
+	var found []ThermalZone
+	for _, z := range zones {
+		if match(z) {
+			found = append(found, z)
+		}
+	}
+	return found
+}
+
+// zoneReadings converts zones to readings labelled for device
+func zoneReadings(device string, zones []ThermalZone) ([]TempReading, error) {
+	if len(zones) == 0 {
+		return nil, fmt.Errorf("%s: no matching thermal zone: %w", device, ErrSensorUnavailable)
+	}
+	temps := make([]TempReading, len(zones))
+	for i, z := range zones {
+		temps[i] = z.TempReading
+		if device != DeviceCPU {
+			temps[i].Label = device
+		}
+	}
+	return temps, nil
+}
@@ -0,0 +1,106 @@
+package temperature
+
+import (
+	"reflect"
+	"testing"
+)
+
+// fanlessZoneDeps emulates a SoC router exposing temps only via thermal zones
+func fanlessZoneDeps() *mapSensorDeps {
+	return &mapSensorDeps{
+		cmdErr: map[string]error{
+			"sensors -u": &testError{msg: "not installed"},
+		},
+		globResults: map[string][]string{
+			"/sys/class/thermal/thermal_zone*": {
+				"/sys/class/thermal/thermal_zone0",
+				"/sys/class/thermal/thermal_zone1",
+				"/sys/class/thermal/thermal_zone2",
+			},
+			"/sys/class/thermal/thermal_zone0/trip_point_*_temp": {
+				"/sys/class/thermal/thermal_zone0/trip_point_10_temp",
+				"/sys/class/thermal/thermal_zone0/trip_point_0_temp",
+				"/sys/class/thermal/thermal_zone0/trip_point_1_temp",
+			},
+		},
+		files: map[string]string{
+			"/sys/class/thermal/thermal_zone0/type":               "cpu-thermal\n",
+			"/sys/class/thermal/thermal_zone0/temp":               "48312\n",
+			"/sys/class/thermal/thermal_zone0/mode":               "enabled\n",
+			"/sys/class/thermal/thermal_zone0/trip_point_0_type":  "passive\n",
+			"/sys/class/thermal/thermal_zone0/trip_point_0_temp":  "80000\n",
+			"/sys/class/thermal/thermal_zone0/trip_point_1_type":  "critical\n",
+			"/sys/class/thermal/thermal_zone0/trip_point_1_temp":  "95000\n",
+			"/sys/class/thermal/thermal_zone0/trip_point_10_type": "active\n",
+			"/sys/class/thermal/thermal_zone0/trip_point_10_temp": "60000\n",
+			"/sys/class/thermal/thermal_zone1/type":               "acpitz\n",
+			"/sys/class/thermal/thermal_zone1/temp":               "27800\n",
+			"/sys/class/thermal/thermal_zone2/type":               "gpu-thermal\n",
+			"/sys/class/thermal/thermal_zone2/temp":               "40000\n",
+			"/sys/class/thermal/thermal_zone2/mode":               "disabled\n",
+		},
+	}
+}
+
+func TestGetThermalZones(t *testing.T) {
+	zones, err := GetThermalZonesWith(fanlessZoneDeps())
+	if err != nil {
+		t.Fatalf("GetThermalZonesWith() error = %v", err)
+	}
+	if len(zones) != 2 {
+		t.Fatalf("expected 2 enabled zones, got %d", len(zones))
+	}
+
+	cpu := zones[0]
+	if cpu.Label != "cpu-thermal" || cpu.Zone != "thermal_zone0" || cpu.Value != 48.312 || cpu.Source != SourceThermalZone {
+		t.Errorf("zone0 = %+v", cpu)
+	}
+	wantTrips := []TripPoint{{TripPassive, 80}, {TripCritical, 95}, {TripActive, 60}}
+	if !reflect.DeepEqual(cpu.Trips, wantTrips) {
+		t.Errorf("trips = %+v, want %+v", cpu.Trips, wantTrips)
+	}
+
+	warn, crit, ok := cpu.TripThresholds()
+	if !ok || warn != 80 || crit != 95 {
+		t.Errorf("TripThresholds() = %v, %v, %v; want 80, 95, true", warn, crit, ok)
+	}
+
+	if _, _, ok := zones[1].TripThresholds(); ok {
+		t.Error("zone without trip points should have no thresholds")
+	}
+}
+
+func TestThermalZone_Thresholds(t *testing.T) {
+	tests := []struct {
+		name       string
+		trips      []TripPoint
+		warn, crit float64
+		ok         bool
+	}{
+		{"critical only", []TripPoint{{TripCritical, 100}}, 90, 100, true},
+		{"hot as critical", []TripPoint{{TripPassive, 70}, {TripHot, 85}}, 70, 85, true},
+		{"hot as warning", []TripPoint{{TripHot, 90}, {TripCritical, 105}}, 90, 105, true},
+		{"active ignored", []TripPoint{{TripActive, 50}, {TripCritical, 90}}, 80, 90, true},
+		{"lowest critical", []TripPoint{{TripCritical, 110}, {TripCritical, 100}}, 90, 100, true},
+		{"none", []TripPoint{{TripActive, 50}}, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := ThermalZone{Trips: tt.trips}
+			warn, crit, ok := z.TripThresholds()
+			if warn != tt.warn || crit != tt.crit || ok != tt.ok {
+				t.Errorf("TripThresholds() = %v, %v, %v; want %v, %v, %v", warn, crit, ok, tt.warn, tt.crit, tt.ok)
+			}
+		})
+	}
+}
+
+func TestGetAllTemps_ThermalZones(t *testing.T) {
+	_, _, _, zones := GetAllTempsWith(t.Context(), "eth1", fanlessZoneDeps())
+	if len(zones) != 2 {
+		t.Fatalf("expected 2 thermal zones, got %d", len(zones))
+	}
+	if zones[1].Label != "acpitz" {
+		t.Errorf("expected ACPI zone, got %q", zones[1].Label)
+	}
+}
@@ -0,0 +1,88 @@
+#!/bin/bash
+set -e
+
+# Usage: ./scripts/capture-sysroot.sh [OUTPUT]
+# Example: ./scripts/capture-sysroot.sh router.tar.gz
+#
+# Run on the router. Captures the /sys and /proc files read by pervigil and
+# the output of the commands it runs into a tarball that can be replayed with
+# `pervigil-monitor --sysroot` / `pervigil-bot --sysroot` or used as a test
+# fixture (see bot/internal/sysroot).
+
+OUTPUT="${1:-sysroot-$(hostname)-$(date +%Y%m%d).tar.gz}"
+OUTPUT="$(realpath "$OUTPUT")"
+
+STAGE="$(mktemp -d)"
+trap 'rm -rf "$STAGE"' EXIT
+
+# sysfs reports 4096 bytes for every attribute, so contents are copied with
+# cat instead of archiving /sys directly
+copy_file() {
+    local src="$1"
+    [ -f "$src" ] && [ -r "$src" ] || return 0
+    mkdir -p "$STAGE$(dirname "$src")"
+    cat "$src" >"$STAGE$src" 2>/dev/null || rm -f "$STAGE$src"
+}
+
+copy_dir() {
+    local dir="$1" depth="${2:-1}"
+    find "$dir/" -maxdepth "$depth" -type f 2>/dev/null | while read -r f; do
+        copy_file "$f"
+    done
+}
+
+# copy_link recreates a sysfs symlink (relative, as in sysfs) and prints its target
+copy_link() {
+    local link="$1" target
+    target="$(readlink -f "$link")"
+    mkdir -p "$STAGE$(dirname "$link")" "$STAGE$target"
+    ln -sfn "$(realpath --relative-to="$(dirname "$link")" "$target")" "$STAGE$link"
+    echo "$target"
+}
+
+record() {
+    local name
+    name="$(echo "$*" | tr '/' '_')"
+    "$@" >"$STAGE/commands/$name" 2>/dev/null || rm -f "$STAGE/commands/$name"
+}
+
+echo "==> Capturing /proc..."
+for f in meminfo stat loadavg uptime sys/kernel/hostname; do
+    copy_file "/proc/$f"
+done
+for f in /proc/driver/ixgbe/*/sensor_*/temp; do
+    copy_file "$f"
+done
+
+echo "==> Capturing network interfaces..."
+for link in /sys/class/net/*; do
+    dev="$(copy_link "$link")"
+    copy_dir "$dev" 2
+    if [ -L "$dev/device" ]; then
+        pci="$(copy_link "$dev/device")"
+        copy_file "$pci/uevent"
+        for h in "$pci"/hwmon/hwmon*; do
+            copy_dir "$h"
+        done
+    fi
+done
+
+echo "==> Capturing hwmon and thermal zones..."
+for link in /sys/class/hwmon/* /sys/class/thermal/thermal_zone*; do
+    [ -e "$link" ] || continue
+    copy_dir "$(copy_link "$link")"
+done
+
+echo "==> Recording commands..."
+mkdir -p "$STAGE/commands"
+record uptime -p
+record sensors -u
+for link in /sys/class/net/*; do
+    iface="$(basename "$link")"
+    [ "$iface" = "lo" ] && continue
+    record ethtool -m "$iface"
+    record ethtool -S "$iface"
+done
+
+tar -czf "$OUTPUT" -C "$STAGE" .
+echo "==> Wrote $OUTPUT"